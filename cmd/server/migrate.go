@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/mod-develop/backend/internal/adapters/storage/database"
+	"github.com/mod-develop/backend/internal/core/config"
+	"github.com/mod-develop/backend/internal/logger"
+)
+
+const migrateUsage = "usage: server migrate [up | down [steps] | status]"
+
+// runMigrate управляет версиями схемы базы данных:
+// up применяет новые миграции, down откатывает последние, status показывает состояние.
+func runMigrate(args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer cancel()
+	cfg, err := config.Init()
+	if err != nil {
+		return fmt.Errorf("failed initialize config: %w", err)
+	}
+
+	lgr, err := logger.New(logger.SetLevel(cfg.LogLevel))
+	if err != nil {
+		return fmt.Errorf("failed inittialize logger: %w", err)
+	}
+	defer lgr.Sync()
+
+	store, err := database.New(ctx, cfg.Store.DSN, database.SetLogger(lgr), database.SkipMigrate())
+	if err != nil {
+		return fmt.Errorf("failed inittialize storage: %w", err)
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "up":
+		return store.Migrate(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps `%s`: %s", args[1], migrateUsage)
+			}
+		}
+		return store.Rollback(ctx, steps)
+	case "status":
+		status, err := store.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, m := range status {
+			applied := "-"
+			if m.AppliedAt != nil {
+				applied = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown command `%s`: %s", command, migrateUsage)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/mod-develop/backend/internal/adapters/storage/database"
+	"github.com/mod-develop/backend/internal/core/config"
+	"github.com/mod-develop/backend/internal/logger"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+const roleUsage = "usage: server role [grant | revoke] <login> <role>"
+
+// runRole выдает или снимает роль пользователя без входа в систему,
+// например чтобы назначить первого админа.
+func runRole(args []string) error {
+	if len(args) != 3 || (args[0] != "grant" && args[0] != "revoke") {
+		return fmt.Errorf("invalid arguments: %s", roleUsage)
+	}
+	command, login, name := args[0], args[1], args[2]
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer cancel()
+	cfg, err := config.Init()
+	if err != nil {
+		return fmt.Errorf("failed initialize config: %w", err)
+	}
+
+	lgr, err := logger.New(logger.SetLevel(cfg.LogLevel))
+	if err != nil {
+		return fmt.Errorf("failed inittialize logger: %w", err)
+	}
+	defer lgr.Sync()
+
+	store, err := database.New(ctx, cfg.Store.DSN, database.SetLogger(lgr))
+	if err != nil {
+		return fmt.Errorf("failed inittialize storage: %w", err)
+	}
+
+	user, err := store.GetUserByLogin(ctx, login)
+	if err != nil {
+		return fmt.Errorf("failed get user `%s`: %w", login, err)
+	}
+	roles, err := store.GetRoles(ctx)
+	if err != nil {
+		return err
+	}
+	var role *models.Role
+	for i := range *roles {
+		if (*roles)[i].Name == name {
+			role = &(*roles)[i]
+		}
+	}
+	if role == nil {
+		return fmt.Errorf("unknown role `%s`", name)
+	}
+
+	roleIDs := []uint{}
+	for _, r := range user.Roles {
+		if r.ID != role.ID {
+			roleIDs = append(roleIDs, r.ID)
+		}
+	}
+	if command == "grant" {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	return store.SetUserRoles(ctx, user.ID, roleIDs)
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"go.uber.org/zap"
 
 	"github.com/mod-develop/backend/internal/adapters/api/rest"
-	"github.com/mod-develop/backend/internal/adapters/session/cookie"
+	"github.com/mod-develop/backend/internal/adapters/auth"
+	"github.com/mod-develop/backend/internal/adapters/storage/blob"
 	"github.com/mod-develop/backend/internal/adapters/storage/database"
 	"github.com/mod-develop/backend/internal/adapters/ui/web"
 	"github.com/mod-develop/backend/internal/core/config"
@@ -20,7 +23,16 @@ import (
 )
 
 func main() {
-	if err := run(); err != nil {
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "migrate":
+		err = runMigrate(os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "role":
+		err = runRole(os.Args[2:])
+	default:
+		err = run()
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -39,12 +51,17 @@ func run() error {
 	}
 	defer lgr.Sync()
 
-	store, err := database.New(ctx, cfg.Store.DSN)
+	store, err := database.New(ctx, cfg.Store.DSN, database.SetLogger(lgr))
 	if err != nil {
 		return fmt.Errorf("failed inittialize storage: %w", err)
 	}
 
-	disc, err := discipline.New(ctx, store, discipline.SetLogger(lgr))
+	blobs, err := blob.NewLocal(cfg.Blob.Dir)
+	if err != nil {
+		return fmt.Errorf("failed inittialize blob storage: %w", err)
+	}
+
+	disc, err := discipline.New(ctx, store, discipline.SetLogger(lgr), discipline.SetBlobStore(blobs))
 	if err != nil {
 		return fmt.Errorf("failed initialize discipline: %w", err)
 	}
@@ -54,18 +71,26 @@ func run() error {
 		return fmt.Errorf("failed initialize web interface: %w", err)
 	}
 
-	sess, err := cookie.New([]byte(cfg.Rest.SecretKey))
+	authManager, err := auth.New(
+		disc,
+		auth.SetLogger(lgr),
+		auth.SetSecretKey([]byte(cfg.Auth.SecretKey)),
+		auth.SetDevMode(cfg.DevMode),
+		auth.SetTokenTTL(cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL),
+	)
 	if err != nil {
-		return fmt.Errorf("failed inititalize session store: %w", err)
+		return fmt.Errorf("failed inititalize auth: %w", err)
 	}
 
 	srv := rest.New(
 		disc,
 		ui,
-		sess,
+		authManager,
 		rest.SetAddress(cfg.Rest.Address),
 		rest.SetLogger(lgr),
-		rest.SetSecretKey([]byte(cfg.Rest.SecretKey)),
+		rest.SetTrustedProxies(cfg.Rest.TrustedProxies),
+		rest.SetRateLimit(cfg.Rest.RateLimit, cfg.Rest.AuthRateLimit),
+		rest.SetLoginLockout(cfg.Rest.LoginLockout),
 	)
 	go func() {
 		if err := srv.Run(); err != nil {
@@ -1,6 +1,13 @@
 package rest
 
 type Config struct {
-	Address   string `env:"HTTP_ADDRESS"`
-	SecretKey string `env:"SECRET_KEY"`
+	Address string `env:"HTTP_ADDRESS"`
+	// TrustedProxies - адреса прокси, которым сервер верит в X-Forwarded-For при определении адреса клиента.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
+	// RateLimit - лимит запросов к API с одного адреса.
+	RateLimit RateLimit `envPrefix:"RATE_LIMIT_"`
+	// AuthRateLimit - лимит запросов к регистрации, входу и обновлению токенов.
+	AuthRateLimit RateLimit `envPrefix:"AUTH_RATE_LIMIT_"`
+	// LoginLockout - блокировка входа после неудачных попыток.
+	LoginLockout LoginLockout `envPrefix:"LOGIN_LOCKOUT_"`
 }
@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+// tResponseError - тело ответа API с ошибкой.
+type tResponseError struct {
+	Status  bool              `json:"status"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func isAPIRequest(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, "/api/")
+}
+
+// abortWithError прерывает запрос с ошибкой err, ответ формирует middlewareError.
+// Ошибки, которые не являются apperr.Error, логируются и отдаются как внутренние.
+func (s *Server) abortWithError(c *gin.Context, err error, msg string) {
+	if _, ok := apperr.As(err); !ok {
+		s.log.Error(msg, zap.Error(err))
+		err = apperr.ErrInternal.Wrap(err)
+	}
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// middlewareError переводит ошибки запроса в ответ: JSON для /api
+// и страницу ошибки для остальных маршрутов. Если обработчик не передал ошибку,
+// а только выставил статус, ошибка строится по статусу.
+func (s *Server) middlewareError() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Written() {
+			return
+		}
+
+		var appErr *apperr.Error
+		switch {
+		case len(c.Errors) > 0:
+			var ok bool
+			if appErr, ok = apperr.As(c.Errors.Last().Err); !ok {
+				appErr = apperr.ErrInternal
+			}
+		case c.Writer.Status() >= http.StatusBadRequest:
+			appErr = apperr.FromStatus(c.Writer.Status())
+		default:
+			return
+		}
+
+		if isAPIRequest(c) {
+			c.JSON(appErr.Status, tResponseError{
+				Status:  false,
+				Code:    appErr.Code,
+				Message: appErr.Message,
+				Details: appErr.Details,
+			})
+			return
+		}
+		s.errorPage(c, appErr)
+	}
+}
+
+// formError возвращает сообщение ошибки, которое можно показать в форме
+// на странице. Ненайденные данные и внутренние ошибки формой не показываются.
+func formError(err error) (string, bool) {
+	appErr, ok := apperr.As(err)
+	if !ok || appErr.Status == http.StatusNotFound || appErr.Status >= http.StatusInternalServerError {
+		return "", false
+	}
+	return appErr.Message, true
+}
+
+func (s *Server) errorPage(c *gin.Context, appErr *apperr.Error) {
+	var err error
+	c.Status(appErr.Status)
+	switch appErr.Status {
+	case http.StatusUnauthorized:
+		err = s.ui.AuthorizationPage(c.Writer)
+	case http.StatusForbidden:
+		err = s.ui.Error403Page(c.Writer)
+	case http.StatusNotFound:
+		err = s.ui.Error404Page(c.Writer)
+	case http.StatusInternalServerError:
+		err = s.ui.Error500Page(c.Writer)
+	default:
+		err = s.ui.ErrorPage(c.Writer, &types.TErrorPage{
+			Status:  appErr.Status,
+			Message: appErr.Message,
+			Details: appErr.Details,
+		})
+	}
+	if err != nil {
+		s.log.Error("failed render error page", zap.Error(err))
+	}
+}
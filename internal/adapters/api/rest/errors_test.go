@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+// errorPages подменяет страницы ошибок: вместо шаблонов пишет, какая страница показана.
+type errorPages struct {
+	userInterface
+}
+
+func (errorPages) Error403Page(wr http.ResponseWriter) error {
+	_, err := wr.Write([]byte("page 403"))
+	return err
+}
+
+func (errorPages) Error404Page(wr http.ResponseWriter) error {
+	_, err := wr.Write([]byte("page 404"))
+	return err
+}
+
+func (errorPages) Error500Page(wr http.ResponseWriter) error {
+	_, err := wr.Write([]byte("page 500"))
+	return err
+}
+
+func (errorPages) AuthorizationPage(wr http.ResponseWriter) error {
+	_, err := wr.Write([]byte("page authorization"))
+	return err
+}
+
+func (errorPages) ErrorPage(wr http.ResponseWriter, page *types.TErrorPage) error {
+	wr.WriteHeader(page.Status)
+	_, err := fmt.Fprintf(wr, "page %d: %s %v", page.Status, page.Message, page.Details)
+	return err
+}
+
+func TestMiddlewareError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := errors.New("dial tcp 10.0.0.5:5432: connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		status     int // статус без ошибки, если err == nil
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+		wantDetail map[string]string
+		wantPage   string
+		wantLogged bool
+	}{
+		{
+			name:       "wrapped app error",
+			err:        fmt.Errorf("failed create quest: %w", apperr.ErrValidation.WithField("title", "Введите название")),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation",
+			wantMsg:    "Проверьте заполнение полей",
+			wantDetail: map[string]string{"title": "Введите название"},
+			wantPage:   "page 400: Проверьте заполнение полей map[title:Введите название]",
+		},
+		{
+			name:       "joined not found",
+			err:        errors.Join(errors.New("record not found"), apperr.ErrDataNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   "not_found",
+			wantMsg:    "Данные не найдены",
+			wantPage:   "page 404",
+		},
+		{
+			name:       "untyped error",
+			err:        secret,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   "internal",
+			wantMsg:    "Что-то пошло не так",
+			wantPage:   "page 500",
+			wantLogged: true,
+		},
+		{
+			name:       "status only",
+			status:     http.StatusForbidden,
+			wantStatus: http.StatusForbidden,
+			wantCode:   "forbidden",
+			wantMsg:    "Недостаточно прав",
+			wantPage:   "page 403",
+		},
+	}
+	for _, tt := range tests {
+		core, logs := observer.New(zapcore.ErrorLevel)
+		s := &Server{log: zap.New(core), ui: errorPages{}}
+		r := gin.New()
+		r.Use(s.middlewareError())
+		handler := func(c *gin.Context) {
+			if tt.err == nil {
+				c.Status(tt.status)
+				return
+			}
+			s.abortWithError(c, tt.err, "failed handle request")
+		}
+		r.GET("/api/v1/test", handler)
+		r.GET("/test", handler)
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/test", nil))
+		var body tResponseError
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: api body %q: %v", tt.name, rec.Body.String(), err)
+		}
+		if rec.Code != tt.wantStatus || body.Status || body.Code != tt.wantCode ||
+			body.Message != tt.wantMsg || fmt.Sprint(body.Details) != fmt.Sprint(tt.wantDetail) {
+			t.Errorf("%s: api response = %d %+v, want %d {Code:%s Message:%s Details:%v}", tt.name, rec.Code, body, tt.wantStatus, tt.wantCode, tt.wantMsg, tt.wantDetail)
+		}
+		if strings.Contains(rec.Body.String(), "10.0.0.5") {
+			t.Errorf("%s: api response %q leaks the internal error", tt.name, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+		if rec.Code != tt.wantStatus || rec.Body.String() != tt.wantPage {
+			t.Errorf("%s: page response = %d %q, want %d %q", tt.name, rec.Code, rec.Body.String(), tt.wantStatus, tt.wantPage)
+		}
+
+		if logged := logs.FilterMessage("failed handle request").Len(); (logged > 0) != tt.wantLogged {
+			t.Errorf("%s: logged %d errors, want logged = %v", tt.name, logged, tt.wantLogged)
+		}
+	}
+}
+
+func TestFormError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   string
+		wantOK bool
+	}{
+		{name: "wrapped app error", err: fmt.Errorf("failed add reward: %w", apperr.ErrValidation.WithMessage("Цена больше нуля")), want: "Цена больше нуля", wantOK: true},
+		{name: "joined app error", err: errors.Join(errors.New("duplicated key"), apperr.ErrPlayerQuestStatusExists), want: apperr.ErrPlayerQuestStatusExists.Message, wantOK: true},
+		{name: "not found", err: errors.Join(errors.New("record not found"), apperr.ErrDataNotFound)},
+		{name: "internal", err: apperr.ErrInternal.Wrap(errors.New("connection refused"))},
+		{name: "untyped", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		got, ok := formError(tt.err)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: formError() = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -29,15 +30,13 @@ func (s *Server) handlerApiRegistration(c *gin.Context) {
 	jBody := tRequestRegistration{}
 	err := json.Unmarshal(bBody, &jBody)
 	if err != nil {
-		s.log.Error("failed parse body", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
 		return
 	}
 
 	err = s.disc.Registration(c.Request.Context(), jBody.Login, jBody.Password)
 	if err != nil {
-		s.log.Error("failed register user", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed register user")
 		return
 	}
 
@@ -60,22 +59,13 @@ func (s *Server) handlerAPIAuthorization(c *gin.Context) {
 	jBody := tRequestAuthorization{}
 	err := json.Unmarshal(bBody, &jBody)
 	if err != nil {
-		s.log.Error("failed parse body", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
 		return
 	}
 
-	err = s.authorization(c, jBody.Login, jBody.Password)
+	_, err = s.authorization(c, jBody.Login, jBody.Password)
 	if err != nil {
-		if errors.Is(err, apperr.ErrDataNotFound) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"status":  false,
-				"message": "Не удалось авторизоваться",
-			})
-			return
-		}
-		s.log.Error("failed register user", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed authorization")
 		return
 	}
 
@@ -85,7 +75,7 @@ func (s *Server) handlerAPIAuthorization(c *gin.Context) {
 }
 
 func (s *Server) handlerAPIManageQuestConfirmation(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -96,15 +86,18 @@ func (s *Server) handlerAPIManageQuestConfirmation(c *gin.Context) {
 	jBody := tRequestApiManageQuestConfirmation{}
 	err = json.Unmarshal(bBody, &jBody)
 	if err != nil {
-		s.log.Error("failed parse body", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
+		return
+	}
+
+	if jBody.Action != actionConfirmationAccpet && jBody.Action != actionConfirmationReject {
+		s.abortWithError(c, apperr.ErrValidation.WithField("action", "Неизвестное действие"), "")
 		return
 	}
 
-	err = s.disc.ManageQuestConfirmation(c.Request.Context(), jBody.ID, user.ID, jBody.Action == actionConfirmationAccpet)
+	err = s.disc.ManageQuestConfirmation(c.Request.Context(), jBody.ID, user.Master.ID, jBody.Action == actionConfirmationAccpet, jBody.Reason)
 	if err != nil {
-		s.log.Error("failed confirmation quest", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed confirmation quest")
 		return
 	}
 
@@ -114,34 +107,28 @@ func (s *Server) handlerAPIManageQuestConfirmation(c *gin.Context) {
 }
 
 func (s *Server) handlerAPIManageCreateMaster(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	userM, err := s.disc.ManageCreateSelfMaster(c.Request.Context(), user.ID)
+	master, err := s.disc.ManageCreateSelfMaster(c.Request.Context(), user.ID, "")
 	if err != nil {
-		s.log.Error("failed confirmation quest", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed create master")
 		return
 	}
 
-	err = s.sess.SaveUser(c, userM)
-	if err != nil {
-		s.log.Error("failed save user session", zap.Error(err))
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"status":     true,
-		"masterID":   userM.QuestMaster.ID,
-		"masterCode": userM.QuestMaster.UniqueCode,
+		"masterID":   master.ID,
+		"masterCode": master.UniqueCode,
 	})
 }
 
 func (s *Server) handlerAPIPlayerAddMaster(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -152,14 +139,40 @@ func (s *Server) handlerAPIPlayerAddMaster(c *gin.Context) {
 	jBody := tRequestAPISettingsAddMaster{}
 	err = json.Unmarshal(bBody, &jBody)
 	if err != nil {
-		s.log.Error("failed parse body", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
 		return
 	}
 
 	err = s.disc.AddMaster(c.Request.Context(), jBody.Code, user.ID)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed add master")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": true,
+	})
+}
+
+func (s *Server) handlerAPIUserTimeZone(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bBody, _ := s.readBody(c)
+	jBody := tRequestAPISettingsTimeZone{}
+	err = json.Unmarshal(bBody, &jBody)
+	if err != nil {
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
+		return
+	}
+
+	err = s.disc.SetTimeZone(c.Request.Context(), user.ID, jBody.TimeZone)
+	if err != nil {
+		s.abortWithError(c, err, "failed set time zone")
 		return
 	}
 
@@ -169,19 +182,78 @@ func (s *Server) handlerAPIPlayerAddMaster(c *gin.Context) {
 }
 
 func (s *Server) handleUserLogout(c *gin.Context) {
-	unauthorize(c)
+	if err := s.auth.SignOut(c, ""); err != nil {
+		s.log.Error("failed sign out", zap.Error(err))
+	}
 
 	c.Redirect(http.StatusTemporaryRedirect, "/authorization")
 }
 
-func (s *Server) handlerAPIUserInfo(c *gin.Context) {
-	user, statusCode, err := s.checkUser(c)
+func (s *Server) handleUserLogoutAll(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
 	if err != nil {
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	err = s.auth.SignOutAll(c, user.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed logout all devices")
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/authorization")
+}
+
+func (s *Server) handlerUserSessions(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+
+	sessions, err := s.disc.GetSessions(c.Request.Context(), user.ID, user.SessionID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get sessions")
+		return
+	}
+
+	err = s.ui.PlayerSessions(c.Writer, &types.TSessionsPage{
+		User:     *user,
+		Sessions: *sessions,
+	})
+	if err != nil {
+		s.log.Error("page handlerUserSessions", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerUserSessionRevoke(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+
+	sessionID := c.Param("id")
+	err = s.disc.RevokeSession(c.Request.Context(), user.ID, sessionID)
+	if err != nil {
+		s.abortWithError(c, err, "failed revoke session")
+		return
+	}
+	if sessionID == user.SessionID {
+		if err := s.auth.SignOut(c, ""); err != nil {
+			s.log.Error("failed sign out", zap.Error(err))
+		}
+		c.Redirect(http.StatusSeeOther, "/authorization")
 		return
 	}
-	if statusCode > 0 {
-		c.Writer.WriteHeader(statusCode)
+
+	c.Redirect(http.StatusSeeOther, "/player/sessions")
+}
+
+func (s *Server) handlerAPIUserInfo(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
 		return
 	}
 
@@ -193,7 +265,7 @@ func (s *Server) handlerAPIUserInfo(c *gin.Context) {
 }
 
 func (s *Server) handlerUserProfile(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -206,15 +278,50 @@ func (s *Server) handlerUserProfile(c *gin.Context) {
 	}
 }
 
+// handlerUserPassword меняет пароль пользователя. Остальные сессии завершаются,
+// текущему устройству выдаются новые токены.
+func (s *Server) handlerUserPassword(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+
+	page := &types.TPasswordPage{User: *user}
+	if c.Request.Method == http.MethodPost {
+		changed, err := s.disc.ChangePassword(c.Request.Context(), user.ID, c.PostForm("current_password"), c.PostForm("password"))
+		if err == nil {
+			if _, err := s.auth.SignIn(c, changed); err != nil {
+				s.abortWithError(c, err, "failed sign in")
+				return
+			}
+			c.Redirect(http.StatusSeeOther, "/")
+			return
+		}
+		message, ok := formError(err)
+		if !ok {
+			s.abortWithError(c, err, "failed change password")
+			return
+		}
+		appErr, _ := apperr.As(err)
+		page.Error, page.Fields = message, appErr.Details
+	}
+
+	err = s.ui.PasswordPage(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerUserPassword", zap.Error(err))
+	}
+}
+
 func (s *Server) handlerQuestGiverPage(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	quests, err := s.disc.GetQuests(c.Request.Context(), user.ID)
+	quests, err := s.disc.GetQuests(c.Request.Context(), user.Master.ID)
 	if err != nil && !errors.Is(err, apperr.ErrDataNotFound) {
 		s.log.Error("failed get quests", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusInternalServerError)
@@ -230,6 +337,96 @@ func (s *Server) handlerQuestGiverPage(c *gin.Context) {
 	}
 }
 
+// questScheduleFromForm заполняет расписание квеста из формы создания и редактирования.
+func questScheduleFromForm(c *gin.Context, quest *types.TQuest) {
+	quest.WeekDays = types.NewWeekDays()
+	for _, d := range c.PostFormArray("schedule_week_days") {
+		for i := range quest.WeekDays {
+			if strconv.Itoa(quest.WeekDays[i].Value) == d {
+				quest.WeekDays[i].Selected = true
+			}
+		}
+	}
+	quest.MonthDays = c.PostForm("schedule_month_days")
+}
+
+// questStreakBonusesFromForm заполняет бонусы за серию из парных полей streak_days и streak_bonus.
+func questStreakBonusesFromForm(c *gin.Context, quest *types.TQuest) {
+	days := c.PostFormArray("streak_days")
+	bonuses := c.PostFormArray("streak_bonus")
+	quest.StreakBonuses = []types.TStreakBonus{}
+	for i := range min(len(days), len(bonuses)) {
+		d, _ := strconv.Atoi(days[i])
+		b, _ := strconv.Atoi(bonuses[i])
+		if d <= 0 || b <= 0 {
+			continue
+		}
+		quest.StreakBonuses = append(quest.StreakBonuses, types.TStreakBonus{
+			Days:  uint(d),
+			Bonus: uint(b),
+		})
+	}
+}
+
+// questFromForm заполняет квест из формы создания и редактирования.
+// Числа, которые не удалось разобрать, возвращаются как ошибки полей.
+func questFromForm(c *gin.Context, quest *types.TQuest) error {
+	var formErr *apperr.Error
+	number := func(field, name, message string) uint {
+		value := strings.TrimSpace(c.PostForm(name))
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			if formErr == nil {
+				formErr = apperr.ErrValidation
+			}
+			formErr = formErr.WithField(field, message)
+		}
+		return uint(n)
+	}
+
+	quest.Title = c.PostForm("title")
+	quest.Description = c.PostForm("description")
+	for i := range quest.Types {
+		quest.Types[i].Selected = quest.Types[i].Value == models.QuestType(c.PostForm("type"))
+	}
+	quest.IsActive = c.PostForm("active") == "on"
+	quest.IsAllPlayers = c.PostForm("players_all") == "on"
+	for _, p := range c.PostFormArray("players") {
+		for i := range quest.Players {
+			if strconv.Itoa(int(quest.Players[i].ID)) == p || quest.IsAllPlayers {
+				quest.Players[i].Selected = true
+				break
+			}
+		}
+	}
+	quest.Price = number("price", "price", "Награда должна быть целым неотрицательным числом")
+	quest.Penalty = number("penalty", "penalty", "Штраф должен быть целым неотрицательным числом")
+	quest.DateStart = c.PostForm("date_start")
+	quest.DateEnd = c.PostForm("date_end")
+	quest.TimeZoneOffset, _ = strconv.Atoi(c.PostForm("timezoneoffset"))
+	questScheduleFromForm(c, quest)
+	quest.Times = number("times", "schedule_times", "Укажите число раз в неделю")
+	questStreakBonusesFromForm(c, quest)
+
+	if formErr != nil {
+		return formErr
+	}
+	return nil
+}
+
+// questErrorMessage возвращает сообщение и ошибки полей для формы квеста,
+// для остальных ошибок - сообщение message.
+func questErrorMessage(err error, message string) (string, map[string]string) {
+	if formMessage, ok := formError(err); ok {
+		appErr, _ := apperr.As(err)
+		return formMessage, appErr.Details
+	}
+	return message, nil
+}
+
 func (s *Server) handlerQuestEdit(c *gin.Context) {
 	sID := c.Param("id")
 	questID, err := strconv.Atoi(sID)
@@ -239,7 +436,7 @@ func (s *Server) handlerQuestEdit(c *gin.Context) {
 		return
 	}
 
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusInternalServerError)
@@ -249,8 +446,9 @@ func (s *Server) handlerQuestEdit(c *gin.Context) {
 	page := &types.TQuestEdit{
 		User: *user,
 		Quest: types.TQuest{
-			ID:    uint(questID),
-			Types: types.QuestTypes,
+			ID:       uint(questID),
+			Types:    types.QuestTypes,
+			WeekDays: types.NewWeekDays(),
 		},
 	}
 
@@ -263,53 +461,31 @@ func (s *Server) handlerQuestEdit(c *gin.Context) {
 	page.Quest.Players = *players
 
 	if c.Request.Method == http.MethodPost {
-		page.Quest.Title = c.PostForm("title")
-		page.Quest.Description = c.PostForm("description")
-		for i := range page.Quest.Types {
-			page.Quest.Types[i].Selected = page.Quest.Types[i].Value == models.QuestType(c.PostForm("type"))
+		formErr := questFromForm(c, &page.Quest)
+		s.log.Debug("form", zap.Any("form", c.Request.PostForm))
+
+		var quest *types.TQuest
+		if formErr != nil {
+			err = formErr
+		} else {
+			quest, err = s.disc.EditQuest(c.Request.Context(), &page.Quest, user.Master.ID)
 		}
-		page.Quest.IsActive = c.PostForm("active") == "on"
-		page.Quest.IsAllPlayers = c.PostForm("players_all") == "on"
-		for _, p := range c.PostFormArray("players") {
-			for i := range page.Quest.Players {
-				if strconv.Itoa(int(page.Quest.Players[i].ID)) == p || c.PostForm("players_all") == "on" {
-					page.Quest.Players[i].Selected = true
-					break
-				}
-			}
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			s.abortWithError(c, err, "")
+			return
 		}
-		price, _ := strconv.Atoi(c.PostForm("price"))
-		page.Quest.Price = uint(price)
-		page.Quest.DateStart = c.PostForm("date_start")
-		page.Quest.DateEnd = c.PostForm("date_end")
-		page.Quest.TimeZoneOffset, _ = strconv.Atoi(c.PostForm("timezoneoffset"))
-
-		s.log.Debug("form",
-			zap.String("title", c.PostForm("title")),
-			zap.String("description", c.PostForm("description")),
-			zap.String("active", c.PostForm("active")),
-			zap.String("players_all", c.PostForm("players_all")),
-			zap.String("price", c.PostForm("price")),
-			zap.Strings("players", c.PostFormArray("players")),
-			zap.String("date_start", c.PostForm("date_start")),
-			zap.String("date_end", c.PostForm("date_end")),
-			zap.String("type", c.PostForm("type")),
-			zap.String("timezoneoffset", c.PostForm("timezoneoffset")),
-		)
-
-		quest, err := s.disc.EditQuest(c.Request.Context(), &page.Quest, user.ID)
 		if err != nil {
-			s.log.Error("create quest", zap.Error(err))
-			page.Error = "Не удалось создать квест"
+			s.log.Error("update quest", zap.Error(err))
+			page.Error, page.Fields = questErrorMessage(err, "Не удалось обновить квест")
+		} else {
+			page.Quest = *quest
+			page.Success = "Квест обновлен"
 		}
-		page.Quest = *quest
-		page.Success = "Квест обновлен"
 
 	} else {
-		quest, err := s.disc.GetQuest(c.Request.Context(), uint(questID))
+		quest, err := s.disc.GetQuest(c.Request.Context(), uint(questID), user.Master.ID)
 		if err != nil {
-			s.log.Error("failed get quest", zap.Error(err), zap.Int("quest_id", questID))
-			_ = s.ui.Error500Page(c.Writer)
+			s.abortWithError(c, err, "failed get quest")
 			return
 		}
 		page.Quest = *quest
@@ -322,7 +498,7 @@ func (s *Server) handlerQuestEdit(c *gin.Context) {
 }
 
 func (s *Server) handlerQuestNew(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -341,48 +517,23 @@ func (s *Server) handlerQuestNew(c *gin.Context) {
 			Types:        types.QuestTypes,
 			IsAllPlayers: true,
 			Players:      *players,
+			WeekDays:     types.NewWeekDays(),
 		},
 	}
 
 	if c.Request.Method == http.MethodPost {
-		page.Quest.Title = c.PostForm("title")
-		page.Quest.Description = c.PostForm("description")
-		for i := range page.Quest.Types {
-			page.Quest.Types[i].Selected = page.Quest.Types[i].Value == models.QuestType(c.PostForm("type"))
-		}
-		page.Quest.IsActive = c.PostForm("active") == "on"
-		page.Quest.IsAllPlayers = c.PostForm("players_all") == "on"
-		for _, p := range c.PostFormArray("players") {
-			for i := range page.Quest.Players {
-				if strconv.Itoa(int(page.Quest.Players[i].ID)) == p || c.PostForm("players_all") == "on" {
-					page.Quest.Players[i].Selected = true
-					break
-				}
-			}
+		formErr := questFromForm(c, &page.Quest)
+		s.log.Debug("form", zap.Any("form", c.Request.PostForm))
+
+		var quest *models.Quest
+		if formErr != nil {
+			err = formErr
+		} else {
+			quest, err = s.disc.NewQuest(c.Request.Context(), &page.Quest, user.Master.ID, user.ID)
 		}
-		price, _ := strconv.Atoi(c.PostForm("price"))
-		page.Quest.Price = uint(price)
-		page.Quest.DateStart = c.PostForm("date_start")
-		page.Quest.DateEnd = c.PostForm("date_end")
-		page.Quest.TimeZoneOffset, _ = strconv.Atoi(c.PostForm("timezoneoffset"))
-
-		s.log.Debug("form",
-			zap.String("title", c.PostForm("title")),
-			zap.String("description", c.PostForm("description")),
-			zap.String("active", c.PostForm("active")),
-			zap.String("players_all", c.PostForm("players_all")),
-			zap.String("price", c.PostForm("price")),
-			zap.Strings("players", c.PostFormArray("players")),
-			zap.String("date_start", c.PostForm("date_start")),
-			zap.String("date_end", c.PostForm("date_end")),
-			zap.String("type", c.PostForm("type")),
-			zap.String("timezoneoffset", c.PostForm("timezoneoffset")),
-		)
-
-		quest, err := s.disc.NewQuest(c.Request.Context(), &page.Quest, user.ID)
 		if err != nil {
 			s.log.Error("create quest", zap.Error(err))
-			page.Error = "Не удалось создать квест"
+			page.Error, page.Fields = questErrorMessage(err, "Не удалось создать квест")
 		}
 
 		if err == nil {
@@ -398,14 +549,14 @@ func (s *Server) handlerQuestNew(c *gin.Context) {
 }
 
 func (s *Server) handlerQuestAwait(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	awaits, err := s.disc.GetAwaitQuests(c.Request.Context(), user.ID)
+	awaits, err := s.disc.GetAwaitQuests(c.Request.Context(), user.Master.ID)
 	if err != nil {
 		c.Writer.WriteHeader(http.StatusInternalServerError)
 		return
@@ -421,7 +572,7 @@ func (s *Server) handlerQuestAwait(c *gin.Context) {
 }
 
 func (s *Server) handlerPlayer(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -453,7 +604,7 @@ func (s *Server) handlerPlayer(c *gin.Context) {
 }
 
 func (s *Server) handlerPlayerQuests(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -477,7 +628,7 @@ func (s *Server) handlerPlayerQuests(c *gin.Context) {
 }
 
 func (s *Server) handlerPlayerQuest(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -497,33 +648,33 @@ func (s *Server) handlerPlayerQuest(c *gin.Context) {
 	}
 
 	if c.Request.Method == http.MethodPost {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProofRequestSize)
 		if c.PostForm("action") == "send" {
-			_, err = s.disc.SendQuestPlayer(c.Request.Context(), uint(questID), user.ID)
-			if err != nil && !errors.Is(err, apperr.ErrPlayerQuestStatusExists) {
-				if errors.Is(err, apperr.ErrDataNotFound) {
-					c.Writer.WriteHeader(http.StatusNotFound)
-					return
-				}
-				s.log.Error("failed get quests", zap.Error(err))
-				c.Writer.WriteHeader(http.StatusInternalServerError)
+			_, err = s.sendPlayerQuest(c, uint(questID), user.ID)
+			if message, ok := formError(err); ok {
+				page.Error = message
+			} else if err != nil {
+				s.abortWithError(c, err, "failed send quest")
 				return
-			}
-			if err != nil && errors.Is(err, apperr.ErrPlayerQuestStatusExists) {
-				page.Error = "Квест уже был отправлен"
 			} else {
 				page.Success = "Квест отправлен"
 			}
 		}
+		if c.PostForm("action") == "comment" {
+			statusID, _ := strconv.Atoi(c.PostForm("status"))
+			_, err = s.disc.AddQuestComment(c.Request.Context(), uint(statusID), user.ID, c.PostForm("text"))
+			if message, ok := formError(err); ok {
+				page.Error = message
+			} else if err != nil {
+				s.abortWithError(c, err, "failed add quest comment")
+				return
+			}
+		}
 	}
 
 	quest, err := s.disc.GetQuestPlayer(c.Request.Context(), uint(questID), user.ID)
 	if err != nil {
-		if errors.Is(err, apperr.ErrDataNotFound) {
-			c.Writer.WriteHeader(http.StatusNotFound)
-			return
-		}
-		s.log.Error("failed get quests", zap.Error(err))
-		c.Writer.WriteHeader(http.StatusInternalServerError)
+		s.abortWithError(c, err, "failed get quests")
 		return
 	}
 	page.Quest = *quest
@@ -535,7 +686,7 @@ func (s *Server) handlerPlayerQuest(c *gin.Context) {
 }
 
 func (s *Server) handlerPlayerSettings(c *gin.Context) {
-	user, err := s.sess.GetUser(c)
+	user, err := s.auth.GetUser(c)
 	if err != nil {
 		s.log.Error("failed get user from session", zap.Error(err))
 		c.Writer.WriteHeader(http.StatusUnauthorized)
@@ -551,13 +702,22 @@ func (s *Server) handlerPlayerSettings(c *gin.Context) {
 	playerMasters := []types.TMaster{}
 	for _, m := range *masters {
 		playerMasters = append(playerMasters, types.TMaster{
-			ID: m.ID,
+			ID:   m.ID,
+			Name: m.Name,
 		})
 	}
 
+	userData, err := s.disc.GetUserByID(c.Request.Context(), user.ID)
+	if err != nil {
+		s.log.Error("failed get user", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	err = s.ui.PlayerSettings(c.Writer, &types.TPlayerSettingsPage{
-		User:    *user,
-		Masters: playerMasters,
+		User:     *user,
+		Masters:  playerMasters,
+		TimeZone: userData.TimeZone,
 	})
 	if err != nil {
 		s.log.Error("page PlayerSettings", zap.Error(err))
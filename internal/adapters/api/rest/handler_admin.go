@@ -0,0 +1,179 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+func (s *Server) handlerAdminUsers(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	s.renderAdminUsers(c, &types.TAdminUsersPage{User: *user, Query: c.Query("q"), Page: queryPage(c)})
+}
+
+func (s *Server) handlerAdminUserRoles(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.SetUserRoles(c.Request.Context(), user.ID, userID, c.PostFormArray("role"))
+	if err != nil {
+		message, ok := fieldError(err, "roles")
+		if !ok {
+			s.abortWithError(c, err, "failed set user roles")
+			return
+		}
+		s.renderAdminUsers(c, &types.TAdminUsersPage{User: *user, Error: message})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, "/admin")
+}
+
+// renderAdminUsers дополняет страницу ролями и пользователями и отображает ее.
+func (s *Server) renderAdminUsers(c *gin.Context, page *types.TAdminUsersPage) {
+	roles, err := s.disc.GetRoles(c.Request.Context())
+	if err != nil {
+		s.abortWithError(c, err, "failed get roles")
+		return
+	}
+	users, err := s.disc.GetUsersWithRoles(c.Request.Context(), page.Query, page.Page)
+	if err != nil {
+		s.abortWithError(c, err, "failed get users")
+		return
+	}
+	page.Roles = *roles
+	page.Users = *users
+
+	err = s.ui.AdminUsers(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerAdminUsers", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerAdminUser(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	s.renderAdminUser(c, userID, &types.TAdminUserPage{User: *user})
+}
+
+func (s *Server) handlerAdminUserLock(c *gin.Context) {
+	s.changeUserLock(c, true)
+}
+
+func (s *Server) handlerAdminUserUnlock(c *gin.Context) {
+	s.changeUserLock(c, false)
+}
+
+// changeUserLock блокирует пользователя из пути запроса или снимает с него блокировку.
+func (s *Server) changeUserLock(c *gin.Context, lock bool) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	if lock {
+		err = s.disc.LockUser(c.Request.Context(), user.ID, userID)
+	} else {
+		err = s.disc.UnlockUser(c.Request.Context(), userID)
+	}
+	if err != nil {
+		message, ok := fieldError(err, "user")
+		if !ok {
+			s.abortWithError(c, err, "failed change user lock")
+			return
+		}
+		s.renderAdminUser(c, userID, &types.TAdminUserPage{User: *user, Error: message})
+		return
+	}
+
+	c.Redirect(http.StatusSeeOther, fmt.Sprintf("/admin/users/%d", userID))
+}
+
+func (s *Server) handlerAdminUserPassword(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	password, err := s.disc.ResetPassword(c.Request.Context(), userID)
+	if err != nil {
+		s.abortWithError(c, err, "failed reset password")
+		return
+	}
+	s.renderAdminUser(c, userID, &types.TAdminUserPage{User: *user, Password: password})
+}
+
+// renderAdminUser дополняет страницу пользователем userID и отображает ее.
+func (s *Server) renderAdminUser(c *gin.Context, userID uint, page *types.TAdminUserPage) {
+	account, err := s.disc.GetAdminUser(c.Request.Context(), userID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user")
+		return
+	}
+	page.Account = *account
+
+	err = s.ui.AdminUser(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerAdminUser", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerAdminHealth(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	health, err := s.disc.GetHealth(c.Request.Context())
+	if err != nil {
+		s.abortWithError(c, err, "failed get health")
+		return
+	}
+
+	err = s.ui.AdminHealth(c.Writer, &types.TAdminHealthPage{User: *user, Health: *health})
+	if err != nil {
+		s.log.Error("page handlerAdminHealth", zap.Error(err))
+	}
+}
+
+// fieldError возвращает текст ошибки поля field, чтобы показать его на странице.
+func fieldError(err error, field string) (string, bool) {
+	appErr, ok := apperr.As(err)
+	if !ok || appErr.Details[field] == "" {
+		return "", false
+	}
+	return appErr.Details[field], true
+}
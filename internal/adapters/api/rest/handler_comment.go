@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+func commentResponse(comment *types.TQuestComment) tResponseQuestComment {
+	return tResponseQuestComment{
+		ID:       comment.ID,
+		Author:   comment.Author,
+		Text:     comment.Text,
+		Date:     comment.Date,
+		IsMaster: comment.IsMaster,
+	}
+}
+
+func (s *Server) handlerAPIQuestComments(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	statusID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	comments, err := s.disc.GetQuestComments(c.Request.Context(), uint(statusID), user.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get quest comments")
+		return
+	}
+
+	resp := tResponseQuestComments{
+		Status:   true,
+		Comments: []tResponseQuestComment{},
+	}
+	for _, comment := range *comments {
+		resp.Comments = append(resp.Comments, commentResponse(&comment))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerAPIQuestCommentAdd(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	statusID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	bBody, _ := s.readBody(c)
+	jBody := tRequestAPIQuestComment{}
+	err = json.Unmarshal(bBody, &jBody)
+	if err != nil {
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
+		return
+	}
+
+	comment, err := s.disc.AddQuestComment(c.Request.Context(), uint(statusID), user.ID, jBody.Text)
+	if err != nil {
+		s.abortWithError(c, err, "failed add quest comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  true,
+		"comment": commentResponse(comment),
+	})
+}
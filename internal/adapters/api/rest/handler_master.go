@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+const mastersPath = "/manager/masters"
+
+func (s *Server) handlerMasters(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	s.renderMasters(c, &types.TMastersPage{User: *user})
+}
+
+func (s *Server) handlerMasterNew(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+
+	_, err = s.disc.ManageCreateSelfMaster(c.Request.Context(), user.ID, c.PostForm("name"))
+	if err != nil {
+		s.mastersError(c, user, err, "failed create master")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, mastersPath)
+}
+
+func (s *Server) handlerMasterSwitch(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	masterID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.SwitchMaster(c.Request.Context(), user.ID, masterID)
+	if err != nil {
+		s.abortWithError(c, err, "failed switch master")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, mastersPath)
+}
+
+func (s *Server) handlerMasterRename(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	masterID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.RenameMaster(c.Request.Context(), masterID, user.ID, c.PostForm("name"))
+	if err != nil {
+		s.mastersError(c, user, err, "failed rename master")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, mastersPath)
+}
+
+// mastersError показывает ошибку названия на странице групп, остальные ошибки обрабатывает abortWithError.
+func (s *Server) mastersError(c *gin.Context, user *types.TUser, err error, msg string) {
+	message, ok := fieldError(err, "name")
+	if !ok {
+		s.abortWithError(c, err, msg)
+		return
+	}
+	s.renderMasters(c, &types.TMastersPage{User: *user, Error: message})
+}
+
+func (s *Server) renderMasters(c *gin.Context, page *types.TMastersPage) {
+	err := s.ui.Masters(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerMasters", zap.Error(err))
+	}
+}
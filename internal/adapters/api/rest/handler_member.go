@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+const teamPath = "/manager/members"
+
+func (s *Server) handlerTeam(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	s.renderTeam(c, &types.TTeamPage{User: *user})
+}
+
+func (s *Server) handlerTeamInviteNew(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+
+	_, err = s.disc.NewInvite(c.Request.Context(), user.Master.ID, user.ID, c.PostForm("role"))
+	if err != nil {
+		s.teamError(c, user, err, "role", "failed create invite")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, teamPath)
+}
+
+func (s *Server) handlerTeamInviteRevoke(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	inviteID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.RevokeInvite(c.Request.Context(), user.Master.ID, user.ID, inviteID)
+	if err != nil {
+		s.abortWithError(c, err, "failed revoke invite")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, teamPath)
+}
+
+func (s *Server) handlerTeamMemberRole(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	memberID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.SetMemberRole(c.Request.Context(), user.Master.ID, user.ID, memberID, c.PostForm("role"))
+	if err != nil {
+		s.teamError(c, user, err, "role", "failed set member role")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, teamPath)
+}
+
+func (s *Server) handlerTeamMemberRemove(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	memberID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err = s.disc.RemoveMember(c.Request.Context(), user.Master.ID, user.ID, memberID)
+	if err != nil {
+		s.teamError(c, user, err, "user", "failed remove member")
+		return
+	}
+	// покинувший группу больше не видит ее участников
+	if memberID == user.ID {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, teamPath)
+}
+
+// teamError показывает ошибку поля field на странице команды, остальные ошибки обрабатывает abortWithError.
+func (s *Server) teamError(c *gin.Context, user *types.TUser, err error, field, msg string) {
+	message, ok := fieldError(err, field)
+	if !ok {
+		s.abortWithError(c, err, msg)
+		return
+	}
+	s.renderTeam(c, &types.TTeamPage{User: *user, Error: message})
+}
+
+// renderTeam дополняет страницу участниками группы пользователя и отображает ее.
+func (s *Server) renderTeam(c *gin.Context, page *types.TTeamPage) {
+	team, err := s.disc.GetTeam(c.Request.Context(), page.User.Master.ID, page.User.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get team")
+		return
+	}
+	page.Team = *team
+	page.Roles = types.MemberRoles
+
+	err = s.ui.Team(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerTeam", zap.Error(err))
+	}
+}
+
+// handlerInvite показывает приглашение в группу мастера и принимает его.
+func (s *Server) handlerInvite(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	page := &types.TInvitePage{User: *user, Code: c.Param("code")}
+
+	if c.Request.Method == http.MethodPost {
+		err = s.disc.AcceptInvite(c.Request.Context(), page.Code, user.ID)
+		if err == nil {
+			c.Redirect(http.StatusSeeOther, teamPath)
+			return
+		}
+		if !errors.Is(err, apperr.ErrInviteNotFound) && !errors.Is(err, apperr.ErrAlreadyMember) {
+			s.abortWithError(c, err, "failed accept invite")
+			return
+		}
+		appErr, _ := apperr.As(err)
+		page.Error = appErr.Message
+	}
+
+	// недействующее приглашение страница показывает без формы
+	invite, err := s.disc.GetInvite(c.Request.Context(), page.Code)
+	if err != nil && !errors.Is(err, apperr.ErrInviteNotFound) {
+		s.abortWithError(c, err, "failed get invite")
+		return
+	}
+	page.Invite = invite
+
+	err = s.ui.Invite(c.Writer, page)
+	if err != nil {
+		s.log.Error("page handlerInvite", zap.Error(err))
+	}
+}
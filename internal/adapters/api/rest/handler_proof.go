@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+// maxProofRequestSize - предел тела запроса с файлами подтверждения.
+// Ограничения на каждый файл проверяет discipline.
+var maxProofRequestSize int64 = 64 << 20
+
+// sendPlayerQuest отправляет квест на проверку вместе с комментарием и файлами из формы.
+func (s *Server) sendPlayerQuest(c *gin.Context, questID, playerID uint) (*types.TPlayerQuest, error) {
+	proof := &types.TProof{
+		Comment: c.PostForm("comment"),
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, apperr.ErrProofFileTooLarge
+		}
+		return nil, fmt.Errorf("failed parse multipart form: %w", err)
+	}
+	if form != nil {
+		for _, fh := range form.File["files"] {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed open proof file: %w", err)
+			}
+			defer func(f multipart.File) {
+				if err := f.Close(); err != nil {
+					s.log.Error("failed close proof file", zap.Error(err))
+				}
+			}(f)
+			proof.Files = append(proof.Files, types.TProofFile{
+				Name:    fh.Filename,
+				Size:    fh.Size,
+				Content: f,
+			})
+		}
+	}
+
+	quest, err := s.disc.SendQuestPlayer(c.Request.Context(), questID, playerID, proof)
+	if err != nil {
+		return nil, fmt.Errorf("failed send quest player: %w", err)
+	}
+	return quest, nil
+}
+
+func (s *Server) handlerProofAttachment(c *gin.Context) {
+	attachmentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		s.log.Error("invalid id attachment", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	attachment, content, err := s.disc.OpenProofAttachment(c.Request.Context(), uint(attachmentID), user.ID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.log.Error("failed open proof attachment", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.writeProofAttachment(c, attachment, content)
+}
+
+// writeProofAttachment отдает файл подтверждения и закрывает его.
+func (s *Server) writeProofAttachment(c *gin.Context, attachment *types.TProofAttachment, content io.ReadCloser) {
+	defer func() {
+		if err := content.Close(); err != nil {
+			s.log.Error("failed close proof attachment", zap.Error(err))
+		}
+	}()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("inline; filename=%q", attachment.Name),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
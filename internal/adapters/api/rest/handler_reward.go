@@ -0,0 +1,300 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// rewardFromForm заполняет награду из формы создания и редактирования.
+// Числа, которые не удалось разобрать, возвращаются как ошибки полей.
+func rewardFromForm(c *gin.Context, reward *types.TReward) error {
+	var formErr *apperr.Error
+	number := func(field, message string) uint {
+		value := strings.TrimSpace(c.PostForm(field))
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			if formErr == nil {
+				formErr = apperr.ErrValidation
+			}
+			formErr = formErr.WithField(field, message)
+		}
+		return uint(n)
+	}
+
+	reward.Title = c.PostForm("title")
+	reward.Description = c.PostForm("description")
+	reward.IsActive = c.PostForm("active") == "on"
+	reward.IsUnlimited = c.PostForm("stock_unlimited") == "on"
+	reward.Cost = number("cost", "Стоимость должна быть целым положительным числом")
+	if !reward.IsUnlimited {
+		reward.Stock = number("stock", "Количество должно быть целым неотрицательным числом")
+	}
+
+	if formErr != nil {
+		return formErr
+	}
+	return nil
+}
+
+func (s *Server) handlerRewards(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	rewards, err := s.disc.GetRewards(c.Request.Context(), user.Master.ID)
+	if err != nil {
+		s.log.Error("failed get rewards", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ui.Rewards(c.Writer, &types.TRewardsPage{
+		User:    *user,
+		Rewards: *rewards,
+	})
+	if err != nil {
+		s.log.Error("page handlerRewards", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerRewardNew(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	page := &types.TRewardNew{
+		User: *user,
+		Reward: types.TReward{
+			IsUnlimited: true,
+			IsActive:    true,
+		},
+	}
+
+	if c.Request.Method == http.MethodPost {
+		var reward *models.Reward
+		if err = rewardFromForm(c, &page.Reward); err == nil {
+			reward, err = s.disc.NewReward(c.Request.Context(), &page.Reward, user.Master.ID)
+		}
+		if err != nil {
+			s.log.Error("create reward", zap.Error(err))
+			page.Error, page.Fields = questErrorMessage(err, "Не удалось создать награду")
+		}
+
+		if err == nil {
+			c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/manager/rewards/%v", reward.ID))
+			return
+		}
+	}
+
+	err = s.ui.RewardNew(c.Writer, page)
+	if err != nil {
+		s.log.Error("page RewardNew", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerRewardEdit(c *gin.Context) {
+	sID := c.Param("id")
+	rewardID, err := strconv.Atoi(sID)
+	if err != nil {
+		s.log.Error("invalid id reward", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	page := &types.TRewardEdit{
+		User: *user,
+		Reward: types.TReward{
+			ID: uint(rewardID),
+		},
+	}
+
+	if c.Request.Method == http.MethodPost {
+		var reward *types.TReward
+		if err = rewardFromForm(c, &page.Reward); err == nil {
+			reward, err = s.disc.EditReward(c.Request.Context(), &page.Reward, user.Master.ID)
+		}
+		if err != nil {
+			if errors.Is(err, apperr.ErrDataNotFound) {
+				c.Writer.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s.log.Error("edit reward", zap.Error(err))
+			page.Error, page.Fields = questErrorMessage(err, "Не удалось обновить награду")
+		} else {
+			page.Reward = *reward
+			page.Success = "Награда обновлена"
+		}
+	} else {
+		reward, err := s.disc.GetReward(c.Request.Context(), uint(rewardID), user.Master.ID)
+		if err != nil {
+			if errors.Is(err, apperr.ErrDataNotFound) {
+				c.Writer.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s.log.Error("failed get reward", zap.Error(err), zap.Int("reward_id", rewardID))
+			c.Writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		page.Reward = *reward
+	}
+
+	err = s.ui.RewardEdit(c.Writer, page)
+	if err != nil {
+		s.log.Error("page RewardEdit", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerRewardPurchases(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	purchases, err := s.disc.GetRewardPurchases(c.Request.Context(), user.Master.ID)
+	if err != nil {
+		s.log.Error("failed get reward purchases", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ui.RewardPurchases(c.Writer, &types.TRewardPurchasesPage{
+		User:      *user,
+		Purchases: *purchases,
+	})
+	if err != nil {
+		s.log.Error("page handlerRewardPurchases", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerAPIManageRewardPurchase(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bBody, _ := s.readBody(c)
+	jBody := tRequestAPIManageRewardPurchase{}
+	err = json.Unmarshal(bBody, &jBody)
+	if err != nil {
+		s.log.Error("failed parse body", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var fulfil bool
+	switch jBody.Action {
+	case actionPurchaseFulfil:
+		fulfil = true
+	case actionPurchaseRefund:
+		fulfil = false
+	default:
+		s.abortWithError(c, apperr.ErrBadRequest.WithField("action", "Укажите действие fulfil или refund"), "")
+		return
+	}
+
+	err = s.disc.ManageRewardPurchase(c.Request.Context(), jBody.ID, user.Master.ID, fulfil)
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, apperr.ErrPurchaseAlreadyClosed) {
+			c.JSON(http.StatusConflict, gin.H{
+				"status":  false,
+				"message": "Покупка уже обработана",
+			})
+			return
+		}
+		s.log.Error("failed manage reward purchase", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": true,
+	})
+}
+
+func (s *Server) handlerPlayerRewards(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	page := &types.TPlayerRewardsPage{
+		User: *user,
+	}
+
+	if c.Request.Method == http.MethodPost && c.PostForm("action") == "buy" {
+		rewardID, _ := strconv.Atoi(c.PostForm("id"))
+		err = s.disc.BuyReward(c.Request.Context(), uint(rewardID), user.ID)
+		switch {
+		case err == nil:
+			page.Success = "Награда куплена"
+		case errors.Is(err, apperr.ErrInsufficientFunds):
+			page.Error = "Недостаточно баллов"
+		case errors.Is(err, apperr.ErrRewardOutOfStock):
+			page.Error = "Награда закончилась"
+		case errors.Is(err, apperr.ErrDataNotFound):
+			page.Error = "Награда недоступна"
+		default:
+			s.log.Error("failed buy reward", zap.Error(err))
+			c.Writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	rewards, err := s.disc.GetPlayerRewards(c.Request.Context(), user.ID)
+	if err != nil {
+		s.log.Error("failed get player rewards", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	page.Rewards = *rewards
+
+	purchases, err := s.disc.GetPlayerPurchases(c.Request.Context(), user.ID)
+	if err != nil {
+		s.log.Error("failed get player purchases", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	page.Purchases = *purchases
+
+	err = s.ui.PlayerRewards(c.Writer, page)
+	if err != nil {
+		s.log.Error("page PlayerRewards", zap.Error(err))
+	}
+}
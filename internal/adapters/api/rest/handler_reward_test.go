@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+func TestRewardFromForm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		form      url.Values
+		wantCost  uint
+		wantStock uint
+		fields    []string
+	}{
+		{name: "valid", form: url.Values{"title": {"Кино"}, "cost": {"10"}, "stock": {"3"}}, wantCost: 10, wantStock: 3},
+		{name: "unlimited ignores stock", form: url.Values{"cost": {"10"}, "stock": {"x"}, "stock_unlimited": {"on"}}, wantCost: 10},
+		{name: "negative cost", form: url.Values{"cost": {"-5"}, "stock": {"1"}}, wantStock: 1, fields: []string{"cost"}},
+		{name: "invalid numbers", form: url.Values{"cost": {"дорого"}, "stock": {"-1"}}, fields: []string{"cost", "stock"}},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/manager/rewards/new", strings.NewReader(tt.form.Encode()))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		reward := types.TReward{}
+		err := rewardFromForm(c, &reward)
+		if reward.Cost != tt.wantCost || reward.Stock != tt.wantStock {
+			t.Errorf("%s: reward = {Cost: %d, Stock: %d}, want {Cost: %d, Stock: %d}", tt.name, reward.Cost, reward.Stock, tt.wantCost, tt.wantStock)
+		}
+		appErr, _ := apperr.As(err)
+		if len(tt.fields) == 0 {
+			if err != nil {
+				t.Errorf("%s: rewardFromForm() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if appErr == nil || len(appErr.Details) != len(tt.fields) {
+			t.Errorf("%s: rewardFromForm() error = %v, want fields %v", tt.name, err, tt.fields)
+			continue
+		}
+		for _, field := range tt.fields {
+			if appErr.Details[field] == "" {
+				t.Errorf("%s: rewardFromForm() details = %v, want field %s", tt.name, appErr.Details, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// apiV1Path - префикс JSON API для мобильного клиента. Ответы API не проходят
+// через страницы ошибок userInterface.
+const apiV1Path = "/api/v1"
+
+// v1ReadJSON разбирает тело запроса, при ошибке отвечает 400.
+func (s *Server) v1ReadJSON(c *gin.Context, v any) bool {
+	bBody, status := s.readBody(c)
+	if status != 0 {
+		s.abortWithError(c, apperr.ErrInternal, "")
+		return false
+	}
+	if err := json.Unmarshal(bBody, v); err != nil {
+		s.abortWithError(c, apperr.ErrBadRequest.Wrap(err), "")
+		return false
+	}
+	return true
+}
+
+// v1ParamID возвращает числовой параметр пути, при ошибке отвечает 404.
+func (s *Server) v1ParamID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		s.abortWithError(c, apperr.ErrDataNotFound.Wrap(err), "")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// v1User - пользователь текущей сессии, гарантирован middlewareAuthentication.
+func (s *Server) v1User(c *gin.Context) *types.TUser {
+	user, _ := s.auth.GetUser(c)
+	return user
+}
+
+func (s *Server) handlerV1Registration(c *gin.Context) {
+	req := tV1Credentials{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	err := s.disc.Registration(c.Request.Context(), req.Login, req.Password)
+	if err != nil {
+		s.abortWithError(c, err, "failed register user")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"status": true,
+	})
+}
+
+func (s *Server) handlerV1Login(c *gin.Context) {
+	req := tV1Credentials{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	tokens, err := s.authorization(c, req.Login, req.Password)
+	if err != nil {
+		s.abortWithError(c, err, "failed authorization")
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	c.JSON(http.StatusOK, tV1Login{
+		tV1User:   v1UserResponse(user),
+		tV1Tokens: v1TokensResponse(tokens),
+	})
+}
+
+// v1ReadRefresh читает необязательное тело с токеном обновления,
+// без тела токен берется из cookie.
+func (s *Server) v1ReadRefresh(c *gin.Context) (string, bool) {
+	req := tV1Refresh{}
+	if c.Request.ContentLength != 0 && !s.v1ReadJSON(c, &req) {
+		return "", false
+	}
+	return req.RefreshToken, true
+}
+
+func (s *Server) handlerV1Refresh(c *gin.Context) {
+	refresh, ok := s.v1ReadRefresh(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := s.auth.Refresh(c, refresh)
+	if err != nil {
+		s.abortWithError(c, err, "failed refresh tokens")
+		return
+	}
+	c.JSON(http.StatusOK, v1TokensResponse(tokens))
+}
+
+func (s *Server) handlerV1Logout(c *gin.Context) {
+	refresh, ok := s.v1ReadRefresh(c)
+	if !ok {
+		return
+	}
+	if err := s.auth.SignOut(c, refresh); err != nil {
+		s.log.Error("failed sign out", zap.Error(err))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1LogoutAll(c *gin.Context) {
+	err := s.auth.SignOutAll(c, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed logout all devices")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1User(c *gin.Context) {
+	// роли пользователя middlewareAuthentication читает из базы на каждом запросе
+	c.JSON(http.StatusOK, v1UserResponse(s.v1User(c)))
+}
+
+func (s *Server) handlerV1UserSessions(c *gin.Context) {
+	user := s.v1User(c)
+	sessions, err := s.disc.GetSessions(c.Request.Context(), user.ID, user.SessionID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get sessions")
+		return
+	}
+
+	result := []tV1Session{}
+	for _, session := range *sessions {
+		result = append(result, tV1Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.Current,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) handlerV1UserSessionRevoke(c *gin.Context) {
+	err := s.disc.RevokeSession(c.Request.Context(), s.v1User(c).ID, c.Param("id"))
+	if err != nil {
+		s.abortWithError(c, err, "failed revoke session")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1UserTimeZone(c *gin.Context) {
+	req := tV1TimeZone{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	err := s.disc.SetTimeZone(c.Request.Context(), s.v1User(c).ID, req.TimeZone)
+	if err != nil {
+		s.abortWithError(c, err, "failed set time zone")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handlerV1UserPassword меняет пароль и возвращает новые токены: остальные сессии завершаются.
+func (s *Server) handlerV1UserPassword(c *gin.Context) {
+	req := tV1PasswordChange{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	changed, err := s.disc.ChangePassword(c.Request.Context(), s.v1User(c).ID, req.CurrentPassword, req.Password)
+	if err != nil {
+		s.abortWithError(c, err, "failed change password")
+		return
+	}
+	tokens, err := s.auth.SignIn(c, changed)
+	if err != nil {
+		s.abortWithError(c, err, "failed sign in")
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user from session")
+		return
+	}
+	c.JSON(http.StatusOK, tV1Login{
+		tV1User:   v1UserResponse(user),
+		tV1Tokens: v1TokensResponse(tokens),
+	})
+}
+
+// handlerV1UserCreateMaster создает группу, название в теле необязательно.
+func (s *Server) handlerV1UserCreateMaster(c *gin.Context) {
+	req := tV1MasterName{}
+	if c.Request.ContentLength != 0 && !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	master, err := s.disc.ManageCreateSelfMaster(c.Request.Context(), s.v1User(c).ID, req.Name)
+	if err != nil {
+		s.abortWithError(c, err, "failed create master")
+		return
+	}
+
+	c.JSON(http.StatusCreated, tV1Master{
+		ID:     master.ID,
+		Name:   master.Name,
+		Code:   master.UniqueCode,
+		Role:   models.MemberOwner,
+		Active: true,
+	})
+}
+
+func (s *Server) handlerV1UserMasters(c *gin.Context) {
+	user := s.v1User(c)
+	resp := []tV1Master{}
+	for _, m := range user.Masters {
+		master := v1MasterResponse(m)
+		master.Active = m.ID == user.Master.ID
+		resp = append(resp, master)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1UserMasterSwitch(c *gin.Context) {
+	masterID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := s.disc.SwitchMaster(c.Request.Context(), s.v1User(c).ID, masterID)
+	if err != nil {
+		s.abortWithError(c, err, "failed switch master")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1UserInviteAccept(c *gin.Context) {
+	req := tV1InviteCode{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	err := s.disc.AcceptInvite(c.Request.Context(), req.Code, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed accept invite")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1SubmissionComments(c *gin.Context) {
+	statusID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	comments, err := s.disc.GetQuestComments(c.Request.Context(), statusID, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get quest comments")
+		return
+	}
+	c.JSON(http.StatusOK, v1Comments(*comments))
+}
+
+func (s *Server) handlerV1SubmissionCommentAdd(c *gin.Context) {
+	statusID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	req := tV1Comment{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	comment, err := s.disc.AddQuestComment(c.Request.Context(), statusID, s.v1User(c).ID, req.Text)
+	if err != nil {
+		s.abortWithError(c, err, "failed add quest comment")
+		return
+	}
+	c.JSON(http.StatusCreated, commentResponse(comment))
+}
+
+func (s *Server) handlerV1Proof(c *gin.Context) {
+	attachmentID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, content, err := s.disc.OpenProofAttachment(c.Request.Context(), attachmentID, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed open proof attachment")
+		return
+	}
+	s.writeProofAttachment(c, attachment, content)
+}
@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) handlerV1AdminRoles(c *gin.Context) {
+	roles, err := s.disc.GetRoles(c.Request.Context())
+	if err != nil {
+		s.abortWithError(c, err, "failed get roles")
+		return
+	}
+
+	resp := []tV1Role{}
+	for _, r := range *roles {
+		resp = append(resp, tV1Role{ID: r.ID, Name: r.Name, Actions: r.Actions})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1AdminUsers(c *gin.Context) {
+	users, err := s.disc.GetUsersWithRoles(c.Request.Context(), c.Query("q"), queryPage(c))
+	if err != nil {
+		s.abortWithError(c, err, "failed get users")
+		return
+	}
+
+	resp := tV1UsersPage{
+		Page:  users.Pagination.Page,
+		Pages: users.Pagination.Pages,
+		Total: users.Pagination.Total,
+		Users: []tV1UserRoles{},
+	}
+	for _, u := range users.Users {
+		resp.Users = append(resp.Users, v1UserRolesResponse(&u))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1AdminUser(c *gin.Context) {
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := s.disc.GetAdminUser(c.Request.Context(), userID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get user")
+		return
+	}
+
+	resp := tV1AdminUser{
+		tV1UserRoles: v1UserRolesResponse(&user.TUserRoles),
+		Masters:      []tV1AdminMaster{},
+	}
+	for _, m := range user.Masters {
+		master := tV1AdminMaster{ID: m.ID, Name: m.Name, Code: m.Code, Players: []tV1Player{}, Quests: []tV1Quest{}}
+		for _, p := range m.Players {
+			master.Players = append(master.Players, tV1Player{ID: p.ID, Name: p.Name})
+		}
+		for _, q := range m.Quests {
+			master.Quests = append(master.Quests, v1QuestResponse(&q))
+		}
+		resp.Masters = append(resp.Masters, master)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1AdminUserRoles(c *gin.Context) {
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	req := tV1Roles{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	err := s.disc.SetUserRoles(c.Request.Context(), s.v1User(c).ID, userID, req.Roles)
+	if err != nil {
+		s.abortWithError(c, err, "failed set user roles")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1AdminUserLock(c *gin.Context) {
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.disc.LockUser(c.Request.Context(), s.v1User(c).ID, userID); err != nil {
+		s.abortWithError(c, err, "failed lock user")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1AdminUserUnlock(c *gin.Context) {
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := s.disc.UnlockUser(c.Request.Context(), userID); err != nil {
+		s.abortWithError(c, err, "failed unlock user")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1AdminUserPassword(c *gin.Context) {
+	userID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	password, err := s.disc.ResetPassword(c.Request.Context(), userID)
+	if err != nil {
+		s.abortWithError(c, err, "failed reset password")
+		return
+	}
+	c.JSON(http.StatusOK, tV1Password{Password: password})
+}
+
+func (s *Server) handlerV1AdminHealth(c *gin.Context) {
+	health, err := s.disc.GetHealth(c.Request.Context())
+	if err != nil {
+		s.abortWithError(c, err, "failed get health")
+		return
+	}
+	c.JSON(http.StatusOK, tV1Health{
+		Users:        health.Users,
+		LockedUsers:  health.LockedUsers,
+		Masters:      health.Masters,
+		UnpaidQuests: health.UnpaidQuests,
+		OldestUnpaid: health.OldestUnpaid,
+	})
+}
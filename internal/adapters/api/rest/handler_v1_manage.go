@@ -0,0 +1,404 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+func (s *Server) handlerV1ManagePlayers(c *gin.Context) {
+	players, err := s.disc.GetPlayers(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get players")
+		return
+	}
+
+	resp := []tV1Player{}
+	for _, p := range *players {
+		resp = append(resp, tV1Player{ID: p.ID, Name: p.Name})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1ManageQuests(c *gin.Context) {
+	quests, err := s.disc.GetQuests(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil && !errors.Is(err, apperr.ErrDataNotFound) {
+		s.abortWithError(c, err, "failed get quests")
+		return
+	}
+
+	resp := []tV1Quest{}
+	for _, q := range *quests {
+		resp = append(resp, v1QuestResponse(&q))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1ManageQuest(c *gin.Context) {
+	questID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	quest, err := s.disc.GetQuest(c.Request.Context(), questID, s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get quest")
+		return
+	}
+	c.JSON(http.StatusOK, v1QuestResponse(quest))
+}
+
+// v1QuestFromRequest читает квест из запроса и отмечает выбранных игроков мастера.
+func (s *Server) v1QuestFromRequest(c *gin.Context, questID uint) (*types.TQuest, bool) {
+	req := tV1QuestRequest{}
+	if !s.v1ReadJSON(c, &req) {
+		return nil, false
+	}
+
+	players, err := s.disc.GetPlayers(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get players")
+		return nil, false
+	}
+	return req.toQuest(questID, *players), true
+}
+
+func (s *Server) handlerV1ManageQuestNew(c *gin.Context) {
+	quest, ok := s.v1QuestFromRequest(c, 0)
+	if !ok {
+		return
+	}
+
+	user := s.v1User(c)
+	created, err := s.disc.NewQuest(c.Request.Context(), quest, user.Master.ID, user.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed create quest")
+		return
+	}
+
+	result, err := s.disc.GetQuest(c.Request.Context(), created.ID, user.Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get quest")
+		return
+	}
+	c.JSON(http.StatusCreated, v1QuestResponse(result))
+}
+
+func (s *Server) handlerV1ManageQuestEdit(c *gin.Context) {
+	questID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	quest, ok := s.v1QuestFromRequest(c, questID)
+	if !ok {
+		return
+	}
+
+	_, err := s.disc.EditQuest(c.Request.Context(), quest, s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed update quest")
+		return
+	}
+
+	result, err := s.disc.GetQuest(c.Request.Context(), questID, s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get quest")
+		return
+	}
+	c.JSON(http.StatusOK, v1QuestResponse(result))
+}
+
+func (s *Server) handlerV1ManageSubmissions(c *gin.Context) {
+	awaits, err := s.disc.GetAwaitQuests(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get await quests")
+		return
+	}
+
+	resp := []tV1Submission{}
+	for _, a := range *awaits {
+		resp = append(resp, v1SubmissionResponse(&a))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1ManageSubmissionConfirm(c *gin.Context) {
+	statusID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := s.disc.ManageQuestConfirmation(c.Request.Context(), statusID, s.v1User(c).Master.ID, true, "")
+	if err != nil {
+		s.abortWithError(c, err, "failed confirmation quest")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageSubmissionReject(c *gin.Context) {
+	statusID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	req := tV1Reject{}
+	if c.Request.ContentLength != 0 && !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	err := s.disc.ManageQuestConfirmation(c.Request.Context(), statusID, s.v1User(c).Master.ID, false, req.Reason)
+	if err != nil {
+		s.abortWithError(c, err, "failed reject quest")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageRewards(c *gin.Context) {
+	rewards, err := s.disc.GetRewards(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get rewards")
+		return
+	}
+
+	resp := []tV1Reward{}
+	for _, r := range *rewards {
+		resp = append(resp, v1RewardResponse(&r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1ManageReward(c *gin.Context) {
+	rewardID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	reward, err := s.disc.GetReward(c.Request.Context(), rewardID, s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get reward")
+		return
+	}
+	c.JSON(http.StatusOK, v1RewardResponse(reward))
+}
+
+func (s *Server) handlerV1ManageRewardNew(c *gin.Context) {
+	req := tV1RewardRequest{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+	masterID := s.v1User(c).Master.ID
+
+	created, err := s.disc.NewReward(c.Request.Context(), req.toReward(0), masterID)
+	if err != nil {
+		s.abortWithError(c, err, "failed create reward")
+		return
+	}
+
+	reward, err := s.disc.GetReward(c.Request.Context(), created.ID, masterID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get reward")
+		return
+	}
+	c.JSON(http.StatusCreated, v1RewardResponse(reward))
+}
+
+func (s *Server) handlerV1ManageRewardEdit(c *gin.Context) {
+	rewardID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	req := tV1RewardRequest{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	reward, err := s.disc.EditReward(c.Request.Context(), req.toReward(rewardID), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed update reward")
+		return
+	}
+	c.JSON(http.StatusOK, v1RewardResponse(reward))
+}
+
+func (s *Server) handlerV1ManagePurchases(c *gin.Context) {
+	purchases, err := s.disc.GetRewardPurchases(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get reward purchases")
+		return
+	}
+	c.JSON(http.StatusOK, v1PurchasesResponse(*purchases))
+}
+
+func (s *Server) handlerV1ManagePurchaseFulfil(c *gin.Context) {
+	s.v1ManagePurchase(c, true)
+}
+
+func (s *Server) handlerV1ManagePurchaseRefund(c *gin.Context) {
+	s.v1ManagePurchase(c, false)
+}
+
+func (s *Server) v1ManagePurchase(c *gin.Context, fulfil bool) {
+	purchaseID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := s.disc.ManageRewardPurchase(c.Request.Context(), purchaseID, s.v1User(c).Master.ID, fulfil)
+	if err != nil {
+		s.abortWithError(c, err, "failed manage reward purchase")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageWallets(c *gin.Context) {
+	wallets, err := s.disc.GetMasterWallets(c.Request.Context(), s.v1User(c).Master.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get master wallets")
+		return
+	}
+	c.JSON(http.StatusOK, v1WalletsResponse(*wallets))
+}
+
+func (s *Server) handlerV1ManageWalletTransactions(c *gin.Context) {
+	walletID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	history, err := s.disc.GetMasterWalletHistory(c.Request.Context(), walletID, s.v1User(c).Master.ID, queryPage(c))
+	if err != nil {
+		s.abortWithError(c, err, "failed get wallet history")
+		return
+	}
+	c.JSON(http.StatusOK, walletHistoryResponse(history))
+}
+
+func (s *Server) handlerV1ManageWalletAdjust(c *gin.Context) {
+	req := tV1WalletAdjust{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+	if req.Amount == 0 {
+		s.abortWithError(c, apperr.ErrBadRequest, "")
+		return
+	}
+	user := s.v1User(c)
+
+	err := s.disc.AdjustWallet(c.Request.Context(), user.Master.ID, req.PlayerID, user.ID, req.Amount, req.Comment)
+	if err != nil {
+		s.abortWithError(c, err, "failed adjust wallet")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageTransactionReverse(c *gin.Context) {
+	transactionID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	user := s.v1User(c)
+
+	err := s.disc.ReverseWalletTransaction(c.Request.Context(), transactionID, user.Master.ID, user.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed reverse wallet transaction")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// handlerV1ManageMasterRename переименовывает активную группу, доступно ее владельцу.
+func (s *Server) handlerV1ManageMasterRename(c *gin.Context) {
+	req := tV1MasterName{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+
+	user := s.v1User(c)
+	err := s.disc.RenameMaster(c.Request.Context(), user.Master.ID, user.ID, req.Name)
+	if err != nil {
+		s.abortWithError(c, err, "failed rename master")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageMembers(c *gin.Context) {
+	user := s.v1User(c)
+	team, err := s.disc.GetTeam(c.Request.Context(), user.Master.ID, user.ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get team")
+		return
+	}
+	c.JSON(http.StatusOK, v1TeamResponse(team))
+}
+
+func (s *Server) handlerV1ManageMemberRole(c *gin.Context) {
+	memberID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	req := tV1MemberRole{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+	user := s.v1User(c)
+
+	err := s.disc.SetMemberRole(c.Request.Context(), user.Master.ID, user.ID, memberID, req.Role)
+	if err != nil {
+		s.abortWithError(c, err, "failed set member role")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageMemberRemove(c *gin.Context) {
+	memberID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	user := s.v1User(c)
+
+	err := s.disc.RemoveMember(c.Request.Context(), user.Master.ID, user.ID, memberID)
+	if err != nil {
+		s.abortWithError(c, err, "failed remove member")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1ManageInviteNew(c *gin.Context) {
+	req := tV1MemberRole{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+	user := s.v1User(c)
+
+	invite, err := s.disc.NewInvite(c.Request.Context(), user.Master.ID, user.ID, req.Role)
+	if err != nil {
+		s.abortWithError(c, err, "failed create invite")
+		return
+	}
+	c.JSON(http.StatusCreated, v1InviteResponse(invite))
+}
+
+func (s *Server) handlerV1ManageInviteRevoke(c *gin.Context) {
+	inviteID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+	user := s.v1User(c)
+
+	err := s.disc.RevokeInvite(c.Request.Context(), user.Master.ID, user.ID, inviteID)
+	if err != nil {
+		s.abortWithError(c, err, "failed revoke invite")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
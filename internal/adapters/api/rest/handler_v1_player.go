@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+)
+
+func (s *Server) handlerV1PlayerQuests(c *gin.Context) {
+	quests, err := s.disc.GetQuestsPlayer(c.Request.Context(), s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get player quests")
+		return
+	}
+
+	resp := []tV1PlayerQuest{}
+	for _, q := range *quests {
+		resp = append(resp, v1PlayerQuestResponse(&q))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1PlayerQuest(c *gin.Context) {
+	questID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	quest, err := s.disc.GetQuestPlayer(c.Request.Context(), questID, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get player quest")
+		return
+	}
+	c.JSON(http.StatusOK, v1PlayerQuestResponse(quest))
+}
+
+// handlerV1PlayerQuestSend отправляет квест на проверку. Тело - multipart/form-data
+// с полем comment и файлами files, как у формы на странице квеста.
+func (s *Server) handlerV1PlayerQuestSend(c *gin.Context) {
+	questID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxProofRequestSize)
+	quest, err := s.sendPlayerQuest(c, questID, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed send quest")
+		return
+	}
+	c.JSON(http.StatusCreated, v1PlayerQuestResponse(quest))
+}
+
+func (s *Server) handlerV1PlayerMasters(c *gin.Context) {
+	masters, err := s.disc.GetPlayerMasters(c.Request.Context(), s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get player masters")
+		return
+	}
+
+	resp := []tV1Master{}
+	for _, m := range *masters {
+		resp = append(resp, tV1Master{ID: m.ID, Name: m.Name, Code: m.UniqueCode})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1PlayerMasterAdd(c *gin.Context) {
+	req := tV1MasterCode{}
+	if !s.v1ReadJSON(c, &req) {
+		return
+	}
+	if req.Code == "" {
+		s.abortWithError(c, apperr.ErrBadRequest, "")
+		return
+	}
+
+	err := s.disc.AddMaster(c.Request.Context(), req.Code, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed add master")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1PlayerWallets(c *gin.Context) {
+	wallets, err := s.disc.GetWallets(c.Request.Context(), s.v1User(c).ID)
+	if err != nil && !errors.Is(err, apperr.ErrDataNotFound) {
+		s.abortWithError(c, err, "failed get wallets")
+		return
+	}
+	c.JSON(http.StatusOK, v1WalletsResponse(*wallets))
+}
+
+func (s *Server) handlerV1PlayerWalletTransactions(c *gin.Context) {
+	history, err := s.disc.GetPlayerWalletHistory(c.Request.Context(), s.v1User(c).ID, queryPage(c))
+	if err != nil {
+		s.abortWithError(c, err, "failed get wallet history")
+		return
+	}
+	c.JSON(http.StatusOK, walletHistoryResponse(history))
+}
+
+func (s *Server) handlerV1PlayerRewards(c *gin.Context) {
+	rewards, err := s.disc.GetPlayerRewards(c.Request.Context(), s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get player rewards")
+		return
+	}
+
+	resp := []tV1PlayerReward{}
+	for _, r := range *rewards {
+		resp = append(resp, tV1PlayerReward{
+			ID:          r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Cost:        r.Cost,
+			Stock:       r.Stock,
+			IsUnlimited: r.IsUnlimited,
+			Score:       r.Score,
+			CanBuy:      r.CanBuy,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) handlerV1PlayerRewardBuy(c *gin.Context) {
+	rewardID, ok := s.v1ParamID(c, "id")
+	if !ok {
+		return
+	}
+
+	err := s.disc.BuyReward(c.Request.Context(), rewardID, s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed buy reward")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (s *Server) handlerV1PlayerPurchases(c *gin.Context) {
+	purchases, err := s.disc.GetPlayerPurchases(c.Request.Context(), s.v1User(c).ID)
+	if err != nil {
+		s.abortWithError(c, err, "failed get player purchases")
+		return
+	}
+	c.JSON(http.StatusOK, v1PurchasesResponse(*purchases))
+}
@@ -0,0 +1,248 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+)
+
+func queryPage(c *gin.Context) int {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+func walletHistoryResponse(history *types.TWalletHistory) tResponseWalletHistory {
+	resp := tResponseWalletHistory{
+		Status:       true,
+		Score:        history.Wallet.Score,
+		Page:         history.Pagination.Page,
+		Pages:        history.Pagination.Pages,
+		Total:        history.Pagination.Total,
+		Transactions: []tResponseWalletTransaction{},
+	}
+	for _, t := range history.Transactions {
+		resp.Transactions = append(resp.Transactions, tResponseWalletTransaction{
+			ID:         t.ID,
+			Type:       string(t.Type),
+			Amount:     t.Amount,
+			Balance:    t.Balance,
+			Comment:    t.Comment,
+			Date:       t.Date,
+			IsReversed: t.IsReversed,
+		})
+	}
+	return resp
+}
+
+func (s *Server) handlerWallets(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	wallets, err := s.disc.GetMasterWallets(c.Request.Context(), user.Master.ID)
+	if err != nil {
+		s.log.Error("failed get wallets", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ui.Wallets(c.Writer, &types.TWalletsPage{
+		User:    *user,
+		Wallets: *wallets,
+	})
+	if err != nil {
+		s.log.Error("page handlerWallets", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerWalletHistory(c *gin.Context) {
+	walletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		s.log.Error("invalid id wallet", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	history, err := s.disc.GetMasterWalletHistory(c.Request.Context(), uint(walletID), user.Master.ID, queryPage(c))
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.log.Error("failed get wallet history", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ui.WalletHistory(c.Writer, &types.TWalletHistoryPage{
+		User:    *user,
+		History: *history,
+	})
+	if err != nil {
+		s.log.Error("page handlerWalletHistory", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerPlayerWallet(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	history, err := s.disc.GetPlayerWalletHistory(c.Request.Context(), user.ID, queryPage(c))
+	if err != nil {
+		s.log.Error("failed get wallet history", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.ui.PlayerWallet(c.Writer, &types.TWalletHistoryPage{
+		User:    *user,
+		History: *history,
+	})
+	if err != nil {
+		s.log.Error("page handlerPlayerWallet", zap.Error(err))
+	}
+}
+
+func (s *Server) handlerAPIPlayerWalletTransactions(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	history, err := s.disc.GetPlayerWalletHistory(c.Request.Context(), user.ID, queryPage(c))
+	if err != nil {
+		s.log.Error("failed get wallet history", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, walletHistoryResponse(history))
+}
+
+func (s *Server) handlerAPIManageWalletTransactions(c *gin.Context) {
+	walletID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		s.log.Error("invalid id wallet", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	history, err := s.disc.GetMasterWalletHistory(c.Request.Context(), uint(walletID), user.Master.ID, queryPage(c))
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.log.Error("failed get wallet history", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, walletHistoryResponse(history))
+}
+
+func (s *Server) handlerAPIManageWalletAdjust(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bBody, _ := s.readBody(c)
+	jBody := tRequestAPIManageWalletAdjust{}
+	err = json.Unmarshal(bBody, &jBody)
+	if err != nil || jBody.Amount == 0 {
+		s.log.Error("failed parse body", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = s.disc.AdjustWallet(c.Request.Context(), user.Master.ID, jBody.PlayerID, user.ID, jBody.Amount, jBody.Comment)
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.log.Error("failed adjust wallet", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": true,
+	})
+}
+
+func (s *Server) handlerAPIManageWalletReverse(c *gin.Context) {
+	user, err := s.auth.GetUser(c)
+	if err != nil {
+		s.log.Error("failed get user from session", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	bBody, _ := s.readBody(c)
+	jBody := tRequestAPIManageWalletReverse{}
+	err = json.Unmarshal(bBody, &jBody)
+	if err != nil {
+		s.log.Error("failed parse body", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = s.disc.ReverseWalletTransaction(c.Request.Context(), jBody.ID, user.Master.ID, user.ID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrDataNotFound) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, apperr.ErrTransactionReversed) || errors.Is(err, apperr.ErrTransactionNotReversible) {
+			c.JSON(http.StatusConflict, gin.H{
+				"status":  false,
+				"message": "Операцию нельзя отменить",
+			})
+			return
+		}
+		s.log.Error("failed reverse wallet transaction", zap.Error(err))
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": true,
+	})
+}
@@ -0,0 +1,471 @@
+package rest
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// Документация API: спецификация OpenAPI 3 строится из таблицы apiDocs,
+// Swagger UI берет файлы из swaggo/files.
+const (
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs"
+)
+
+//go:embed swagger.html
+var swaggerPage []byte
+
+type tResponseStatus struct {
+	Status bool `json:"status"`
+}
+
+type tResponseUserInfo struct {
+	Status bool   `json:"status"`
+	UserID uint   `json:"userID"`
+	Login  string `json:"login"`
+}
+
+type tResponseCreateMaster struct {
+	Status     bool   `json:"status"`
+	MasterID   uint   `json:"masterID"`
+	MasterCode string `json:"masterCode"`
+}
+
+type tResponseQuestCommentAdded struct {
+	Status  bool                  `json:"status"`
+	Comment tResponseQuestComment `json:"comment"`
+}
+
+// apiDoc описывает один маршрут /api для спецификации.
+type apiDoc struct {
+	Method    string
+	Path      string // путь в формате gin
+	Tag       string
+	Summary   string
+	Auth      bool
+	Query     []string // необязательные параметры запроса
+	Request   any      // тело запроса JSON
+	Multipart bool     // тело запроса multipart/form-data с подтверждением квеста
+	Status    int      // код успешного ответа
+	Response  any      // тело успешного ответа JSON
+	Binary    bool     // ответ - файл
+}
+
+// apiDocs - все маршруты /api из SetupRouter. Тест проверяет, что список
+// совпадает с маршрутами роутера.
+var apiDocs = []apiDoc{
+	{Method: http.MethodPost, Path: "/api/v0/auth/registration", Tag: "v0", Summary: "Регистрация",
+		Request: tRequestRegistration{}, Status: http.StatusCreated, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v0/auth/authorization", Tag: "v0", Summary: "Вход",
+		Request: tRequestAuthorization{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodGet, Path: "/api/v0/user/info", Tag: "v0", Summary: "Текущий пользователь", Auth: true,
+		Status: http.StatusOK, Response: tResponseUserInfo{}},
+	{Method: http.MethodGet, Path: "/api/v0/user/wallet/transactions", Tag: "v0", Summary: "История кошелька игрока", Auth: true,
+		Query: []string{"page"}, Status: http.StatusOK, Response: tResponseWalletHistory{}},
+	{Method: http.MethodGet, Path: "/api/v0/user/quests/status/:id/comments", Tag: "v0", Summary: "Обсуждение отправки квеста", Auth: true,
+		Status: http.StatusOK, Response: tResponseQuestComments{}},
+	{Method: http.MethodPost, Path: "/api/v0/user/quests/status/:id/comments", Tag: "v0", Summary: "Сообщение в обсуждение", Auth: true,
+		Request: tRequestAPIQuestComment{}, Status: http.StatusOK, Response: tResponseQuestCommentAdded{}},
+	{Method: http.MethodPost, Path: "/api/v0/user/settings/self/master", Tag: "v0", Summary: "Стать мастером", Auth: true,
+		Status: http.StatusOK, Response: tResponseCreateMaster{}},
+	{Method: http.MethodPost, Path: "/api/v0/user/settings/player/master", Tag: "v0", Summary: "Добавить мастера по коду", Auth: true,
+		Request: tRequestAPISettingsAddMaster{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v0/user/settings/timezone", Tag: "v0", Summary: "Часовой пояс", Auth: true,
+		Request: tRequestAPISettingsTimeZone{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v0/manage/quests/status/confirmation", Tag: "v0", Summary: "Подтвердить или вернуть квест", Auth: true,
+		Request: tRequestApiManageQuestConfirmation{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v0/manage/rewards/purchases/status", Tag: "v0", Summary: "Выдать награду или вернуть баллы", Auth: true,
+		Request: tRequestAPIManageRewardPurchase{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodGet, Path: "/api/v0/manage/wallets/:id/transactions", Tag: "v0", Summary: "История кошелька игрока", Auth: true,
+		Query: []string{"page"}, Status: http.StatusOK, Response: tResponseWalletHistory{}},
+	{Method: http.MethodPost, Path: "/api/v0/manage/wallets/adjust", Tag: "v0", Summary: "Начислить или списать баллы", Auth: true,
+		Request: tRequestAPIManageWalletAdjust{}, Status: http.StatusOK, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v0/manage/wallets/transactions/reverse", Tag: "v0", Summary: "Отменить операцию", Auth: true,
+		Request: tRequestAPIManageWalletReverse{}, Status: http.StatusOK, Response: tResponseStatus{}},
+
+	{Method: http.MethodPost, Path: "/api/v1/auth/registration", Tag: "auth", Summary: "Регистрация",
+		Request: tV1Credentials{}, Status: http.StatusCreated, Response: tResponseStatus{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/login", Tag: "auth", Summary: "Вход",
+		Request: tV1Credentials{}, Status: http.StatusOK, Response: tV1Login{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/refresh", Tag: "auth", Summary: "Обновление токенов",
+		Request: tV1Refresh{}, Status: http.StatusOK, Response: tV1Tokens{}},
+	{Method: http.MethodPost, Path: "/api/v1/auth/logout", Tag: "auth", Summary: "Выход",
+		Request: tV1Refresh{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/auth/logout-all", Tag: "auth", Summary: "Выход на всех устройствах", Auth: true,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/api/v1/user", Tag: "user", Summary: "Текущий пользователь", Auth: true,
+		Status: http.StatusOK, Response: tV1User{}},
+	{Method: http.MethodPut, Path: "/api/v1/user/timezone", Tag: "user", Summary: "Часовой пояс", Auth: true,
+		Request: tV1TimeZone{}, Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/api/v1/user/password", Tag: "user", Summary: "Сменить пароль", Auth: true,
+		Request: tV1PasswordChange{}, Status: http.StatusOK, Response: tV1Login{}},
+	{Method: http.MethodGet, Path: "/api/v1/user/sessions", Tag: "user", Summary: "Активные сессии", Auth: true,
+		Status: http.StatusOK, Response: []tV1Session{}},
+	{Method: http.MethodDelete, Path: "/api/v1/user/sessions/:id", Tag: "user", Summary: "Завершить сессию", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/user/master", Tag: "user", Summary: "Создать группу мастера", Auth: true,
+		Request: tV1MasterName{}, Status: http.StatusCreated, Response: tV1Master{}},
+	{Method: http.MethodGet, Path: "/api/v1/user/masters", Tag: "user", Summary: "Группы, которыми управляет пользователь", Auth: true,
+		Status: http.StatusOK, Response: []tV1Master{}},
+	{Method: http.MethodPost, Path: "/api/v1/user/masters/:id/activate", Tag: "user", Summary: "Перейти в группу мастера", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/user/invites", Tag: "user", Summary: "Принять приглашение в группу мастера", Auth: true,
+		Request: tV1InviteCode{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/submissions/:id/comments", Tag: "user", Summary: "Обсуждение отправки квеста", Auth: true,
+		Status: http.StatusOK, Response: []tResponseQuestComment{}},
+	{Method: http.MethodPost, Path: "/api/v1/submissions/:id/comments", Tag: "user", Summary: "Сообщение в обсуждение", Auth: true,
+		Request: tV1Comment{}, Status: http.StatusCreated, Response: tResponseQuestComment{}},
+	{Method: http.MethodGet, Path: "/api/v1/proofs/:id", Tag: "user", Summary: "Файл подтверждения", Auth: true,
+		Status: http.StatusOK, Binary: true},
+
+	{Method: http.MethodGet, Path: "/api/v1/player/quests", Tag: "player", Summary: "Квесты игрока", Auth: true,
+		Status: http.StatusOK, Response: []tV1PlayerQuest{}},
+	{Method: http.MethodGet, Path: "/api/v1/player/quests/:id", Tag: "player", Summary: "Квест игрока", Auth: true,
+		Status: http.StatusOK, Response: tV1PlayerQuest{}},
+	{Method: http.MethodPost, Path: "/api/v1/player/quests/:id/submissions", Tag: "player", Summary: "Отправить квест на проверку", Auth: true,
+		Multipart: true, Status: http.StatusCreated, Response: tV1PlayerQuest{}},
+	{Method: http.MethodGet, Path: "/api/v1/player/masters", Tag: "player", Summary: "Мастера игрока", Auth: true,
+		Status: http.StatusOK, Response: []tV1Master{}},
+	{Method: http.MethodPost, Path: "/api/v1/player/masters", Tag: "player", Summary: "Добавить мастера по коду", Auth: true,
+		Request: tV1MasterCode{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/player/wallets", Tag: "player", Summary: "Кошельки игрока", Auth: true,
+		Status: http.StatusOK, Response: []tV1Wallet{}},
+	{Method: http.MethodGet, Path: "/api/v1/player/wallet/transactions", Tag: "player", Summary: "История кошелька", Auth: true,
+		Query: []string{"page"}, Status: http.StatusOK, Response: tResponseWalletHistory{}},
+	{Method: http.MethodGet, Path: "/api/v1/player/rewards", Tag: "player", Summary: "Награды", Auth: true,
+		Status: http.StatusOK, Response: []tV1PlayerReward{}},
+	{Method: http.MethodPost, Path: "/api/v1/player/rewards/:id/buy", Tag: "player", Summary: "Купить награду", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/player/purchases", Tag: "player", Summary: "Покупки игрока", Auth: true,
+		Status: http.StatusOK, Response: []tV1Purchase{}},
+
+	{Method: http.MethodGet, Path: "/api/v1/manage/players", Tag: "manage", Summary: "Игроки мастера", Auth: true,
+		Status: http.StatusOK, Response: []tV1Player{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/quests", Tag: "manage", Summary: "Квесты мастера", Auth: true,
+		Status: http.StatusOK, Response: []tV1Quest{}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/quests", Tag: "manage", Summary: "Создать квест", Auth: true,
+		Request: tV1QuestRequest{}, Status: http.StatusCreated, Response: tV1Quest{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/quests/:id", Tag: "manage", Summary: "Квест", Auth: true,
+		Status: http.StatusOK, Response: tV1Quest{}},
+	{Method: http.MethodPut, Path: "/api/v1/manage/quests/:id", Tag: "manage", Summary: "Изменить квест", Auth: true,
+		Request: tV1QuestRequest{}, Status: http.StatusOK, Response: tV1Quest{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/submissions", Tag: "manage", Summary: "Квесты на проверке", Auth: true,
+		Status: http.StatusOK, Response: []tV1Submission{}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/submissions/:id/confirm", Tag: "manage", Summary: "Подтвердить квест", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/manage/submissions/:id/reject", Tag: "manage", Summary: "Вернуть квест", Auth: true,
+		Request: tV1Reject{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/manage/rewards", Tag: "manage", Summary: "Награды мастера", Auth: true,
+		Status: http.StatusOK, Response: []tV1Reward{}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/rewards", Tag: "manage", Summary: "Создать награду", Auth: true,
+		Request: tV1RewardRequest{}, Status: http.StatusCreated, Response: tV1Reward{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/rewards/:id", Tag: "manage", Summary: "Награда", Auth: true,
+		Status: http.StatusOK, Response: tV1Reward{}},
+	{Method: http.MethodPut, Path: "/api/v1/manage/rewards/:id", Tag: "manage", Summary: "Изменить награду", Auth: true,
+		Request: tV1RewardRequest{}, Status: http.StatusOK, Response: tV1Reward{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/purchases", Tag: "manage", Summary: "Покупки наград", Auth: true,
+		Status: http.StatusOK, Response: []tV1Purchase{}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/purchases/:id/fulfil", Tag: "manage", Summary: "Выдать награду", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/manage/purchases/:id/refund", Tag: "manage", Summary: "Вернуть баллы за награду", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/manage/wallets", Tag: "manage", Summary: "Кошельки игроков", Auth: true,
+		Status: http.StatusOK, Response: []tV1Wallet{}},
+	{Method: http.MethodGet, Path: "/api/v1/manage/wallets/:id/transactions", Tag: "manage", Summary: "История кошелька игрока", Auth: true,
+		Query: []string{"page"}, Status: http.StatusOK, Response: tResponseWalletHistory{}},
+	{Method: http.MethodPost, Path: "/api/v1/manage/wallets/adjustments", Tag: "manage", Summary: "Начислить или списать баллы", Auth: true,
+		Request: tV1WalletAdjust{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/manage/transactions/:id/reverse", Tag: "manage", Summary: "Отменить операцию", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/api/v1/manage/master", Tag: "manage", Summary: "Переименовать группу мастера", Auth: true,
+		Request: tV1MasterName{}, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/v1/manage/members", Tag: "manage", Summary: "Участники группы мастера", Auth: true,
+		Status: http.StatusOK, Response: tV1Team{}},
+	{Method: http.MethodPut, Path: "/api/v1/manage/members/:id", Tag: "manage", Summary: "Изменить роль участника", Auth: true,
+		Request: tV1MemberRole{}, Status: http.StatusNoContent},
+	{Method: http.MethodDelete, Path: "/api/v1/manage/members/:id", Tag: "manage", Summary: "Удалить участника или выйти из группы", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/manage/invites", Tag: "manage", Summary: "Пригласить в группу мастера", Auth: true,
+		Request: tV1MemberRole{}, Status: http.StatusCreated, Response: tV1Invite{}},
+	{Method: http.MethodDelete, Path: "/api/v1/manage/invites/:id", Tag: "manage", Summary: "Отозвать приглашение", Auth: true,
+		Status: http.StatusNoContent},
+
+	{Method: http.MethodGet, Path: "/api/v1/admin/roles", Tag: "admin", Summary: "Роли и их действия", Auth: true,
+		Status: http.StatusOK, Response: []tV1Role{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/users", Tag: "admin", Summary: "Пользователи с ролями", Auth: true,
+		Query: []string{"q", "page"}, Status: http.StatusOK, Response: tV1UsersPage{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/users/:id", Tag: "admin", Summary: "Пользователь, игроки и квесты его группы", Auth: true,
+		Status: http.StatusOK, Response: tV1AdminUser{}},
+	{Method: http.MethodPut, Path: "/api/v1/admin/users/:id/roles", Tag: "admin", Summary: "Назначить роли", Auth: true,
+		Request: tV1Roles{}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/lock", Tag: "admin", Summary: "Заблокировать пользователя", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/unlock", Tag: "admin", Summary: "Разблокировать пользователя", Auth: true,
+		Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/password", Tag: "admin", Summary: "Сбросить пароль", Auth: true,
+		Status: http.StatusOK, Response: tV1Password{}},
+	{Method: http.MethodGet, Path: "/api/v1/admin/health", Tag: "admin", Summary: "Состояние системы", Auth: true,
+		Status: http.StatusOK, Response: tV1Health{}},
+}
+
+type openAPIDoc struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                   `json:"tags"`
+	Summary     string                     `json:"summary"`
+	OperationID string                     `json:"operationId"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+	Items      *openAPISchema            `json:"items,omitempty"`
+	Properties map[string]*openAPISchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+// openAPIPathFromGin переводит путь gin /a/:id в путь OpenAPI /a/{id}.
+func openAPIPathFromGin(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// OpenAPI строит спецификацию OpenAPI 3 для маршрутов /api.
+func OpenAPI() *openAPIDoc {
+	doc := &openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "Medal of Discipline API",
+			Version: "1.0.0",
+		},
+		Paths: map[string]map[string]openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: map[string]*openAPISchema{},
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"cookieAuth": {Type: "apiKey", In: "cookie", Name: cookieName},
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	errorSchema := doc.schema(reflect.TypeOf(tResponseError{}))
+
+	for _, d := range apiDocs {
+		path := openAPIPathFromGin(d.Path)
+		op := openAPIOperation{
+			Tags:        []string{d.Tag},
+			Summary:     d.Summary,
+			OperationID: operationID(d.Method, d.Path),
+			Responses:   map[string]openAPIResponse{},
+		}
+		for _, m := range ginParam.FindAllStringSubmatch(d.Path, -1) {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: m[1], In: "path", Required: true, Schema: &openAPISchema{Type: "integer"},
+			})
+		}
+		for _, q := range d.Query {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: q, In: "query", Schema: &openAPISchema{Type: "integer"},
+			})
+		}
+		if d.Auth {
+			op.Security = []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {}}}
+		}
+		switch {
+		case d.Multipart:
+			op.RequestBody = &openAPIBody{
+				Required: true,
+				Content: map[string]openAPIMediaType{
+					"multipart/form-data": {Schema: &openAPISchema{
+						Type: "object",
+						Properties: map[string]*openAPISchema{
+							"comment": {Type: "string"},
+							"files":   {Type: "array", Items: &openAPISchema{Type: "string", Format: "binary"}},
+						},
+					}},
+				},
+			}
+		case d.Request != nil:
+			op.RequestBody = &openAPIBody{
+				Required: true,
+				Content: map[string]openAPIMediaType{
+					ApplicationJSON: {Schema: doc.schema(reflect.TypeOf(d.Request))},
+				},
+			}
+		}
+
+		success := openAPIResponse{Description: http.StatusText(d.Status)}
+		switch {
+		case d.Binary:
+			success.Content = map[string]openAPIMediaType{
+				"application/octet-stream": {Schema: &openAPISchema{Type: "string", Format: "binary"}},
+			}
+		case d.Response != nil:
+			success.Content = map[string]openAPIMediaType{
+				ApplicationJSON: {Schema: doc.schema(reflect.TypeOf(d.Response))},
+			}
+		}
+		op.Responses[strconv.Itoa(d.Status)] = success
+		op.Responses["default"] = openAPIResponse{
+			Description: "Ошибка",
+			Content:     map[string]openAPIMediaType{ApplicationJSON: {Schema: errorSchema}},
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]openAPIOperation{}
+		}
+		doc.Paths[path][strings.ToLower(d.Method)] = op
+	}
+	return doc
+}
+
+// schema описывает тип Go по его json тегам. Структуры выносятся в components.
+func (doc *openAPIDoc) schema(t reflect.Type) *openAPISchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return doc.schema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: doc.schema(t.Elem())}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.Struct:
+	default:
+		return &openAPISchema{}
+	}
+
+	name := strings.TrimPrefix(t.Name(), "t")
+	ref := &openAPISchema{Ref: "#/components/schemas/" + name}
+	if _, ok := doc.Components.Schemas[name]; ok {
+		return ref
+	}
+	s := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	doc.Components.Schemas[name] = s
+	doc.fields(s, t)
+	return ref
+}
+
+// fields добавляет в схему s поля структуры t. Поля встроенных структур
+// без json тега поднимаются на уровень t, как их кодирует encoding/json.
+func (doc *openAPIDoc) fields(s *openAPISchema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			doc.fields(s, f.Type)
+			continue
+		}
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+		if fieldName == "" {
+			fieldName = f.Name
+		}
+		s.Properties[fieldName] = doc.schema(f.Type)
+		if opts != "omitempty" {
+			s.Required = append(s.Required, fieldName)
+		}
+	}
+}
+
+// operationID строит идентификатор операции: POST /api/v1/user/master -> postApiV1UserMaster.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(path, "/") {
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, ":") {
+			part = "by_" + part[1:]
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '_' || r == '-' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+func (s *Server) handlerOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, OpenAPI())
+}
+
+func (s *Server) handlerSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerPage)
+}
+
+// setupDocs подключает спецификацию и Swagger UI.
+func (s *Server) setupDocs(r *gin.Engine) {
+	r.GET(openAPIPath, s.handlerOpenAPI)
+	r.GET(docsPath, s.handlerSwaggerUI)
+	r.StaticFS(docsPath+"/assets", http.FS(swaggerFiles.FS))
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/auth"
+)
+
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	authManager, err := auth.New(nil, auth.SetSecretKey([]byte("test_secret_key_of_thirty_two_by")))
+	if err != nil {
+		t.Fatalf("failed create auth: %v", err)
+	}
+	return New(nil, nil, authManager).SetupRouter()
+}
+
+// TestOpenAPIRoutes проверяет, что спецификация описывает ровно маршруты /api роутера.
+func TestOpenAPIRoutes(t *testing.T) {
+	routes := map[string]bool{}
+	for _, r := range testRouter(t).Routes() {
+		if !strings.HasPrefix(r.Path, "/api/") || r.Path == openAPIPath || strings.HasPrefix(r.Path, docsPath) {
+			continue
+		}
+		routes[r.Method+" "+openAPIPathFromGin(r.Path)] = true
+	}
+
+	spec := map[string]bool{}
+	for path, ops := range OpenAPI().Paths {
+		for method := range ops {
+			spec[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		if !spec[route] {
+			t.Errorf("route %s is missing in OpenAPI spec", route)
+		}
+	}
+	for route := range spec {
+		if !routes[route] {
+			t.Errorf("OpenAPI spec describes unknown route %s", route)
+		}
+	}
+	if len(apiDocs) != len(spec) {
+		t.Errorf("apiDocs has duplicate routes: %d docs for %d routes", len(apiDocs), len(spec))
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	r := testRouter(t)
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+	}{
+		{name: "spec", path: openAPIPath, contentType: ApplicationJSON},
+		{name: "swagger ui", path: docsPath, contentType: "text/html"},
+		{name: "swagger ui assets", path: docsPath + "/assets/swagger-ui-bundle.js", contentType: "javascript"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if ct := rec.Header().Get(ContentType); !strings.Contains(ct, tt.contentType) {
+				t.Errorf("content type = %q, want %q", ct, tt.contentType)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+	doc := map[string]any{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("spec is not valid json: %v", err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v, want 3.0.3", doc["openapi"])
+	}
+}
+
+// TestOpenAPISchemaRefs проверяет, что все ссылки на схемы ведут в components.
+func TestOpenAPISchemaRefs(t *testing.T) {
+	doc := OpenAPI()
+	var check func(where string, s *openAPISchema)
+	check = func(where string, s *openAPISchema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+			if _, ok := doc.Components.Schemas[name]; !ok {
+				t.Errorf("%s: unknown schema %s", where, s.Ref)
+			}
+		}
+		check(where, s.Items)
+		for _, p := range s.Properties {
+			check(where, p)
+		}
+	}
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			where := method + " " + path
+			if op.RequestBody != nil {
+				for _, m := range op.RequestBody.Content {
+					check(where, m.Schema)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, m := range resp.Content {
+					check(where, m.Schema)
+				}
+			}
+		}
+	}
+	for name, s := range doc.Components.Schemas {
+		check(name, s)
+	}
+}
@@ -0,0 +1,258 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+)
+
+// Ограничение частоты запросов. Счетчики хранятся в памяти процесса,
+// поэтому при нескольких экземплярах сервера лимиты действуют в каждом отдельно.
+
+// loginBodyLimit - сколько байт тела запроса входа читается, чтобы найти логин.
+const loginBodyLimit = 1 << 16
+
+// RateLimit - не больше Requests запросов за Period с одного адреса.
+// Нулевой Requests снимает ограничение.
+type RateLimit struct {
+	Requests int           `env:"REQUESTS"`
+	Period   time.Duration `env:"PERIOD"`
+}
+
+// LoginLockout - после Attempts неудачных попыток входа в один логин или IPAttempts
+// с одного адреса вход блокируется на Base, каждая следующая неудача удваивает блокировку до Max.
+// Нулевые Attempts и IPAttempts отключают блокировку по логину и по адресу.
+type LoginLockout struct {
+	Attempts   int           `env:"ATTEMPTS"`
+	IPAttempts int           `env:"IP_ATTEMPTS"`
+	Base       time.Duration `env:"BASE"`
+	Max        time.Duration `env:"MAX"`
+}
+
+// rateLimiter - счетчики запросов по ключу в окнах фиксированной длины.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   RateLimit
+	windows map[string]*rateWindow
+	sweep   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		windows: map[string]*rateWindow{},
+	}
+}
+
+// allow учитывает запрос с ключом key. Если лимит исчерпан,
+// возвращает false и время до начала следующего окна.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.sweep) >= l.limit.Period {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.limit.Period {
+				delete(l.windows, k)
+			}
+		}
+		l.sweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.limit.Period {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit.Requests {
+		return false, w.start.Add(l.limit.Period).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
+
+// lockout - неудачные попытки входа по ключу с растущей блокировкой.
+type lockout struct {
+	mu       sync.Mutex
+	attempts int
+	base     time.Duration
+	max      time.Duration
+	failures map[string]*loginFailures
+	sweep    time.Time
+}
+
+type loginFailures struct {
+	count int
+	last  time.Time
+	until time.Time
+}
+
+func newLockout(attempts int, base, max time.Duration) *lockout {
+	return &lockout{
+		attempts: attempts,
+		base:     base,
+		max:      max,
+		failures: map[string]*loginFailures{},
+	}
+}
+
+// locked возвращает, сколько еще заблокирован ключ key.
+func (l *lockout) locked(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f, ok := l.failures[key]; ok && f.until.After(now) {
+		return f.until.Sub(now)
+	}
+	return 0
+}
+
+// fail учитывает неудачную попытку. Неудачи старше max забываются.
+func (l *lockout) fail(key string, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.sweep) >= l.max {
+		for k, f := range l.failures {
+			if now.Sub(f.last) >= l.max && !f.until.After(now) {
+				delete(l.failures, k)
+			}
+		}
+		l.sweep = now
+	}
+
+	f, ok := l.failures[key]
+	if !ok || now.Sub(f.last) >= l.max {
+		f = &loginFailures{}
+		l.failures[key] = f
+	}
+	f.count++
+	f.last = now
+	if f.count < l.attempts {
+		return
+	}
+	d := l.max
+	if n := f.count - l.attempts; n < 32 && l.base<<n < l.max {
+		d = l.base << n
+	}
+	f.until = now.Add(d)
+}
+
+// reset забывает неудачные попытки ключа.
+func (l *lockout) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
+
+// middlewareRateLimit ограничивает частоту запросов с одного адреса.
+// Каждый вызов заводит свои счетчики, поэтому лимит действует на маршрут или группу, где он подключен.
+func (s *Server) middlewareRateLimit(limit RateLimit) gin.HandlerFunc {
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	limiter := newRateLimiter(limit)
+	return func(c *gin.Context) {
+		if ok, retry := limiter.allow(c.ClientIP(), time.Now()); !ok {
+			s.abortTooManyRequests(c, retry, apperr.ErrTooManyRequests)
+			return
+		}
+		c.Next()
+	}
+}
+
+// middlewareLoginLockout блокирует вход после неудачных попыток по логину из тела запроса и по адресу.
+// Успешный вход сбрасывает счетчик логина, счетчик адреса забывается со временем.
+func (s *Server) middlewareLoginLockout() gin.HandlerFunc {
+	cfg := s.loginLockout
+	var logins, ips *lockout
+	if cfg.Attempts > 0 && cfg.Base > 0 {
+		logins = newLockout(cfg.Attempts, cfg.Base, max(cfg.Max, cfg.Base))
+	}
+	if cfg.IPAttempts > 0 && cfg.Base > 0 {
+		ips = newLockout(cfg.IPAttempts, cfg.Base, max(cfg.Max, cfg.Base))
+	}
+	return func(c *gin.Context) {
+		if logins == nil && ips == nil {
+			c.Next()
+			return
+		}
+		login, ip := "", c.ClientIP()
+		if logins != nil {
+			login = s.peekLogin(c)
+		}
+
+		now := time.Now()
+		var retry time.Duration
+		if ips != nil {
+			retry = ips.locked(ip, now)
+		}
+		if logins != nil && login != "" {
+			retry = max(retry, logins.locked(login, now))
+		}
+		if retry > 0 {
+			s.abortTooManyRequests(c, retry, apperr.ErrLoginLocked)
+			return
+		}
+
+		c.Next()
+
+		failed := false
+		for _, err := range c.Errors {
+			if errors.Is(err.Err, apperr.ErrInvalidCredentials) {
+				failed = true
+			}
+		}
+		switch {
+		case failed:
+			now = time.Now()
+			if ips != nil {
+				ips.fail(ip, now)
+			}
+			if logins != nil && login != "" {
+				logins.fail(login, now)
+			}
+		case len(c.Errors) == 0 && logins != nil && login != "":
+			logins.reset(login)
+		}
+	}
+}
+
+// peekLogin возвращает логин из JSON тела запроса, тело остается доступным обработчику.
+func (s *Server) peekLogin(c *gin.Context) string {
+	bBody, err := io.ReadAll(io.LimitReader(c.Request.Body, loginBodyLimit))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(bBody), c.Request.Body))
+
+	body := struct {
+		Login string `json:"login"`
+	}{}
+	if err := json.Unmarshal(bBody, &body); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Login))
+}
+
+// abortTooManyRequests прерывает запрос с ошибкой err и заголовком Retry-After в секундах.
+func (s *Server) abortTooManyRequests(c *gin.Context, retry time.Duration, err error) {
+	seconds := int64((retry + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+	s.abortWithError(c, err, "")
+}
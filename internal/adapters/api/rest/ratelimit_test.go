@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(RateLimit{Requests: 2, Period: time.Minute})
+
+	tests := []struct {
+		name      string
+		key       string
+		at        time.Duration
+		wantOK    bool
+		wantRetry time.Duration
+	}{
+		{name: "first", key: "a", at: 0, wantOK: true},
+		{name: "second", key: "a", at: 10 * time.Second, wantOK: true},
+		{name: "over limit", key: "a", at: 20 * time.Second, wantOK: false, wantRetry: 40 * time.Second},
+		{name: "other key", key: "b", at: 20 * time.Second, wantOK: true},
+		{name: "next window", key: "a", at: time.Minute, wantOK: true},
+	}
+	for _, tt := range tests {
+		ok, retry := l.allow(tt.key, start.Add(tt.at))
+		if ok != tt.wantOK || retry != tt.wantRetry {
+			t.Errorf("%s: allow() = %v, %v, want %v, %v", tt.name, ok, retry, tt.wantOK, tt.wantRetry)
+		}
+	}
+}
+
+func TestLockout(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLockout(3, time.Minute, 5*time.Minute)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Minute},
+		{failures: 4, want: 2 * time.Minute},
+		{failures: 5, want: 4 * time.Minute},
+		{failures: 6, want: 5 * time.Minute},
+		{failures: 40, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		l.reset("login")
+		for range tt.failures {
+			l.fail("login", now)
+		}
+		if got := l.locked("login", now); got != tt.want {
+			t.Errorf("locked() after %d failures = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := l.locked("login", now.Add(5*time.Minute)); got != 0 {
+		t.Errorf("locked() after lockout = %v, want 0", got)
+	}
+	l.fail("login", now.Add(11*time.Minute))
+	if got := l.locked("login", now.Add(11*time.Minute)); got != 0 {
+		t.Errorf("locked() after old failures forgotten = %v, want 0", got)
+	}
+}
+
+func TestMiddlewareLoginLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Server{
+		log:          zap.NewNop(),
+		loginLockout: LoginLockout{Attempts: 2, IPAttempts: 10, Base: time.Minute, Max: time.Hour},
+	}
+	r := gin.New()
+	r.Use(s.middlewareError())
+	r.POST("/api/login", s.middlewareLoginLockout(), func(c *gin.Context) {
+		body, _ := s.readBody(c)
+		if !strings.Contains(string(body), `"password":"right"`) {
+			s.abortWithError(c, apperr.ErrInvalidCredentials, "")
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	login := func(login, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"login":"` + login + `","password":"` + password + `"}`
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body)))
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		login      string
+		password   string
+		wantStatus int
+	}{
+		{name: "right password", login: "alice", password: "right", wantStatus: http.StatusOK},
+		{name: "first failure", login: "alice", password: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "second failure", login: "Alice", password: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "locked", login: "alice", password: "right", wantStatus: http.StatusTooManyRequests},
+		{name: "other login", login: "bob", password: "right", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := login(tt.login, tt.password)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, rec.Code, tt.wantStatus)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "60" {
+			t.Errorf("%s: Retry-After = %q, want %q", tt.name, rec.Header().Get("Retry-After"), "60")
+		}
+	}
+}
@@ -3,11 +3,9 @@ package rest
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,16 +13,12 @@ import (
 
 	"github.com/mod-develop/backend/internal/adapters/types"
 	"github.com/mod-develop/backend/internal/models"
-	"github.com/mod-develop/backend/pkg/jwt"
 )
 
 var (
 	cookieName = "token"
-	cookieKey  = "UserID"
 
 	msgErrorCloseBody = "failed close body request"
-
-	errUnauthorize = errors.New("unauthorize")
 )
 
 // Константы сервиса.
@@ -45,46 +39,109 @@ type discipline interface {
 	Login(ctx context.Context, login, password string) (*models.User, error)
 	GetUserByID(ctx context.Context, userID uint) (*models.User, error)
 	GetPlayers(ctx context.Context, masterID uint) (*[]types.TQuestPlayer, error)
-	NewQuest(ctx context.Context, quest *types.TQuest, userID uint) (*models.Quest, error)
-	GetQuest(ctx context.Context, questID uint) (*types.TQuest, error)
-	GetQuests(ctx context.Context, userID uint) (*[]types.TQuest, error)
-	EditQuest(ctx context.Context, quest *types.TQuest, userID uint) (*types.TQuest, error)
-	GetAwaitQuests(ctx context.Context, userID uint) (*[]types.TQuestAwait, error)
-
-	ManageQuestConfirmation(ctx context.Context, statusID, userID uint, confirm bool) error
-
-	ManageCreateSelfMaster(ctx context.Context, userID uint) (*models.User, error)
+	NewQuest(ctx context.Context, quest *types.TQuest, masterID, userID uint) (*models.Quest, error)
+	GetQuest(ctx context.Context, questID, masterID uint) (*types.TQuest, error)
+	GetQuests(ctx context.Context, masterID uint) (*[]types.TQuest, error)
+	EditQuest(ctx context.Context, quest *types.TQuest, masterID uint) (*types.TQuest, error)
+	GetAwaitQuests(ctx context.Context, masterID uint) (*[]types.TQuestAwait, error)
+
+	ManageQuestConfirmation(ctx context.Context, statusID, masterID uint, confirm bool, reason string) error
+	GetQuestComments(ctx context.Context, statusID, userID uint) (*[]types.TQuestComment, error)
+	AddQuestComment(ctx context.Context, statusID, userID uint, text string) (*types.TQuestComment, error)
+
+	ManageCreateSelfMaster(ctx context.Context, userID uint, name string) (*models.UserMaster, error)
+	RenameMaster(ctx context.Context, masterID, userID uint, name string) error
+	SwitchMaster(ctx context.Context, userID, masterID uint) error
+	GetTeam(ctx context.Context, masterID, userID uint) (*types.TMasterTeam, error)
+	NewInvite(ctx context.Context, masterID, userID uint, role string) (*types.TMasterInvite, error)
+	RevokeInvite(ctx context.Context, masterID, userID, inviteID uint) error
+	SetMemberRole(ctx context.Context, masterID, userID, memberID uint, role string) error
+	RemoveMember(ctx context.Context, masterID, userID, memberID uint) error
+	GetInvite(ctx context.Context, code string) (*types.TMasterInvite, error)
+	AcceptInvite(ctx context.Context, code string, userID uint) error
 
 	AddMaster(ctx context.Context, masterCode string, playerID uint) error
 	GetQuestsPlayer(ctx context.Context, playerID uint) (*[]types.TPlayerQuest, error)
 	GetQuestPlayer(ctx context.Context, questID uint, playerID uint) (*types.TPlayerQuest, error)
-	SendQuestPlayer(ctx context.Context, questID, playerID uint) (*types.TPlayerQuest, error)
+	SendQuestPlayer(ctx context.Context, questID, playerID uint, proof *types.TProof) (*types.TPlayerQuest, error)
+	OpenProofAttachment(ctx context.Context, attachmentID, userID uint) (*types.TProofAttachment, io.ReadCloser, error)
 	GetPlayerMasters(ctx context.Context, playerID uint) (*[]models.UserMaster, error)
 	GetWallets(ctx context.Context, playerID uint) (*[]types.TPlayerWaller, error)
+	SetTimeZone(ctx context.Context, userID uint, timeZone string) error
+	ChangePassword(ctx context.Context, userID uint, current, password string) (*models.User, error)
+	GetSessions(ctx context.Context, userID uint, currentID string) (*[]types.TSession, error)
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
+
+	GetRoles(ctx context.Context) (*[]types.TRole, error)
+	GetUsersWithRoles(ctx context.Context, query string, page int) (*types.TUsersList, error)
+	SetUserRoles(ctx context.Context, actorID, userID uint, roles []string) error
+	GetAdminUser(ctx context.Context, userID uint) (*types.TAdminUser, error)
+	LockUser(ctx context.Context, actorID, userID uint) error
+	UnlockUser(ctx context.Context, userID uint) error
+	ResetPassword(ctx context.Context, userID uint) (string, error)
+	GetHealth(ctx context.Context) (*types.THealth, error)
+
+	NewReward(ctx context.Context, reward *types.TReward, masterID uint) (*models.Reward, error)
+	GetReward(ctx context.Context, rewardID, masterID uint) (*types.TReward, error)
+	GetRewards(ctx context.Context, masterID uint) (*[]types.TReward, error)
+	EditReward(ctx context.Context, reward *types.TReward, masterID uint) (*types.TReward, error)
+	GetRewardPurchases(ctx context.Context, masterID uint) (*[]types.TRewardPurchase, error)
+	ManageRewardPurchase(ctx context.Context, purchaseID, masterID uint, fulfil bool) error
+	GetPlayerRewards(ctx context.Context, playerID uint) (*[]types.TPlayerReward, error)
+	GetPlayerPurchases(ctx context.Context, playerID uint) (*[]types.TRewardPurchase, error)
+	BuyReward(ctx context.Context, rewardID, playerID uint) error
+
+	GetMasterWallets(ctx context.Context, masterID uint) (*[]types.TPlayerWaller, error)
+	GetMasterWalletHistory(ctx context.Context, walletID, masterID uint, page int) (*types.TWalletHistory, error)
+	GetPlayerWalletHistory(ctx context.Context, playerID uint, page int) (*types.TWalletHistory, error)
+	AdjustWallet(ctx context.Context, masterID, playerID, authorID uint, amount int, comment string) error
+	ReverseWalletTransaction(ctx context.Context, transactionID, masterID, authorID uint) error
 }
 
 type userInterface interface {
 	Error500Page(wr http.ResponseWriter) error
 	Error403Page(wr http.ResponseWriter) error
 	Error404Page(wr http.ResponseWriter) error
+	ErrorPage(wr http.ResponseWriter, page *types.TErrorPage) error
 	RegistrationPage(wr http.ResponseWriter) error
 	AuthorizationPage(wr http.ResponseWriter) error
 	MainPage(wr http.ResponseWriter, user *types.TMainPage) error
 	ProfilePage(wr http.ResponseWriter, page *types.TProfilePage) error
+	PasswordPage(wr http.ResponseWriter, page *types.TPasswordPage) error
 	QuestGiverPage(wr http.ResponseWriter, page *types.TQuestGiverPage) error
 	QuestEdit(wr http.ResponseWriter, page *types.TQuestEdit) error
 	QuestNew(wr http.ResponseWriter, page *types.TQuestNew) error
 	QuestAwait(wr http.ResponseWriter, page *types.TQuestAwaitPage) error
+	Rewards(wr http.ResponseWriter, page *types.TRewardsPage) error
+	RewardEdit(wr http.ResponseWriter, page *types.TRewardEdit) error
+	RewardNew(wr http.ResponseWriter, page *types.TRewardNew) error
+	RewardPurchases(wr http.ResponseWriter, page *types.TRewardPurchasesPage) error
+	Wallets(wr http.ResponseWriter, page *types.TWalletsPage) error
+	WalletHistory(wr http.ResponseWriter, page *types.TWalletHistoryPage) error
+	Team(wr http.ResponseWriter, page *types.TTeamPage) error
+	Masters(wr http.ResponseWriter, page *types.TMastersPage) error
+	Invite(wr http.ResponseWriter, page *types.TInvitePage) error
 
 	PlayerProfile(wr http.ResponseWriter, page *types.TPlayerProfilePage) error
 	PlayerQuests(wr http.ResponseWriter, page *types.TPlayerQuestsPage) error
 	PlayerQuest(wr http.ResponseWriter, page *types.TPlayerQuestPage) error
 	PlayerSettings(wr http.ResponseWriter, page *types.TPlayerSettingsPage) error
+	PlayerSessions(wr http.ResponseWriter, page *types.TSessionsPage) error
+	PlayerRewards(wr http.ResponseWriter, page *types.TPlayerRewardsPage) error
+	PlayerWallet(wr http.ResponseWriter, page *types.TWalletHistoryPage) error
+
+	AdminUsers(wr http.ResponseWriter, page *types.TAdminUsersPage) error
+	AdminUser(wr http.ResponseWriter, page *types.TAdminUserPage) error
+	AdminHealth(wr http.ResponseWriter, page *types.TAdminHealthPage) error
 }
 
-type sess interface {
-	Middleware() gin.HandlerFunc
-	SaveUser(c *gin.Context, user *models.User) error
+// authenticator - подсистема аутентификации: сессии пользователей и их токены.
+type authenticator interface {
+	Authenticate(c *gin.Context) (*types.TUser, error)
+	SignIn(c *gin.Context, user *models.User) (*types.TAuthTokens, error)
+	Refresh(c *gin.Context, refreshToken string) (*types.TAuthTokens, error)
+	SignOut(c *gin.Context, refreshToken string) error
+	SignOutAll(c *gin.Context, userID uint) error
 	GetUser(c *gin.Context) (*types.TUser, error)
 }
 
@@ -92,12 +149,16 @@ type sess interface {
 type Server struct {
 	log       *zap.Logger
 	disc      discipline
-	sess      sess
+	auth      authenticator
 	ui        userInterface
 	baseURL   string
-	secretKey []byte
 	s         http.Server
 	tlsEnable bool
+
+	trustedProxies []string
+	rateLimit      RateLimit
+	authRateLimit  RateLimit
+	loginLockout   LoginLockout
 }
 
 // Option - опции сервера.
@@ -124,28 +185,43 @@ func SetLogger(log *zap.Logger) func(s *Server) {
 	}
 }
 
-// SecretKey - задает секретный ключ.
-func SetSecretKey(secret []byte) Option {
+// HTTPSEnable - включает https.
+func HTTPSEnable(enable bool) Option {
 	return func(s *Server) {
-		s.secretKey = secret
+		s.tlsEnable = enable
 	}
 }
 
-// HTTPSEnable - включает https.
-func HTTPSEnable(enable bool) Option {
+// SetTrustedProxies - задает прокси, которым сервер верит в X-Forwarded-For.
+// Без них адресом клиента считается адрес соединения.
+func SetTrustedProxies(proxies []string) Option {
 	return func(s *Server) {
-		s.tlsEnable = enable
+		s.trustedProxies = proxies
+	}
+}
+
+// SetRateLimit - задает лимиты запросов к API и к маршрутам аутентификации.
+func SetRateLimit(api, auth RateLimit) Option {
+	return func(s *Server) {
+		s.rateLimit = api
+		s.authRateLimit = auth
+	}
+}
+
+// SetLoginLockout - задает блокировку входа после неудачных попыток.
+func SetLoginLockout(lockout LoginLockout) Option {
+	return func(s *Server) {
+		s.loginLockout = lockout
 	}
 }
 
 // New создает Server.
-func New(disc discipline, ui userInterface, sess sess, options ...Option) *Server {
+func New(disc discipline, ui userInterface, auth authenticator, options ...Option) *Server {
 	srv := &Server{
-		disc:      disc,
-		ui:        ui,
-		sess:      sess,
-		log:       zap.NewNop(),
-		secretKey: []byte("rest_secret_key"),
+		disc: disc,
+		ui:   ui,
+		auth: auth,
+		log:  zap.NewNop(),
 	}
 	srv.s.Addr = "localhost:8080"
 
@@ -159,11 +235,25 @@ func New(disc discipline, ui userInterface, sess sess, options ...Option) *Serve
 // SetupRouter - создает маршруты.
 func (s *Server) SetupRouter() *gin.Engine {
 	r := gin.New()
+	if err := r.SetTrustedProxies(s.trustedProxies); err != nil {
+		s.log.Error("failed set trusted proxies", zap.Error(err))
+	}
 	r.Use(
 		s.Logger(),
-		s.sess.Middleware(),
 	)
-	r.Use(s.middlewareErrorPage())
+	r.Use(s.middlewareError())
+
+	// общий счетчик на все API и отдельный на аутентификацию, блокировка входа общая для v0 и v1
+	apiLimit := s.middlewareRateLimit(s.rateLimit)
+	authLimit := s.middlewareRateLimit(s.authRateLimit)
+	loginLockout := s.middlewareLoginLockout()
+
+	createQuest := s.RequirePermission(models.ActionCreateQuest)
+	confirmSubmission := s.RequirePermission(models.ActionConfirmSubmission)
+	managePlayers := s.RequirePermission(models.ActionManagePlayers)
+	manageRewards := s.RequirePermission(models.ActionManageRewards)
+	manageWallets := s.RequirePermission(models.ActionManageWallets)
+	adminUsers := s.RequirePermission(models.ActionAdminUsers)
 
 	r.GET("/registration", s.handlerRegistrationPage)
 	r.GET("/authorization", s.handlerAuthorization)
@@ -173,17 +263,58 @@ func (s *Server) SetupRouter() *gin.Engine {
 		auth.Use(s.middlewareAuthentication())
 		auth.GET("/", s.handlerPlayer)
 		auth.GET("/logout", s.handleUserLogout)
+		auth.POST("/logout/all", s.handleUserLogoutAll)
 		auth.GET("/profile", s.handlerUserProfile)
+		auth.GET("/profile/password", s.handlerUserPassword)
+		auth.POST("/profile/password", s.handlerUserPassword)
+		auth.GET("/proofs/:id", s.handlerProofAttachment)
+		auth.GET("/invite/:code", s.handlerInvite)
+		auth.POST("/invite/:code", s.handlerInvite)
 
 		manage := auth.Group("/manager")
-		manage.Use(s.middlewareManagerRole())
 		{
-			manage.GET("/", s.handlerQuestGiverPage)
-			manage.GET("/quests/:id", s.handlerQuestEdit)
-			manage.POST("/quests/:id", s.handlerQuestEdit)
-			manage.GET("/quests/new", s.handlerQuestNew)
-			manage.POST("/quests/new", s.handlerQuestNew)
-			manage.GET("/quests/await", s.handlerQuestAwait)
+			quests := manage.Group("", createQuest)
+			{
+				quests.GET("/", s.handlerQuestGiverPage)
+				quests.GET("/quests/:id", s.handlerQuestEdit)
+				quests.POST("/quests/:id", s.handlerQuestEdit)
+				quests.GET("/quests/new", s.handlerQuestNew)
+				quests.POST("/quests/new", s.handlerQuestNew)
+			}
+			manage.GET("/quests/await", confirmSubmission, s.handlerQuestAwait)
+			manage.GET("/masters", s.handlerMasters)
+			manage.POST("/masters", s.handlerMasterNew)
+			manage.POST("/masters/:id/activate", s.handlerMasterSwitch)
+			manage.POST("/masters/:id/rename", s.handlerMasterRename)
+			manage.GET("/members", s.handlerTeam)
+			manage.POST("/members/:id/role", s.handlerTeamMemberRole)
+			manage.POST("/members/:id/remove", s.handlerTeamMemberRemove)
+			manage.POST("/invites", s.handlerTeamInviteNew)
+			manage.POST("/invites/:id/revoke", s.handlerTeamInviteRevoke)
+			rewards := manage.Group("/rewards", manageRewards)
+			{
+				rewards.GET("", s.handlerRewards)
+				rewards.GET("/:id", s.handlerRewardEdit)
+				rewards.POST("/:id", s.handlerRewardEdit)
+				rewards.GET("/new", s.handlerRewardNew)
+				rewards.POST("/new", s.handlerRewardNew)
+				rewards.GET("/purchases", s.handlerRewardPurchases)
+			}
+			wallets := manage.Group("/wallets", manageWallets)
+			{
+				wallets.GET("", s.handlerWallets)
+				wallets.GET("/:id", s.handlerWalletHistory)
+			}
+		}
+		admin := auth.Group("/admin", adminUsers)
+		{
+			admin.GET("", s.handlerAdminUsers)
+			admin.GET("/users/:id", s.handlerAdminUser)
+			admin.POST("/users/:id/roles", s.handlerAdminUserRoles)
+			admin.POST("/users/:id/lock", s.handlerAdminUserLock)
+			admin.POST("/users/:id/unlock", s.handlerAdminUserUnlock)
+			admin.POST("/users/:id/password", s.handlerAdminUserPassword)
+			admin.GET("/health", s.handlerAdminHealth)
 		}
 		player := auth.Group("/player")
 		{
@@ -192,34 +323,138 @@ func (s *Server) SetupRouter() *gin.Engine {
 			player.GET("/quests/:id", s.handlerPlayerQuest)
 			player.POST("/quests/:id", s.handlerPlayerQuest)
 			player.GET("/settings", s.handlerPlayerSettings)
+			player.GET("/sessions", s.handlerUserSessions)
+			player.POST("/sessions/:id/revoke", s.handlerUserSessionRevoke)
+			player.GET("/rewards", s.handlerPlayerRewards)
+			player.POST("/rewards", s.handlerPlayerRewards)
+			player.GET("/wallet", s.handlerPlayerWallet)
 		}
 	}
 
 	apiUser := r.Group("/api/v0/user")
-	apiUser.Use(s.middlewareAuthenticationAPI())
+	apiUser.Use(apiLimit, s.middlewareAuthentication())
 	{
 		apiUser.GET("/info", s.handlerAPIUserInfo)
+		apiUser.GET("/wallet/transactions", s.handlerAPIPlayerWalletTransactions)
+		apiUser.GET("/quests/status/:id/comments", s.handlerAPIQuestComments)
+		apiUser.POST("/quests/status/:id/comments", s.handlerAPIQuestCommentAdd)
 	}
 
 	api := r.Group("/api/v0")
+	api.Use(apiLimit)
 	{
 		apiAuth := api.Group("/auth")
+		apiAuth.Use(authLimit)
 		{
 			apiAuth.POST("/registration", s.handlerApiRegistration)
-			apiAuth.POST("/authorization", s.handlerAPIAuthorization)
+			apiAuth.POST("/authorization", loginLockout, s.handlerAPIAuthorization)
 		}
 		apiManage := api.Group("/manage")
-		apiManage.Use(s.middlewareManagerRole())
+		apiManage.Use(s.middlewareAuthentication())
 		{
-			apiManage.POST("/quests/status/confirmation", s.handlerAPIManageQuestConfirmation)
+			apiManage.POST("/quests/status/confirmation", confirmSubmission, s.handlerAPIManageQuestConfirmation)
+			apiManage.POST("/rewards/purchases/status", manageRewards, s.handlerAPIManageRewardPurchase)
+			apiManage.GET("/wallets/:id/transactions", manageWallets, s.handlerAPIManageWalletTransactions)
+			apiManage.POST("/wallets/adjust", manageWallets, s.handlerAPIManageWalletAdjust)
+			apiManage.POST("/wallets/transactions/reverse", manageWallets, s.handlerAPIManageWalletReverse)
 		}
 		apiUser := api.Group("/user")
+		apiUser.Use(s.middlewareAuthentication())
 		{
 			apiUser.POST("/settings/self/master", s.handlerAPIManageCreateMaster)
 			apiUser.POST("/settings/player/master", s.handlerAPIPlayerAddMaster)
+			apiUser.POST("/settings/timezone", s.handlerAPIUserTimeZone)
+		}
+	}
+
+	v1 := r.Group(apiV1Path)
+	v1.Use(apiLimit)
+	{
+		v1Auth := v1.Group("/auth")
+		v1Auth.Use(authLimit)
+		{
+			v1Auth.POST("/registration", s.handlerV1Registration)
+			v1Auth.POST("/login", loginLockout, s.handlerV1Login)
+			v1Auth.POST("/refresh", s.handlerV1Refresh)
+			v1Auth.POST("/logout", s.handlerV1Logout)
+		}
+
+		v1User := v1.Group("/")
+		v1User.Use(s.middlewareAuthentication())
+		{
+			v1User.POST("/auth/logout-all", s.handlerV1LogoutAll)
+			v1User.GET("/user", s.handlerV1User)
+			v1User.PUT("/user/timezone", s.handlerV1UserTimeZone)
+			v1User.PUT("/user/password", s.handlerV1UserPassword)
+			v1User.GET("/user/sessions", s.handlerV1UserSessions)
+			v1User.DELETE("/user/sessions/:id", s.handlerV1UserSessionRevoke)
+			v1User.POST("/user/master", s.handlerV1UserCreateMaster)
+			v1User.GET("/user/masters", s.handlerV1UserMasters)
+			v1User.POST("/user/masters/:id/activate", s.handlerV1UserMasterSwitch)
+			v1User.POST("/user/invites", s.handlerV1UserInviteAccept)
+			v1User.GET("/submissions/:id/comments", s.handlerV1SubmissionComments)
+			v1User.POST("/submissions/:id/comments", s.handlerV1SubmissionCommentAdd)
+			v1User.GET("/proofs/:id", s.handlerV1Proof)
+
+			v1Player := v1User.Group("/player")
+			{
+				v1Player.GET("/quests", s.handlerV1PlayerQuests)
+				v1Player.GET("/quests/:id", s.handlerV1PlayerQuest)
+				v1Player.POST("/quests/:id/submissions", s.handlerV1PlayerQuestSend)
+				v1Player.GET("/masters", s.handlerV1PlayerMasters)
+				v1Player.POST("/masters", s.handlerV1PlayerMasterAdd)
+				v1Player.GET("/wallets", s.handlerV1PlayerWallets)
+				v1Player.GET("/wallet/transactions", s.handlerV1PlayerWalletTransactions)
+				v1Player.GET("/rewards", s.handlerV1PlayerRewards)
+				v1Player.POST("/rewards/:id/buy", s.handlerV1PlayerRewardBuy)
+				v1Player.GET("/purchases", s.handlerV1PlayerPurchases)
+			}
+
+			v1Manage := v1User.Group("/manage")
+			{
+				v1Manage.GET("/players", managePlayers, s.handlerV1ManagePlayers)
+				v1Manage.GET("/quests", createQuest, s.handlerV1ManageQuests)
+				v1Manage.POST("/quests", createQuest, s.handlerV1ManageQuestNew)
+				v1Manage.GET("/quests/:id", createQuest, s.handlerV1ManageQuest)
+				v1Manage.PUT("/quests/:id", createQuest, s.handlerV1ManageQuestEdit)
+				v1Manage.GET("/submissions", confirmSubmission, s.handlerV1ManageSubmissions)
+				v1Manage.POST("/submissions/:id/confirm", confirmSubmission, s.handlerV1ManageSubmissionConfirm)
+				v1Manage.POST("/submissions/:id/reject", confirmSubmission, s.handlerV1ManageSubmissionReject)
+				v1Manage.GET("/rewards", manageRewards, s.handlerV1ManageRewards)
+				v1Manage.POST("/rewards", manageRewards, s.handlerV1ManageRewardNew)
+				v1Manage.GET("/rewards/:id", manageRewards, s.handlerV1ManageReward)
+				v1Manage.PUT("/rewards/:id", manageRewards, s.handlerV1ManageRewardEdit)
+				v1Manage.GET("/purchases", manageRewards, s.handlerV1ManagePurchases)
+				v1Manage.POST("/purchases/:id/fulfil", manageRewards, s.handlerV1ManagePurchaseFulfil)
+				v1Manage.POST("/purchases/:id/refund", manageRewards, s.handlerV1ManagePurchaseRefund)
+				v1Manage.GET("/wallets", manageWallets, s.handlerV1ManageWallets)
+				v1Manage.GET("/wallets/:id/transactions", manageWallets, s.handlerV1ManageWalletTransactions)
+				v1Manage.POST("/wallets/adjustments", manageWallets, s.handlerV1ManageWalletAdjust)
+				v1Manage.POST("/transactions/:id/reverse", manageWallets, s.handlerV1ManageTransactionReverse)
+				v1Manage.PUT("/master", s.handlerV1ManageMasterRename)
+				v1Manage.GET("/members", s.handlerV1ManageMembers)
+				v1Manage.PUT("/members/:id", s.handlerV1ManageMemberRole)
+				v1Manage.DELETE("/members/:id", s.handlerV1ManageMemberRemove)
+				v1Manage.POST("/invites", s.handlerV1ManageInviteNew)
+				v1Manage.DELETE("/invites/:id", s.handlerV1ManageInviteRevoke)
+			}
+
+			v1Admin := v1User.Group("/admin", adminUsers)
+			{
+				v1Admin.GET("/roles", s.handlerV1AdminRoles)
+				v1Admin.GET("/users", s.handlerV1AdminUsers)
+				v1Admin.GET("/users/:id", s.handlerV1AdminUser)
+				v1Admin.PUT("/users/:id/roles", s.handlerV1AdminUserRoles)
+				v1Admin.POST("/users/:id/lock", s.handlerV1AdminUserLock)
+				v1Admin.POST("/users/:id/unlock", s.handlerV1AdminUserUnlock)
+				v1Admin.POST("/users/:id/password", s.handlerV1AdminUserPassword)
+				v1Admin.GET("/health", s.handlerV1AdminHealth)
+			}
 		}
 	}
 
+	s.setupDocs(r)
+
 	r.Static("/static", "static")
 
 	return r
@@ -252,45 +487,21 @@ func (s *Server) Stop() {
 	s.log.Info("Server exiting")
 }
 
-func unauthorize(c *gin.Context) {
-	userCookie := &http.Cookie{
-		Name:  cookieName,
-		Value: "",
-		Path:  "/",
-	}
-	c.Request.AddCookie(userCookie)
-	http.SetCookie(c.Writer, userCookie)
-}
-
-func (s *Server) authorization(c *gin.Context, login, password string) error {
+// authorization проверяет логин и пароль и выдает токены.
+func (s *Server) authorization(c *gin.Context, login, password string) (*types.TAuthTokens, error) {
 	var err error
 	var user *models.User
 	ctx := c.Request.Context()
 	if user, err = s.disc.Login(ctx, login, password); err != nil {
-		return fmt.Errorf("failed authorization: %w", err)
-	}
-
-	jwtRest := jwt.New([]byte(s.secretKey))
-	signedCookie, err := jwtRest.Create(cookieKey, strconv.Itoa(int(user.ID)))
-	if err != nil {
-		return fmt.Errorf("can't create cookie data: %w", err)
+		return nil, fmt.Errorf("failed authorization: %w", err)
 	}
 
-	userCookie := &http.Cookie{
-		Name:  cookieName,
-		Value: signedCookie,
-		Path:  "/",
-	}
-	c.Request.AddCookie(userCookie)
-	http.SetCookie(c.Writer, userCookie)
-
-	err = s.sess.SaveUser(c, user)
+	tokens, err := s.auth.SignIn(c, user)
 	if err != nil {
-		s.log.Error("failed save session", zap.Error(err))
-		return err
+		return nil, fmt.Errorf("failed sign in: %w", err)
 	}
 
-	return nil
+	return tokens, nil
 }
 
 func (s *Server) readBody(c *gin.Context) ([]byte, int) {
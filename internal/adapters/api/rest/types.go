@@ -20,8 +20,71 @@ const (
 type tRequestApiManageQuestConfirmation struct {
 	ID     uint               `json:"id"`
 	Action actionConfirmation `json:"action"`
+	Reason string             `json:"reason"`
+}
+
+type tRequestAPIQuestComment struct {
+	Text string `json:"text"`
+}
+
+type tResponseQuestComment struct {
+	ID       uint   `json:"id"`
+	Author   string `json:"author"`
+	Text     string `json:"text"`
+	Date     string `json:"date"`
+	IsMaster bool   `json:"isMaster"`
+}
+
+type tResponseQuestComments struct {
+	Status   bool                    `json:"status"`
+	Comments []tResponseQuestComment `json:"comments"`
 }
 
 type tRequestAPISettingsAddMaster struct {
 	Code string `json:"code"`
 }
+
+type tRequestAPISettingsTimeZone struct {
+	TimeZone string `json:"timeZone"`
+}
+
+type actionPurchase string
+
+const (
+	actionPurchaseFulfil actionPurchase = "fulfil"
+	actionPurchaseRefund actionPurchase = "refund"
+)
+
+type tRequestAPIManageRewardPurchase struct {
+	ID     uint           `json:"id"`
+	Action actionPurchase `json:"action"`
+}
+
+type tRequestAPIManageWalletAdjust struct {
+	PlayerID uint   `json:"playerID"`
+	Amount   int    `json:"amount"`
+	Comment  string `json:"comment"`
+}
+
+type tRequestAPIManageWalletReverse struct {
+	ID uint `json:"id"`
+}
+
+type tResponseWalletTransaction struct {
+	ID         uint   `json:"id"`
+	Type       string `json:"type"`
+	Amount     int    `json:"amount"`
+	Balance    int    `json:"balance"`
+	Comment    string `json:"comment"`
+	Date       string `json:"date"`
+	IsReversed bool   `json:"isReversed"`
+}
+
+type tResponseWalletHistory struct {
+	Status       bool                         `json:"status"`
+	Score        int                          `json:"score"`
+	Page         int                          `json:"page"`
+	Pages        int                          `json:"pages"`
+	Total        int64                        `json:"total"`
+	Transactions []tResponseWalletTransaction `json:"transactions"`
+}
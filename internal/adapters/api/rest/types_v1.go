@@ -0,0 +1,616 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// Запросы и ответы JSON API /api/v1. Ответ с ошибкой всегда имеет вид tResponseError.
+
+type tV1Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// tV1Tokens - токены доступа. ExpiresIn - время жизни токена доступа в секундах.
+type tV1Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type tV1Refresh struct {
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type tV1Login struct {
+	tV1User
+	tV1Tokens
+}
+
+// tV1Master - группа мастера, role - роль пользователя в группе, которой он управляет.
+// tV1Master - группа мастера, active - группа, которой пользователь управляет сейчас.
+type tV1Master struct {
+	ID     uint              `json:"id"`
+	Name   string            `json:"name"`
+	Code   string            `json:"code"`
+	Role   models.MemberRole `json:"role,omitempty"`
+	Active bool              `json:"active,omitempty"`
+}
+
+type tV1User struct {
+	ID             uint       `json:"id"`
+	Login          string     `json:"login"`
+	IsAdmin        bool       `json:"isAdmin"`
+	IsQuestMaster  bool       `json:"isQuestMaster"`
+	CanCreateQuest bool       `json:"canCreateQuest"`
+	Permissions    []string   `json:"permissions"`
+	Master         *tV1Master `json:"master,omitempty"`
+	// MustChangePassword - пароль сброшен админом, до смены через PUT /user/password
+	// остальные запросы отклоняются с кодом password_change_required
+	MustChangePassword bool `json:"mustChangePassword"`
+}
+
+// tV1Role - роль и действия, которые она разрешает.
+type tV1Role struct {
+	ID      uint     `json:"id"`
+	Name    string   `json:"name"`
+	Actions []string `json:"actions"`
+}
+
+type tV1UserRoles struct {
+	ID        uint      `json:"id"`
+	Login     string    `json:"login"`
+	IsMaster  bool      `json:"isMaster"`
+	Locked    bool      `json:"locked"`
+	CreatedAt time.Time `json:"createdAt"`
+	Roles     []string  `json:"roles"`
+}
+
+// tV1UsersPage - страница пользователей админки.
+type tV1UsersPage struct {
+	Page  int            `json:"page"`
+	Pages int            `json:"pages"`
+	Total int64          `json:"total"`
+	Users []tV1UserRoles `json:"users"`
+}
+
+type tV1Roles struct {
+	Roles []string `json:"roles"`
+}
+
+// tV1AdminUser - пользователь в админке, мастеру добавляются его группы.
+type tV1AdminUser struct {
+	tV1UserRoles
+	Masters []tV1AdminMaster `json:"masters"`
+}
+
+// tV1AdminMaster - группа мастера в админке с игроками и квестами.
+type tV1AdminMaster struct {
+	ID      uint        `json:"id"`
+	Name    string      `json:"name"`
+	Code    string      `json:"code"`
+	Players []tV1Player `json:"players"`
+	Quests  []tV1Quest  `json:"quests"`
+}
+
+// tV1Password - временный пароль после сброса, возвращается один раз.
+type tV1PasswordChange struct {
+	CurrentPassword string `json:"currentPassword"`
+	Password        string `json:"password"`
+}
+
+type tV1Password struct {
+	Password string `json:"password"`
+}
+
+type tV1Health struct {
+	Users        int        `json:"users"`
+	LockedUsers  int        `json:"lockedUsers"`
+	Masters      int        `json:"masters"`
+	UnpaidQuests int        `json:"unpaidQuests"`
+	OldestUnpaid *time.Time `json:"oldestUnpaid,omitempty"`
+}
+
+type tV1TimeZone struct {
+	TimeZone string `json:"timeZone"`
+}
+
+// tV1Session - сессия пользователя на устройстве, current - сессия текущего запроса.
+type tV1Session struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
+}
+
+type tV1MasterCode struct {
+	Code string `json:"code"`
+}
+
+type tV1MasterName struct {
+	Name string `json:"name"`
+}
+
+type tV1InviteCode struct {
+	Code string `json:"code"`
+}
+
+type tV1MemberRole struct {
+	Role string `json:"role"`
+}
+
+type tV1Member struct {
+	UserID uint              `json:"userId"`
+	Login  string            `json:"login"`
+	Role   models.MemberRole `json:"role"`
+}
+
+// tV1Invite - приглашение в группу мастера, код принимается в POST /api/v1/user/invites.
+type tV1Invite struct {
+	ID        uint              `json:"id"`
+	Code      string            `json:"code"`
+	Role      models.MemberRole `json:"role"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// tV1Team - участники группы мастера, приглашения видит только владелец.
+type tV1Team struct {
+	Members []tV1Member `json:"members"`
+	Invites []tV1Invite `json:"invites"`
+}
+
+type tV1Player struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type tV1StreakBonus struct {
+	Days  uint `json:"days"`
+	Bonus uint `json:"bonus"`
+}
+
+// tV1QuestRequest - создание и изменение квеста. Даты в формате 2006-01-02T15:04,
+// TimeZoneOffset - смещение в минутах, как его отдает Date.getTimezoneOffset().
+type tV1QuestRequest struct {
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	Type           models.QuestType `json:"type"`
+	IsActive       bool             `json:"isActive"`
+	Price          uint             `json:"price"`
+	Penalty        uint             `json:"penalty"`
+	AllPlayers     bool             `json:"allPlayers"`
+	Players        []uint           `json:"players"`
+	DateStart      string           `json:"dateStart"`
+	DateEnd        string           `json:"dateEnd"`
+	TimeZoneOffset int              `json:"timeZoneOffset"`
+	WeekDays       []int            `json:"weekDays"`
+	MonthDays      string           `json:"monthDays"`
+	Times          uint             `json:"times"`
+	StreakBonuses  []tV1StreakBonus `json:"streakBonuses"`
+}
+
+type tV1Quest struct {
+	ID            uint             `json:"id"`
+	Title         string           `json:"title"`
+	Description   string           `json:"description"`
+	Type          models.QuestType `json:"type"`
+	IsActive      bool             `json:"isActive"`
+	Price         uint             `json:"price"`
+	Penalty       uint             `json:"penalty"`
+	AllPlayers    bool             `json:"allPlayers"`
+	Players       []tV1Player      `json:"players"`
+	DateStart     string           `json:"dateStart"`
+	DateEnd       string           `json:"dateEnd"`
+	WeekDays      []int            `json:"weekDays"`
+	MonthDays     string           `json:"monthDays"`
+	Times         uint             `json:"times"`
+	Schedule      string           `json:"schedule"`
+	StreakBonuses []tV1StreakBonus `json:"streakBonuses"`
+}
+
+type tV1Attachment struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+type tV1Submission struct {
+	ID           uint                    `json:"id"`
+	Title        string                  `json:"title"`
+	Description  string                  `json:"description"`
+	PlayerName   string                  `json:"playerName"`
+	Price        uint                    `json:"price"`
+	Period       string                  `json:"period"`
+	ProofComment string                  `json:"proofComment"`
+	Attachments  []tV1Attachment         `json:"attachments"`
+	Comments     []tResponseQuestComment `json:"comments"`
+}
+
+type tV1Reject struct {
+	Reason string `json:"reason"`
+}
+
+type tV1Comment struct {
+	Text string `json:"text"`
+}
+
+// Состояние квеста игрока в текущем периоде.
+const (
+	v1QuestStatusNew       = "new"
+	v1QuestStatusSent      = "sent"
+	v1QuestStatusRejected  = "rejected"
+	v1QuestStatusConfirmed = "confirmed"
+)
+
+type tV1PlayerQuest struct {
+	ID            uint                    `json:"id"`
+	Title         string                  `json:"title"`
+	Description   string                  `json:"description"`
+	Price         uint                    `json:"price"`
+	Penalty       uint                    `json:"penalty"`
+	Status        string                  `json:"status"`
+	IsRecurring   bool                    `json:"isRecurring"`
+	Period        string                  `json:"period"`
+	Schedule      string                  `json:"schedule"`
+	IsScheduled   bool                    `json:"isScheduled"`
+	Done          int                     `json:"done"`
+	Total         int                     `json:"total"`
+	StreakCurrent uint                    `json:"streakCurrent"`
+	StreakBest    uint                    `json:"streakBest"`
+	StreakBonuses []tV1StreakBonus        `json:"streakBonuses"`
+	SubmissionID  uint                    `json:"submissionID,omitempty"`
+	ProofComment  string                  `json:"proofComment"`
+	Attachments   []tV1Attachment         `json:"attachments"`
+	RejectReason  string                  `json:"rejectReason"`
+	Comments      []tResponseQuestComment `json:"comments"`
+}
+
+type tV1Wallet struct {
+	ID         uint   `json:"id"`
+	Score      int    `json:"score"`
+	PlayerID   uint   `json:"playerID"`
+	PlayerName string `json:"playerName"`
+	MasterID   uint   `json:"masterID"`
+}
+
+type tV1RewardRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Cost        uint   `json:"cost"`
+	Stock       uint   `json:"stock"`
+	IsUnlimited bool   `json:"isUnlimited"`
+	IsActive    bool   `json:"isActive"`
+}
+
+type tV1Reward struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Cost        uint   `json:"cost"`
+	Stock       uint   `json:"stock"`
+	IsUnlimited bool   `json:"isUnlimited"`
+	IsActive    bool   `json:"isActive"`
+}
+
+type tV1PlayerReward struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Cost        uint   `json:"cost"`
+	Stock       uint   `json:"stock"`
+	IsUnlimited bool   `json:"isUnlimited"`
+	Score       int    `json:"score"`
+	CanBuy      bool   `json:"canBuy"`
+}
+
+type tV1Purchase struct {
+	ID         uint                        `json:"id"`
+	Title      string                      `json:"title"`
+	PlayerName string                      `json:"playerName"`
+	Cost       uint                        `json:"cost"`
+	Status     models.RewardPurchaseStatus `json:"status"`
+	Date       string                      `json:"date"`
+}
+
+type tV1WalletAdjust struct {
+	PlayerID uint   `json:"playerID"`
+	Amount   int    `json:"amount"`
+	Comment  string `json:"comment"`
+}
+
+func v1UserResponse(user *types.TUser) tV1User {
+	resp := tV1User{
+		ID:             user.ID,
+		Login:          user.Login,
+		IsAdmin:        user.IsAdmin,
+		IsQuestMaster:  user.IsQuestMaster,
+		CanCreateQuest: user.Action.IsQuestCreater,
+		Permissions:    []string{},
+
+		MustChangePassword: user.MustChangePassword,
+	}
+	for action, ok := range user.Permissions {
+		if ok {
+			resp.Permissions = append(resp.Permissions, action)
+		}
+	}
+	slices.Sort(resp.Permissions)
+	if user.Master.ID != 0 {
+		master := v1MasterResponse(user.Master)
+		resp.Master = &master
+	}
+	return resp
+}
+
+func v1MasterResponse(master types.TMaster) tV1Master {
+	return tV1Master{ID: master.ID, Name: master.Name, Code: master.Code, Role: master.Role}
+}
+
+func v1TokensResponse(tokens *types.TAuthTokens) tV1Tokens {
+	return tV1Tokens{
+		AccessToken:  tokens.Access,
+		RefreshToken: tokens.Refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+	}
+}
+
+func v1StreakBonuses(bonuses []types.TStreakBonus) []tV1StreakBonus {
+	result := []tV1StreakBonus{}
+	for _, b := range bonuses {
+		result = append(result, tV1StreakBonus{Days: b.Days, Bonus: b.Bonus})
+	}
+	return result
+}
+
+// toQuest переводит запрос в представление квеста. players - игроки мастера,
+// из них отмечаются выбранные в запросе.
+func (r *tV1QuestRequest) toQuest(id uint, players []types.TQuestPlayer) *types.TQuest {
+	quest := &types.TQuest{
+		ID:             id,
+		Title:          r.Title,
+		Description:    r.Description,
+		Price:          r.Price,
+		Penalty:        r.Penalty,
+		IsActive:       r.IsActive,
+		IsAllPlayers:   r.AllPlayers,
+		Types:          slices.Clone(types.QuestTypes),
+		DateStart:      r.DateStart,
+		DateEnd:        r.DateEnd,
+		TimeZoneOffset: r.TimeZoneOffset,
+		WeekDays:       types.NewWeekDays(),
+		MonthDays:      r.MonthDays,
+		Times:          r.Times,
+		StreakBonuses:  []types.TStreakBonus{},
+	}
+	for i := range quest.Types {
+		quest.Types[i].Selected = quest.Types[i].Value == r.Type
+	}
+	for i := range quest.WeekDays {
+		quest.WeekDays[i].Selected = slices.Contains(r.WeekDays, quest.WeekDays[i].Value)
+	}
+	for _, p := range players {
+		p.Selected = r.AllPlayers || slices.Contains(r.Players, p.ID)
+		quest.Players = append(quest.Players, p)
+	}
+	// чужие игроки передаются дальше, чтобы проверка квеста вернула ошибку поля
+	for _, id := range r.Players {
+		if !slices.ContainsFunc(players, func(p types.TQuestPlayer) bool { return p.ID == id }) {
+			quest.Players = append(quest.Players, types.TQuestPlayer{ID: id, Selected: true})
+		}
+	}
+	for _, b := range r.StreakBonuses {
+		if b.Days == 0 || b.Bonus == 0 {
+			continue
+		}
+		quest.StreakBonuses = append(quest.StreakBonuses, types.TStreakBonus{Days: b.Days, Bonus: b.Bonus})
+	}
+	return quest
+}
+
+func v1QuestResponse(quest *types.TQuest) tV1Quest {
+	resp := tV1Quest{
+		ID:            quest.ID,
+		Title:         quest.Title,
+		Description:   quest.Description,
+		IsActive:      quest.IsActive,
+		Price:         quest.Price,
+		Penalty:       quest.Penalty,
+		AllPlayers:    quest.IsAllPlayers,
+		Players:       []tV1Player{},
+		DateStart:     quest.DateStart,
+		DateEnd:       quest.DateEnd,
+		WeekDays:      []int{},
+		MonthDays:     quest.MonthDays,
+		Times:         quest.Times,
+		Schedule:      quest.Schedule,
+		StreakBonuses: v1StreakBonuses(quest.StreakBonuses),
+	}
+	for _, t := range quest.Types {
+		if t.Selected {
+			resp.Type = t.Value
+		}
+	}
+	for _, p := range quest.Players {
+		if p.Selected {
+			resp.Players = append(resp.Players, tV1Player{ID: p.ID, Name: p.Name})
+		}
+	}
+	for _, d := range quest.WeekDays {
+		if d.Selected {
+			resp.WeekDays = append(resp.WeekDays, d.Value)
+		}
+	}
+	return resp
+}
+
+func v1Attachments(attachments []types.TProofAttachment) []tV1Attachment {
+	result := []tV1Attachment{}
+	for _, a := range attachments {
+		result = append(result, tV1Attachment{
+			ID:          a.ID,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			URL:         fmt.Sprintf("%s/proofs/%d", apiV1Path, a.ID),
+		})
+	}
+	return result
+}
+
+func v1Comments(comments []types.TQuestComment) []tResponseQuestComment {
+	result := []tResponseQuestComment{}
+	for _, c := range comments {
+		result = append(result, commentResponse(&c))
+	}
+	return result
+}
+
+func v1SubmissionResponse(await *types.TQuestAwait) tV1Submission {
+	return tV1Submission{
+		ID:           await.ID,
+		Title:        await.Title,
+		Description:  await.Description,
+		PlayerName:   await.PlayerName,
+		Price:        await.Price,
+		Period:       await.Period,
+		ProofComment: await.ProofComment,
+		Attachments:  v1Attachments(await.Attachments),
+		Comments:     v1Comments(await.Comments),
+	}
+}
+
+func v1PlayerQuestResponse(quest *types.TPlayerQuest) tV1PlayerQuest {
+	resp := tV1PlayerQuest{
+		ID:            quest.ID,
+		Title:         quest.Title,
+		Description:   quest.Description,
+		Price:         quest.Price,
+		Penalty:       quest.Penalty,
+		Status:        v1QuestStatusNew,
+		IsRecurring:   quest.IsRecurring,
+		Period:        quest.Period,
+		Schedule:      quest.Schedule,
+		IsScheduled:   quest.IsScheduled,
+		Done:          quest.Done,
+		Total:         quest.Total,
+		StreakCurrent: quest.StreakCurrent,
+		StreakBest:    quest.StreakBest,
+		StreakBonuses: v1StreakBonuses(quest.StreakBonuses),
+		SubmissionID:  quest.StatusID,
+		ProofComment:  quest.ProofComment,
+		Attachments:   v1Attachments(quest.Attachments),
+		Comments:      v1Comments(quest.Comments),
+	}
+	switch {
+	case quest.IsConfirmed:
+		resp.Status = v1QuestStatusConfirmed
+	case quest.IsRejected:
+		resp.Status = v1QuestStatusRejected
+		resp.RejectReason = quest.RejectReason
+	case quest.IsSended:
+		resp.Status = v1QuestStatusSent
+	}
+	return resp
+}
+
+func v1WalletsResponse(wallets []types.TPlayerWaller) []tV1Wallet {
+	result := []tV1Wallet{}
+	for _, w := range wallets {
+		result = append(result, tV1Wallet{
+			ID:         w.ID,
+			Score:      w.Score,
+			PlayerID:   w.PlayerID,
+			PlayerName: w.PlayerName,
+			MasterID:   w.MasterID,
+		})
+	}
+	return result
+}
+
+func (r *tV1RewardRequest) toReward(id uint) *types.TReward {
+	return &types.TReward{
+		ID:          id,
+		Title:       r.Title,
+		Description: r.Description,
+		Cost:        r.Cost,
+		Stock:       r.Stock,
+		IsUnlimited: r.IsUnlimited,
+		IsActive:    r.IsActive,
+	}
+}
+
+func v1RewardResponse(reward *types.TReward) tV1Reward {
+	return tV1Reward{
+		ID:          reward.ID,
+		Title:       reward.Title,
+		Description: reward.Description,
+		Cost:        reward.Cost,
+		Stock:       reward.Stock,
+		IsUnlimited: reward.IsUnlimited,
+		IsActive:    reward.IsActive,
+	}
+}
+
+func v1PurchasesResponse(purchases []types.TRewardPurchase) []tV1Purchase {
+	result := []tV1Purchase{}
+	for _, p := range purchases {
+		result = append(result, tV1Purchase{
+			ID:         p.ID,
+			Title:      p.Title,
+			PlayerName: p.PlayerName,
+			Cost:       p.Cost,
+			Status:     p.Status,
+			Date:       p.Date,
+		})
+	}
+	return result
+}
+
+func v1UserRolesResponse(user *types.TUserRoles) tV1UserRoles {
+	resp := tV1UserRoles{
+		ID:        user.ID,
+		Login:     user.Login,
+		IsMaster:  user.IsMaster,
+		Locked:    user.Locked,
+		CreatedAt: user.CreatedAt,
+		Roles:     []string{},
+	}
+	for role, ok := range user.Roles {
+		if ok {
+			resp.Roles = append(resp.Roles, role)
+		}
+	}
+	slices.Sort(resp.Roles)
+	return resp
+}
+
+func v1InviteResponse(invite *types.TMasterInvite) tV1Invite {
+	return tV1Invite{
+		ID:        invite.ID,
+		Code:      invite.Code,
+		Role:      invite.Role,
+		ExpiresAt: invite.ExpiresAt,
+	}
+}
+
+func v1TeamResponse(team *types.TMasterTeam) tV1Team {
+	resp := tV1Team{Members: []tV1Member{}, Invites: []tV1Invite{}}
+	for _, m := range team.Members {
+		resp.Members = append(resp.Members, tV1Member{UserID: m.UserID, Login: m.Login, Role: m.Role})
+	}
+	for _, invite := range team.Invites {
+		resp.Invites = append(resp.Invites, v1InviteResponse(&invite))
+	}
+	return resp
+}
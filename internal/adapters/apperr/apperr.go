@@ -1,10 +1,156 @@
+// Модуль apperr описывает ошибки приложения. Каждая ошибка несет код,
+// HTTP статус и сообщение для пользователя, поэтому rest переводит их
+// в ответ без знания о конкретных ошибках.
 package apperr
 
-import "errors"
+import (
+	"errors"
+	"maps"
+	"net/http"
+)
+
+// Error - ошибка приложения.
+type Error struct {
+	Code    string            // код ошибки для клиентов API
+	Status  int               // HTTP статус ответа
+	Message string            // сообщение для пользователя
+	Details map[string]string // ошибки полей: поле -> сообщение
+	err     error
+}
+
+// New создает ошибку приложения.
+func New(status int, code, message string) *Error {
+	return &Error{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.err != nil {
+		return e.Code + ": " + e.err.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is сравнивает ошибки по коду, поэтому копии из WithField и Wrap
+// совпадают с исходной ошибкой.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.Details = maps.Clone(e.Details)
+	return &c
+}
+
+// WithField возвращает копию ошибки с ошибкой поля field.
+func (e *Error) WithField(field, message string) *Error {
+	c := e.clone()
+	if c.Details == nil {
+		c.Details = map[string]string{}
+	}
+	c.Details[field] = message
+	return c
+}
+
+// WithMessage возвращает копию ошибки с другим сообщением для пользователя.
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+// Wrap возвращает копию ошибки с причиной err.
+func (e *Error) Wrap(err error) *Error {
+	c := e.clone()
+	c.err = err
+	return c
+}
+
+// As находит ошибку приложения в цепочке err.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// FromStatus возвращает ошибку для ответа, у которого есть только HTTP статус.
+func FromStatus(status int) *Error {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrDataNotFound
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusInternalServerError:
+		return ErrInternal
+	}
+	return New(status, "error", http.StatusText(status))
+}
 
 var (
-	ErrDataNotFound = errors.New("data not found")
+	ErrInternal     = New(http.StatusInternalServerError, "internal", "Что-то пошло не так")
+	ErrBadRequest   = New(http.StatusBadRequest, "bad_request", "Некорректный запрос")
+	ErrUnauthorized = New(http.StatusUnauthorized, "unauthorized", "Требуется авторизация")
+	ErrForbidden    = New(http.StatusForbidden, "forbidden", "Недостаточно прав")
+	ErrValidation   = New(http.StatusBadRequest, "validation", "Проверьте заполнение полей")
+
+	ErrTooManyRequests = New(http.StatusTooManyRequests, "too_many_requests", "Слишком много запросов, попробуйте позже")
+
+	ErrDataNotFound    = New(http.StatusNotFound, "not_found", "Данные не найдены")
+	ErrInvalidTimeZone = New(http.StatusBadRequest, "invalid_time_zone", "Неизвестный часовой пояс")
+
+	// user
+	ErrInvalidCredentials = New(http.StatusUnauthorized, "invalid_credentials", "Не удалось авторизоваться")
+	ErrMasterNotFound     = New(http.StatusNotFound, "master_not_found", "Мастер с таким кодом не найден")
+	ErrInviteNotFound     = New(http.StatusNotFound, "invite_not_found", "Приглашение не найдено или истекло")
+	ErrAlreadyMember      = New(http.StatusConflict, "already_member", "Вы уже участник этой группы")
+	ErrLoginTaken         = New(http.StatusConflict, "login_taken", "Логин уже занят")
+	ErrLoginLocked        = New(http.StatusTooManyRequests, "login_locked", "Слишком много неудачных попыток входа, попробуйте позже")
+	ErrAccountLocked      = New(http.StatusForbidden, "account_locked", "Учетная запись заблокирована")
+	// пароль сброшен админом, до смены доступны только смена пароля и выход
+	ErrPasswordChangeRequired = New(http.StatusForbidden, "password_change_required", "Смените временный пароль")
+
+	// quest
+	ErrInvalidSchedule = New(http.StatusBadRequest, "invalid_schedule", "Расписание квеста заполнено неверно")
 
 	// player quest
-	ErrPlayerQuestStatusExists = errors.New("quest player status already exists")
+	ErrPlayerQuestStatusExists = New(http.StatusConflict, "quest_already_sent", "Квест уже был отправлен")
+	ErrQuestNotScheduled       = New(http.StatusConflict, "quest_not_scheduled", "Сегодня квест недоступен по расписанию")
+	ErrQuestStatusClosed       = New(http.StatusConflict, "quest_status_closed", "Выполнение уже проверено")
+
+	// proof
+	ErrProofTooManyFiles  = New(http.StatusBadRequest, "proof_too_many_files", "Можно приложить не больше 5 файлов")
+	ErrProofFileTooLarge  = New(http.StatusRequestEntityTooLarge, "proof_file_too_large", "Файл слишком большой, можно до 10 МБ")
+	ErrProofFileType      = New(http.StatusUnsupportedMediaType, "proof_file_type", "Можно приложить только изображения, PDF или видео MP4")
+	ErrProofCommentLength = New(http.StatusBadRequest, "proof_comment_length", "Слишком длинный комментарий")
+
+	// comments
+	ErrCommentEmpty       = New(http.StatusBadRequest, "comment_empty", "Сообщение не может быть пустым")
+	ErrCommentLength      = New(http.StatusBadRequest, "comment_length", "Слишком длинное сообщение")
+	ErrRejectReasonLength = New(http.StatusBadRequest, "reject_reason_length", "Слишком длинная причина возврата")
+
+	// rewards
+	ErrInsufficientFunds     = New(http.StatusConflict, "insufficient_funds", "Недостаточно баллов")
+	ErrRewardOutOfStock      = New(http.StatusConflict, "reward_out_of_stock", "Награда закончилась")
+	ErrPurchaseAlreadyClosed = New(http.StatusConflict, "purchase_closed", "Покупка уже обработана")
+
+	// wallet
+	ErrTransactionReversed      = New(http.StatusConflict, "transaction_reversed", "Операция уже отменена")
+	ErrTransactionNotReversible = New(http.StatusConflict, "transaction_not_reversible", "Операцию нельзя отменить")
 )
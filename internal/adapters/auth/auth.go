@@ -1,73 +1,121 @@
+// Модуль auth - подсистема аутентификации: выдает и проверяет токены доступа и обновления
+// сессий, которые хранятся в базе. rest.Server работает с пользователем только через нее.
 package auth
 
 import (
+	"context"
+	"crypto/rand"
 	"fmt"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	accessCookieName  = "token"
+	refreshCookieName = "refresh_token"
+
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// minSecretKeyLength - минимальная длина ключа подписи токенов, 256 бит для HS256.
+	minSecretKeyLength = 32
 )
 
+// Config - настройки аутентификации.
+type Config struct {
+	SecretKey       string        `env:"SECRET_KEY"`
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL"`
+}
+
+// sessionStore - хранилище сессий и их токенов обновления.
+type sessionStore interface {
+	StartSession(ctx context.Context, userID uint, userAgent, ip string, ttl time.Duration) (string, string, error)
+	RefreshSession(ctx context.Context, token, ip string, ttl time.Duration) (*models.User, string, string, error)
+	CheckAccessToken(ctx context.Context, userID uint, sessionID string, version uint, ip string) (*models.User, error)
+	Logout(ctx context.Context, sessionID, refreshToken string) error
+	LogoutAll(ctx context.Context, userID uint) error
+}
+
 type AuthManager struct {
-	log       *zap.Logger
-	secretKey []byte
+	log             *zap.Logger
+	sessions        sessionStore
+	secretKey       []byte
+	devMode         bool
+	secure          bool
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
+type Option func(*AuthManager)
+
 func SetSecretKey(key []byte) Option {
 	return func(a *AuthManager) {
 		a.secretKey = key
 	}
 }
 
-func SetLogger(log *zap.Logger) Option {
+// SetDevMode - режим разработки: сервер запускается без SECRET_KEY или с коротким ключом.
+func SetDevMode(dev bool) Option {
 	return func(a *AuthManager) {
-		a.log = log
+		a.devMode = dev
 	}
 }
 
-type Option func(*AuthManager)
-
-func New(options ...Option) (*AuthManager, error) {
-	a := &AuthManager{
-		log: zap.NewNop(),
+func SetLogger(log *zap.Logger) Option {
+	return func(a *AuthManager) {
+		a.log = log
 	}
-
-	return a, nil
 }
 
-// VerifyJWT - Проверяет JWT.
-func (a *AuthManager) VerifyJWT(signedData string) (string, bool) {
-	token, err := jwt.Parse(signedData, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unknown signing method: %v", token.Header["alg"])
+// SetTokenTTL - задает время жизни токенов доступа и обновления. Нулевые значения не меняют настройку.
+func SetTokenTTL(access, refresh time.Duration) Option {
+	return func(a *AuthManager) {
+		if access > 0 {
+			a.accessTokenTTL = access
 		}
-		return a.secretKey, nil
-	})
-
-	if err != nil {
-		a.log.Debug("failed parse jwt token", zap.Error(err))
-		return "", false
-	}
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		if uniqueID, ok := claims["uniqueID"].(string); ok {
-			if uniqueID != "" {
-				return uniqueID, true
-			}
+		if refresh > 0 {
+			a.refreshTokenTTL = refresh
 		}
 	}
+}
 
-	return "", false
+// SetSecureCookie - cookie передаются только по https.
+func SetSecureCookie(secure bool) Option {
+	return func(a *AuthManager) {
+		a.secure = secure
+	}
 }
 
-// CreateJWT - Создает JWT ключ и записывает в него ID пользователя.
-func (a *AuthManager) CreateJWT(uniqueID string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"uniqueID": uniqueID,
-	})
-	tokenString, err := token.SignedString(a.secretKey)
-	if err != nil {
-		return "", fmt.Errorf("failed signe token: %w", err)
+func New(sessions sessionStore, options ...Option) (*AuthManager, error) {
+	a := &AuthManager{
+		log:             zap.NewNop(),
+		sessions:        sessions,
+		accessTokenTTL:  defaultAccessTokenTTL,
+		refreshTokenTTL: defaultRefreshTokenTTL,
+	}
+	for _, opt := range options {
+		opt(a)
 	}
 
-	return tokenString, nil
+	// пустым или коротким ключом токен доступа может подделать любой
+	if len(a.secretKey) < minSecretKeyLength {
+		if !a.devMode {
+			return nil, fmt.Errorf("secret key must be at least %d bytes, set SECRET_KEY", minSecretKeyLength)
+		}
+		if len(a.secretKey) > 0 {
+			a.log.Warn("secret key is shorter than required, allowed in dev mode only", zap.Int("min_length", minSecretKeyLength))
+			return a, nil
+		}
+		a.secretKey = make([]byte, minSecretKeyLength)
+		if _, err := rand.Read(a.secretKey); err != nil {
+			return nil, fmt.Errorf("failed generate secret key: %w", err)
+		}
+		a.log.Warn("SECRET_KEY is not set, tokens are signed with a random key and expire on restart")
+	}
+
+	return a, nil
 }
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// fakeSessions - хранилище сессий с одним пользователем, роли которого меняет тест.
+type fakeSessions struct {
+	user *models.User
+}
+
+func (f *fakeSessions) StartSession(ctx context.Context, userID uint, userAgent, ip string, ttl time.Duration) (string, string, error) {
+	return "sid", "refresh", nil
+}
+
+func (f *fakeSessions) RefreshSession(ctx context.Context, token, ip string, ttl time.Duration) (*models.User, string, string, error) {
+	return nil, "", "", apperr.ErrUnauthorized
+}
+
+func (f *fakeSessions) CheckAccessToken(ctx context.Context, userID uint, sessionID string, version uint, ip string) (*models.User, error) {
+	if userID != f.user.ID || sessionID != "sid" || version != f.user.TokenVersion {
+		return nil, apperr.ErrUnauthorized
+	}
+	return f.user, nil
+}
+
+func (f *fakeSessions) Logout(ctx context.Context, sessionID, refreshToken string) error {
+	return nil
+}
+
+func (f *fakeSessions) LogoutAll(ctx context.Context, userID uint) error {
+	f.user.TokenVersion++
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sessions := &fakeSessions{user: &models.User{ID: 7, Login: "player"}}
+	a, err := New(sessions, SetSecretKey([]byte("test_secret_key_of_thirty_two_by")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	access, err := a.createAccessToken(sessions.user, "sid")
+	if err != nil {
+		t.Fatalf("createAccessToken() error = %v", err)
+	}
+
+	var got *types.TUser
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		got, err = a.Authenticate(c)
+	})
+	request := func(token string) {
+		t.Helper()
+		got, err = nil, nil
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	request(access)
+	if err != nil || got.ID != 7 || got.IsQuestMaster {
+		t.Fatalf("Authenticate() = %+v, %v, want player without roles", got, err)
+	}
+
+	sessions.user.Roles = []models.Role{{Name: models.RoleQuestMaster}}
+	sessions.user.Memberships = []models.MasterMember{
+		{UserMasterID: 5, Role: models.MemberReviewer},
+		{UserMasterID: 3, Role: models.MemberOwner, UserMaster: models.UserMaster{Model: gorm.Model{ID: 3}, Name: "Класс", UniqueCode: "code"}},
+	}
+	request(access)
+	if err != nil || !got.IsQuestMaster || got.Master.ID != 3 || !got.Master.IsOwner() || got.Master.Name != "Класс" {
+		t.Errorf("Authenticate() after role change = %+v, %v, want owner of master 3", got, err)
+	}
+	if len(got.Masters) != 2 || got.Masters[0].ID != 3 || got.Masters[1].ID != 5 {
+		t.Errorf("Authenticate().Masters = %+v, want own master 3 before master 5", got.Masters)
+	}
+
+	// выбранная группа важнее своей
+	active := uint(5)
+	sessions.user.ActiveMasterID = &active
+	request(access)
+	if err != nil || got.Master.ID != 5 || got.Can(models.ActionCreateQuest) {
+		t.Errorf("Authenticate() with active master = %+v, %v, want reviewer of master 5", got, err)
+	}
+	// недоступная группа заменяется первой доступной
+	active = 9
+	request(access)
+	if err != nil || got.Master.ID != 3 {
+		t.Errorf("Authenticate() with unknown active master = %+v, %v, want owner of master 3", got, err)
+	}
+	sessions.user.ActiveMasterID = nil
+
+	// без роли мастера своя группа не действует, остается приглашение проверяющим
+	sessions.user.Roles = nil
+	request(access)
+	if err != nil || got.Master.ID != 5 || !got.Can(models.ActionConfirmSubmission) || got.Can(models.ActionCreateQuest) {
+		t.Errorf("Authenticate() as reviewer = %+v, %v, want reviewer of master 5", got, err)
+	}
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "7", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}, Session: "sid"}).SignedString(a.secretKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	withoutExp, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "7",
+	}, Session: "sid"}).SignedString(a.secretKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	otherKey, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "7", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}, Session: "sid"}).SignedString([]byte("other_key"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	withoutSession, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject: "7", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}).SignedString(a.secretKey)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	if err := sessions.LogoutAll(context.Background(), 7); err != nil {
+		t.Fatalf("LogoutAll() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "without token", token: ""},
+		{name: "expired", token: expired},
+		{name: "without exp", token: withoutExp},
+		{name: "other key", token: otherKey},
+		{name: "without session", token: withoutSession},
+		{name: "old token version", token: access},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request(tt.token)
+			if !errors.Is(err, apperr.ErrUnauthorized) {
+				t.Errorf("Authenticate() error = %v, want %v", err, apperr.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func TestNewSecretKey(t *testing.T) {
+	strong := "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		key     string
+		dev     bool
+		wantErr bool
+		wantKey string // пустой - ключ сгенерирован случайно
+	}{
+		{name: "not set", key: "", wantErr: true},
+		{name: "too short", key: "auth_secret_key", wantErr: true},
+		{name: "strong", key: strong, wantKey: strong},
+		{name: "short in dev mode", key: "dev", dev: true, wantKey: "dev"},
+		{name: "not set in dev mode", key: "", dev: true},
+	}
+	for _, tt := range tests {
+		a, err := New(&fakeSessions{}, SetSecretKey([]byte(tt.key)), SetDevMode(tt.dev))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: New() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		switch {
+		case tt.wantKey != "" && string(a.secretKey) != tt.wantKey:
+			t.Errorf("%s: secret key = %q, want %q", tt.name, a.secretKey, tt.wantKey)
+		case tt.wantKey == "" && (len(a.secretKey) != minSecretKeyLength || string(a.secretKey) == "auth_secret_key"):
+			t.Errorf("%s: secret key = %q, want random %d bytes", tt.name, a.secretKey, minSecretKeyLength)
+		}
+	}
+}
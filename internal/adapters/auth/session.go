@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+const ctxUserKey = "auth_user"
+
+// userAgentLimit - сколько символов User-Agent сохраняется в сессии.
+const userAgentLimit = 255
+
+// GetUser возвращает пользователя запроса, которого определили Authenticate или SignIn.
+func (a *AuthManager) GetUser(c *gin.Context) (*types.TUser, error) {
+	if user, ok := c.Get(ctxUserKey); ok {
+		if user, ok := user.(*types.TUser); ok {
+			return user, nil
+		}
+	}
+	return nil, errors.New("failed get user from request")
+}
+
+// setUser сохраняет пользователя сессии sessionID в контекст запроса.
+// Роли и их действия берутся из базы на каждом запросе, поэтому их изменения действуют сразу.
+// Действия мастера определяет роль пользователя в группе, которой он управляет.
+func setUser(c *gin.Context, user *models.User, sessionID string) *types.TUser {
+	sUser := &types.TUser{
+		ID:          user.ID,
+		SessionID:   sessionID,
+		Login:       user.Login,
+		Permissions: map[string]bool{},
+		Masters:     []types.TMaster{},
+
+		MustChangePassword: user.MustChangePassword,
+	}
+	for _, role := range user.Roles {
+		if role.Name == models.RoleAdmin {
+			sUser.IsAdmin = true
+		}
+		if role.Name == models.RoleQuestMaster {
+			sUser.IsQuestMaster = true
+		}
+		for _, action := range role.Actions {
+			sUser.Permissions[action.Name] = true
+		}
+	}
+	members := managedMemberships(user, sUser.IsQuestMaster)
+	for _, member := range members {
+		sUser.Masters = append(sUser.Masters, memberMaster(member))
+	}
+	if member := activeMembership(user, members); member != nil {
+		sUser.IsQuestMaster = true
+		sUser.Master = memberMaster(member)
+		for _, action := range models.MemberActions[models.MemberOwner] {
+			delete(sUser.Permissions, action)
+		}
+		for _, action := range models.MemberActions[member.Role] {
+			sUser.Permissions[action] = true
+		}
+	}
+	sUser.Action.IsQuestCreater = sUser.Can(models.ActionCreateQuest)
+	c.Set(ctxUserKey, sUser)
+	return sUser
+}
+
+// managedMemberships возвращает группы, которыми пользователь может управлять: свои, если у него
+// есть роль мастера квестов, и все, куда его пригласили. Свои группы идут первыми.
+func managedMemberships(user *models.User, questMaster bool) []*models.MasterMember {
+	owned, invited := []*models.MasterMember{}, []*models.MasterMember{}
+	for i := range user.Memberships {
+		member := &user.Memberships[i]
+		switch {
+		case member.Role != models.MemberOwner:
+			invited = append(invited, member)
+		case questMaster:
+			owned = append(owned, member)
+		}
+	}
+	return append(owned, invited...)
+}
+
+// activeMembership выбирает из members группу, которую пользователь выбрал для управления.
+// Если выбора нет или группа больше недоступна, берется первая.
+func activeMembership(user *models.User, members []*models.MasterMember) *models.MasterMember {
+	if len(members) == 0 {
+		return nil
+	}
+	if user.ActiveMasterID != nil {
+		for _, member := range members {
+			if member.UserMasterID == *user.ActiveMasterID {
+				return member
+			}
+		}
+	}
+	return members[0]
+}
+
+func memberMaster(member *models.MasterMember) types.TMaster {
+	return types.TMaster{
+		ID:   member.UserMasterID,
+		Name: member.UserMaster.Name,
+		Code: member.UserMaster.UniqueCode,
+		Role: member.Role,
+	}
+}
+
+// device возвращает описание устройства и адрес клиента для сессии.
+func device(c *gin.Context) (userAgent, ip string) {
+	userAgent = c.Request.UserAgent()
+	if runes := []rune(userAgent); len(runes) > userAgentLimit {
+		userAgent = string(runes[:userAgentLimit])
+	}
+	return userAgent, c.ClientIP()
+}
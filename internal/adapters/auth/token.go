@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// claims - данные токена доступа. Session - идентификатор сессии, Version - версия
+// токенов пользователя на момент выдачи.
+type claims struct {
+	jwt.RegisteredClaims
+	Session string `json:"sid"`
+	Version uint   `json:"ver"`
+}
+
+// Authenticate возвращает пользователя по токену доступа из заголовка Authorization: Bearer
+// или из cookie. Если у клиента с cookie токен доступа истек, токены обновляются
+// по токену обновления. Для неавторизованного клиента возвращает apperr.ErrUnauthorized.
+func (a *AuthManager) Authenticate(c *gin.Context) (*types.TUser, error) {
+	token, bearer := accessToken(c)
+	if token != "" {
+		user, err := a.verify(c, token)
+		if err == nil || !errors.Is(err, apperr.ErrUnauthorized) || bearer {
+			return user, err
+		}
+	}
+
+	refresh := refreshToken(c)
+	if refresh == "" {
+		return nil, apperr.ErrUnauthorized
+	}
+	user, _, err := a.refresh(c, refresh)
+	return user, err
+}
+
+// SignIn начинает сессию пользователя на устройстве клиента и выдает ее токены.
+func (a *AuthManager) SignIn(c *gin.Context, user *models.User) (*types.TAuthTokens, error) {
+	userAgent, ip := device(c)
+	sessionID, refresh, err := a.sessions.StartSession(c.Request.Context(), user.ID, userAgent, ip, a.refreshTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed start session: %w", err)
+	}
+	access, err := a.createAccessToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	setUser(c, user, sessionID)
+	return a.setTokens(c, access, refresh), nil
+}
+
+// Refresh обменивает токен обновления на новую пару токенов. Пустой refresh берется из cookie.
+func (a *AuthManager) Refresh(c *gin.Context, refresh string) (*types.TAuthTokens, error) {
+	if refresh == "" {
+		refresh = refreshToken(c)
+	}
+	if refresh == "" {
+		return nil, apperr.ErrUnauthorized
+	}
+	_, tokens, err := a.refresh(c, refresh)
+	return tokens, err
+}
+
+// SignOut завершает сессию клиента и очищает cookie. Сессия определяется по токену доступа,
+// а если он истек - по токену обновления: из refresh или из cookie.
+func (a *AuthManager) SignOut(c *gin.Context, refresh string) error {
+	if refresh == "" {
+		refresh = refreshToken(c)
+	}
+	var sessionID string
+	if token, _ := accessToken(c); token != "" {
+		if parsed, err := a.parse(token); err == nil {
+			sessionID = parsed.Session
+		}
+	}
+	err := a.sessions.Logout(c.Request.Context(), sessionID, refresh)
+	a.clearCookies(c)
+	c.Set(ctxUserKey, nil)
+	if err != nil {
+		return fmt.Errorf("failed logout: %w", err)
+	}
+	return nil
+}
+
+// SignOutAll завершает сессии пользователя на всех устройствах, включая текущее.
+func (a *AuthManager) SignOutAll(c *gin.Context, userID uint) error {
+	if err := a.sessions.LogoutAll(c.Request.Context(), userID); err != nil {
+		return fmt.Errorf("failed logout all devices: %w", err)
+	}
+	a.clearCookies(c)
+	c.Set(ctxUserKey, nil)
+	return nil
+}
+
+func (a *AuthManager) refresh(c *gin.Context, refresh string) (*types.TUser, *types.TAuthTokens, error) {
+	_, ip := device(c)
+	user, sessionID, next, err := a.sessions.RefreshSession(c.Request.Context(), refresh, ip, a.refreshTokenTTL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed refresh session: %w", err)
+	}
+	access, err := a.createAccessToken(user, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return setUser(c, user, sessionID), a.setTokens(c, access, next), nil
+}
+
+// verify проверяет подпись и срок токена доступа и что его сессия действует.
+func (a *AuthManager) verify(c *gin.Context, token string) (*types.TUser, error) {
+	parsed, err := a.parse(token)
+	if err != nil {
+		return nil, apperr.ErrUnauthorized.Wrap(err)
+	}
+	userID, err := strconv.ParseUint(parsed.Subject, 10, 32)
+	if err != nil {
+		return nil, apperr.ErrUnauthorized.Wrap(err)
+	}
+	_, ip := device(c)
+	user, err := a.sessions.CheckAccessToken(c.Request.Context(), uint(userID), parsed.Session, parsed.Version, ip)
+	if err != nil {
+		return nil, fmt.Errorf("failed check access token: %w", err)
+	}
+	return setUser(c, user, parsed.Session), nil
+}
+
+func (a *AuthManager) createAccessToken(user *models.User, sessionID string) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.accessTokenTTL)),
+		},
+		Session: sessionID,
+		Version: user.TokenVersion,
+	})
+	tokenString, err := token.SignedString(a.secretKey)
+	if err != nil {
+		return "", fmt.Errorf("failed signe token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// parse проверяет подпись и срок токена. Токены без срока, субъекта
+// или сессии не принимаются.
+func (a *AuthManager) parse(token string) (*claims, error) {
+	parsed := &claims{}
+	_, err := jwt.ParseWithClaims(token, parsed, func(token *jwt.Token) (interface{}, error) {
+		return a.secretKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, fmt.Errorf("failed parse jwt token: %w", err)
+	}
+	if parsed.Subject == "" || parsed.Session == "" {
+		return nil, errors.New("token without subject or session")
+	}
+	return parsed, nil
+}
+
+func (a *AuthManager) setTokens(c *gin.Context, access, refresh string) *types.TAuthTokens {
+	a.setCookie(c, accessCookieName, access, a.accessTokenTTL)
+	a.setCookie(c, refreshCookieName, refresh, a.refreshTokenTTL)
+	return &types.TAuthTokens{Access: access, Refresh: refresh, ExpiresIn: a.accessTokenTTL}
+}
+
+func (a *AuthManager) clearCookies(c *gin.Context) {
+	a.setCookie(c, accessCookieName, "", -1)
+	a.setCookie(c, refreshCookieName, "", -1)
+}
+
+func (a *AuthManager) setCookie(c *gin.Context, name, value string, ttl time.Duration) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   a.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// accessToken возвращает токен доступа из заголовка Authorization: Bearer или из cookie.
+// bearer сообщает, что клиент передал токен заголовком.
+func accessToken(c *gin.Context) (token string, bearer bool) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, value, _ := strings.Cut(header, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value), true
+		}
+	}
+	if cookie, err := c.Request.Cookie(accessCookieName); err == nil {
+		return cookie.Value, false
+	}
+	return "", false
+}
+
+func refreshToken(c *gin.Context) string {
+	if cookie, err := c.Request.Cookie(refreshCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
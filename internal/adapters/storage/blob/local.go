@@ -0,0 +1,87 @@
+// Модуль blob хранит файлы вложений вне базы данных.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+)
+
+type Config struct {
+	Dir string `env:"BLOB_DIR"`
+}
+
+// Local - хранилище файлов в каталоге локальной файловой системы.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) (*Local, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("failed create blob dir: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// path переводит ключ в путь внутри каталога хранилища, не давая выйти за его пределы.
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(filepath.Clean("/"+key)))
+}
+
+// Put сохраняет файл под ключом key. Файл сначала пишется во временный,
+// поэтому по ключу никогда не читается недописанное содержимое.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) error {
+	path := l.path(key)
+	err := os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("failed create blob dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed create blob file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed write blob: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed close blob file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed save blob: %w", err)
+	}
+	return nil
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errors.Join(err, apperr.ErrDataNotFound)
+		}
+		return nil, fmt.Errorf("failed open blob: %w", err)
+	}
+	return f, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed delete blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Storage) GetQuestComments(ctx context.Context, statusID uint) (*[]models.QuestComment, error) {
+	comments := []models.QuestComment{}
+	err := s.db.WithContext(ctx).Where("quest_player_status_id = ?", statusID).
+		Preload("Author").Order("created_at").Find(&comments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest comments: %w", err)
+	}
+	return &comments, nil
+}
+
+func (s *Storage) NewQuestComment(ctx context.Context, comment *models.QuestComment) (*models.QuestComment, error) {
+	err := s.db.WithContext(ctx).Omit("QuestPlayerStatus", "Author").Create(comment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed create quest comment: %w", err)
+	}
+	err = s.db.WithContext(ctx).Preload("Author").First(comment, comment.ID).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest comment: %w", err)
+	}
+	return comment, nil
+}
@@ -2,34 +2,56 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"github.com/mod-develop/backend/internal/adapters/apperr"
 	"github.com/mod-develop/backend/internal/models"
 )
 
 type Storage struct {
-	db  *gorm.DB
-	log *zap.Logger
+	db          *gorm.DB
+	log         *zap.Logger
+	dialect     string
+	autoMigrate bool
 }
 
+// Config - настройки хранилища. DSN вида sqlite://path открывает встроенную
+// базу SQLite, остальные DSN - Postgres.
 type Config struct {
 	DSN string `env:"DATABASE_URI"`
 }
 
 type option func(s *Storage)
 
+// SetLogger устанавливает логгер хранилища.
+func SetLogger(log *zap.Logger) option {
+	return func(s *Storage) {
+		s.log = log
+	}
+}
+
+// SkipMigrate отключает применение миграций при подключении,
+// чтобы ими можно было управлять командой migrate.
+func SkipMigrate() option {
+	return func(s *Storage) {
+		s.autoMigrate = false
+	}
+}
+
 func New(ctx context.Context, dsn string, options ...option) (*Storage, error) {
 	var err error
 	s := &Storage{
-		log: zap.NewNop(),
+		log:         zap.NewNop(),
+		autoMigrate: true,
 	}
 	lgr := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
@@ -41,12 +63,26 @@ func New(ctx context.Context, dsn string, options ...option) (*Storage, error) {
 			Colorful:                  true,
 		},
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: lgr,
+	dialector, dialect, err := openDialector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         lgr,
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed connect to database: %w", err)
 	}
+	s.dialect = dialect
+	if dialect == dialectSQLite {
+		// SQLite допускает одного писателя, а база в памяти живет в одном соединении
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed get sqlite connection: %w", err)
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
 
 	s.db = db.WithContext(ctx)
 
@@ -54,84 +90,92 @@ func New(ctx context.Context, dsn string, options ...option) (*Storage, error) {
 		opt(s)
 	}
 
-	err = s.db.AutoMigrate(
-		&models.Action{},
-		&models.Role{},
-		&models.User{},
-		&models.UserMaster{},
-		&models.PlayerWallet{},
-		&models.Quest{},
-		&models.QuestPlayerStatus{},
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("migration failed: %w", err)
-	}
-
-	err = s.defaultActions(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed create default actions: %w", err)
-	}
-	err = s.defaultRoles(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed create default roles: %w", err)
+	if s.autoMigrate {
+		if err = s.Migrate(ctx); err != nil {
+			return nil, fmt.Errorf("migration failed: %w", err)
+		}
 	}
 
 	return s, nil
 }
 
-func (s *Storage) defaultRoles(ctx context.Context) error {
-	err := s.db.WithContext(ctx).Save(&models.DefaultRoles).Error
-	if err != nil {
-		return fmt.Errorf("failed create default roles: %w", err)
-	}
-	return nil
-}
-
-func (s *Storage) defaultActions(ctx context.Context) error {
-	err := s.db.WithContext(ctx).Save(&models.DefaultActions).Error
-	if err != nil {
-		return fmt.Errorf("failed create default actions: %w", err)
-	}
-	return nil
-}
-
 func (s *Storage) GetQuestNotPayed(ctx context.Context) (*[]models.QuestPlayerStatus, error) {
 	quests := []models.QuestPlayerStatus{}
 	err := s.db.WithContext(ctx).
 		Where("accrual_date is NULL").
 		Where("confirmation_date is not NULL").
-		Preload("Quest").
-		Preload("Quest.User").Preload("Quest.User.QuestMaster").Limit(100).Find(&quests).Error
+		Preload("Quest").Preload("Quest.StreakBonuses").Limit(100).Find(&quests).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed get quests status: %w", err)
 	}
 
 	return &quests, nil
 }
-func (s *Storage) PayQuest(ctx context.Context, quest *models.QuestPlayerStatus) error {
-	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		masterID := quest.Quest.User.QuestMaster.ID
-		playerID := quest.PlayerID
 
-		wallet := &models.PlayerWallet{UserMasterID: masterID, PlayerID: playerID}
-		_ = tx.Where("user_master_id = ? and player_id = ?", masterID, playerID).First(wallet).Error
-		wallet.Prise += int(quest.Quest.Price)
-		err := tx.Save(wallet).Error
-		if err != nil {
-			return fmt.Errorf("failed save wallet: %w", err)
-		}
+// PayQuest начисляет награду за подтвержденный квест. Серия игрока пересчитывается
+// в той же транзакции, а бонусы за серию начисляются отдельными записями журнала.
+// Уже оплаченное выполнение повторно не оплачивается.
+func (s *Storage) PayQuest(ctx context.Context, quest *models.QuestPlayerStatus, onStreak func(streak *models.QuestStreak, paidPeriods []string) []models.QuestStreakBonus) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// выполнение сначала помечается оплаченным: из двух одновременных оплат
+		// условие пройдет только одна, вторая ничего не начислит
 		currentTime := time.Now().UTC()
+		claim := tx.Model(&models.QuestPlayerStatus{}).
+			Where("id = ? and accrual_date is null", quest.ID).
+			Update("accrual_date", currentTime)
+		if claim.Error != nil {
+			return fmt.Errorf("failed save quest status as payed: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return nil
+		}
 		quest.AccrualDate = &currentTime
-		//сбрасываем данные, что бы орм не пыталась их обновить
-		quest.Quest = models.Quest{}
-		quest.Player = models.User{}
 
-		err = tx.Save(quest).Error
+		statusID := quest.ID
+		err := postTransaction(tx, &models.WalletTransaction{
+			UserMasterID:        quest.Quest.UserMasterID,
+			PlayerID:            quest.PlayerID,
+			Type:                models.TransactionAccrual,
+			Amount:              int(quest.Quest.Price),
+			Comment:             quest.Quest.Title,
+			QuestPlayerStatusID: &statusID,
+		}, true)
 		if err != nil {
-			return fmt.Errorf("failed save quest status as payed: %w", err)
+			return fmt.Errorf("failed accrual to wallet: %w", err)
+		}
+		if onStreak == nil {
+			return nil
 		}
 
+		streak, err := lockStreak(tx, quest.QuestID, quest.PlayerID)
+		if err != nil {
+			return fmt.Errorf("failed get quest streak: %w", err)
+		}
+		var paid []string
+		err = tx.Model(&models.QuestPlayerStatus{}).
+			Where("quest_id = ? and player_id = ? and accrual_date is not NULL", quest.QuestID, quest.PlayerID).
+			Pluck("period_key", &paid).Error
+		if err != nil {
+			return fmt.Errorf("failed get paid quest periods: %w", err)
+		}
+		bonuses := onStreak(streak, paid)
+		err = tx.Save(streak).Error
+		if err != nil {
+			return fmt.Errorf("failed save quest streak: %w", err)
+		}
+		for _, bonus := range bonuses {
+			err = postTransaction(tx, &models.WalletTransaction{
+				UserMasterID:        quest.Quest.UserMasterID,
+				PlayerID:            quest.PlayerID,
+				Type:                models.TransactionStreak,
+				Amount:              int(bonus.Bonus),
+				Comment:             fmt.Sprintf("%s: серия %d", quest.Quest.Title, bonus.Days),
+				QuestPlayerStatusID: &statusID,
+			}, true)
+			if err != nil {
+				return fmt.Errorf("failed accrual streak bonus to wallet: %w", err)
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -141,6 +185,13 @@ func (s *Storage) PayQuest(ctx context.Context, quest *models.QuestPlayerStatus)
 	return nil
 }
 
+// preloadUser подгружает роли пользователя и участие в группах мастера.
+func preloadUser(db *gorm.DB) *gorm.DB {
+	return db.Preload("Roles").Preload("Roles.Actions").
+		Preload("Memberships", func(db *gorm.DB) *gorm.DB { return db.Order("master_members.id") }).
+		Preload("Memberships.UserMaster")
+}
+
 func (s *Storage) NewUser(ctx context.Context, login, passwordHash string) (*models.User, error) {
 	user := &models.User{
 		Login:        login,
@@ -155,7 +206,7 @@ func (s *Storage) NewUser(ctx context.Context, login, passwordHash string) (*mod
 
 func (s *Storage) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
 	user := &models.User{}
-	err := s.db.WithContext(ctx).Where("login = ?", login).Preload("Roles").Preload("Roles.Actions").Preload("QuestMaster").First(user).Error
+	err := s.db.WithContext(ctx).Where("LOWER(login) = LOWER(?)", login).Scopes(preloadUser).First(user).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed find user: %w", err)
 	}
@@ -164,20 +215,155 @@ func (s *Storage) GetUserByLogin(ctx context.Context, login string) (*models.Use
 
 func (s *Storage) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
 	user := &models.User{}
-	err := s.db.WithContext(ctx).Where("id = ?", userID).Preload("Roles").Preload("Roles.Actions").Preload("QuestMaster").First(user).Error
+	err := s.db.WithContext(ctx).Where("id = ?", userID).Scopes(preloadUser).First(user).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed find user: %w", err)
 	}
 	return user, nil
 }
 
-func (s *Storage) GetUsers(ctx context.Context) (*[]models.User, error) {
+// FindUsers возвращает страницу пользователей, в логине которых без учета регистра
+// встречается query, и общее их число. Пустой query не фильтрует.
+func (s *Storage) FindUsers(ctx context.Context, query string, offset, limit int) (*[]models.User, int64, error) {
+	byLogin := func(db *gorm.DB) *gorm.DB {
+		if query == "" {
+			return db
+		}
+		return db.Where(`LOWER(login) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(query))+"%")
+	}
+
+	var total int64
+	err := s.db.WithContext(ctx).Model(&models.User{}).Scopes(byLogin).Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed count users: %w", err)
+	}
+
 	users := &[]models.User{}
-	err := s.db.WithContext(ctx).Find(users).Error
+	err = s.db.WithContext(ctx).Scopes(byLogin, preloadUser).Order("id").Offset(offset).Limit(limit).Find(users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed find users: %w", err)
+	}
+	return users, total, nil
+}
+
+// escapeLike экранирует спецсимволы LIKE, чтобы строка искалась как есть.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (s *Storage) GetUserStats(ctx context.Context) (*models.UserStats, error) {
+	stats := &models.UserStats{}
+	db := s.db.WithContext(ctx)
+	if err := db.Model(&models.User{}).Count(&stats.Users).Error; err != nil {
+		return nil, fmt.Errorf("failed count users: %w", err)
+	}
+	if err := db.Model(&models.User{}).Where("locked_at is not null").Count(&stats.Locked).Error; err != nil {
+		return nil, fmt.Errorf("failed count locked users: %w", err)
+	}
+	err := db.Model(&models.MasterMember{}).Where("role = ?", models.MemberOwner).Distinct("user_id").Count(&stats.Masters).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed count masters: %w", err)
+	}
+	return stats, nil
+}
+
+// SetUserLocked блокирует пользователя с момента lockedAt, nil снимает блокировку.
+func (s *Storage) SetUserLocked(ctx context.Context, userID uint, lockedAt *time.Time) error {
+	res := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("locked_at", lockedAt)
+	if res.Error != nil {
+		return fmt.Errorf("failed update user lock: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed update user lock: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// SetUserPassword меняет хеш пароля, mustChange требует сменить пароль при следующем входе.
+func (s *Storage) SetUserPassword(ctx context.Context, userID uint, passwordHash string, mustChange bool) error {
+	res := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]any{"password_hash": passwordHash, "must_change_password": mustChange})
+	if res.Error != nil {
+		return fmt.Errorf("failed update user password: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed update user password: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Storage) GetRoles(ctx context.Context) (*[]models.Role, error) {
+	roles := &[]models.Role{}
+	err := s.db.WithContext(ctx).Preload("Actions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("actions.id")
+	}).Order("id").Find(roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed find roles: %w", err)
+	}
+	return roles, nil
+}
+
+// SetUserRoles заменяет роли пользователя, несуществующие роли пропускаются.
+func (s *Storage) SetUserRoles(ctx context.Context, userID uint, roleIDs []uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user := &models.User{}
+		err := tx.Where("id = ?", userID).First(user).Error
+		if err != nil {
+			return fmt.Errorf("failed get user: %w", err)
+		}
+
+		roles := []models.Role{}
+		if len(roleIDs) > 0 {
+			err = tx.Where("id IN ?", roleIDs).Find(&roles).Error
+			if err != nil {
+				return fmt.Errorf("failed get roles: %w", err)
+			}
+		}
+
+		err = tx.Model(user).Association("Roles").Replace(roles)
+		if err != nil {
+			return fmt.Errorf("failed replace roles: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed set user roles: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) SetUserTimeZone(ctx context.Context, userID uint, timeZone string) error {
+	err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("time_zone", timeZone).Error
+	if err != nil {
+		return fmt.Errorf("failed update user time zone: %w", err)
+	}
+	return nil
+}
+
+// SetUserActiveMaster запоминает группу, которой управляет пользователь, nil сбрасывает выбор.
+func (s *Storage) SetUserActiveMaster(ctx context.Context, userID uint, masterID *uint) error {
+	err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("active_master_id", masterID).Error
+	if err != nil {
+		return fmt.Errorf("failed update user active master: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetMasterPlayerJoinedAt(ctx context.Context, masterID, playerID uint) (*time.Time, error) {
+	var joined []sql.NullTime
+	err := s.db.WithContext(ctx).Table("master_players").
+		Where("user_master_id = ? and user_id = ?", masterID, playerID).
+		Pluck("created_at", &joined).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed find users: %w", err)
+		return nil, fmt.Errorf("failed get master player: %w", err)
+	}
+	if len(joined) == 0 {
+		return nil, fmt.Errorf("failed get master player: %w", gorm.ErrRecordNotFound)
 	}
-	return users, nil
+	if !joined[0].Valid {
+		return nil, nil
+	}
+	return &joined[0].Time, nil
 }
 
 func (s *Storage) NewQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error) {
@@ -190,16 +376,16 @@ func (s *Storage) NewQuest(ctx context.Context, quest *models.Quest) (*models.Qu
 
 func (s *Storage) GetQuest(ctx context.Context, questID uint) (*models.Quest, error) {
 	quest := &models.Quest{}
-	err := s.db.WithContext(ctx).Where("id = ?", questID).Preload("Players").Preload("User").Preload("User.QuestMaster").First(quest).Error
+	err := s.db.WithContext(ctx).Where("id = ?", questID).Preload("Players").Preload("StreakBonuses").Preload("User").Preload("UserMaster").First(quest).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed get quest: %w", err)
 	}
 	return quest, nil
 }
 
-func (s *Storage) GetQuests(ctx context.Context, userID uint) (*[]models.Quest, error) {
+func (s *Storage) GetQuests(ctx context.Context, masterID uint) (*[]models.Quest, error) {
 	quests := []models.Quest{}
-	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("updated_at desc").Preload("Players").Find(&quests).Error
+	err := s.db.WithContext(ctx).Where("user_master_id = ?", masterID).Order("updated_at desc").Preload("Players").Find(&quests).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed get quests: %w", err)
 	}
@@ -208,7 +394,12 @@ func (s *Storage) GetQuests(ctx context.Context, userID uint) (*[]models.Quest,
 
 func (s *Storage) UpdQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error) {
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Where("id = ?", quest.ID).Save(quest).Error
+		err := tx.Unscoped().Where("quest_id = ?", quest.ID).Delete(&models.QuestStreakBonus{}).Error
+		if err != nil {
+			return fmt.Errorf("failed delete quest streak bonuses: %w", err)
+		}
+		// квест собирается из формы без даты создания, а по ней считаются штрафы за пропуски
+		err = tx.Where("id = ?", quest.ID).Omit("CreatedAt").Save(quest).Error
 		if err != nil {
 			return fmt.Errorf("failed update quest: %w", err)
 		}
@@ -225,13 +416,15 @@ func (s *Storage) UpdQuest(ctx context.Context, quest *models.Quest) (*models.Qu
 	return quest, nil
 }
 
-func (s *Storage) GetAwaitQuests(ctx context.Context, userID uint) (*[]models.QuestPlayerStatus, error) {
+func (s *Storage) GetAwaitQuests(ctx context.Context, masterID uint) (*[]models.QuestPlayerStatus, error) {
 	awaits := []models.QuestPlayerStatus{}
 	err := s.db.WithContext(ctx).
-		Joins("join quests on quests.id = quest_player_statuses.quest_id and quests.user_id = ?", userID).
+		Joins("join quests on quests.id = quest_player_statuses.quest_id and quests.user_master_id = ?", masterID).
 		Where("confirmation_date is null").
 		Where("request_execute_date > reject_execute_date or reject_execute_date is NULL").
-		Preload("Player").Preload("Quest").
+		Preload("Player").Preload("Quest").Preload("Attachments").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Comments.Author").
 		Order("request_execute_date").
 		Find(&awaits).Error
 	if err != nil {
@@ -251,29 +444,85 @@ func (s *Storage) GetAwaitQUest(ctx context.Context, awaitID uint) (*models.Ques
 	return await, nil
 }
 
-func (s *Storage) UpdAwaitQuest(ctx context.Context, status *models.QuestPlayerStatus) (*models.QuestPlayerStatus, error) {
-	err := s.db.WithContext(ctx).Updates(status).Error
+// UpdAwaitQuest записывает решение мастера по отправке, которая еще ждет проверки.
+// Штраф penalty, если он задан, списывается в той же транзакции.
+func (s *Storage) UpdAwaitQuest(ctx context.Context, status *models.QuestPlayerStatus, penalty *models.QuestPenalty, comment string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(status).
+			Where("confirmation_date is null and (reject_execute_date is null or reject_execute_date < request_execute_date)").
+			Select("ConfirmationDate", "RejectExecuteDate", "RejectReason").Updates(status)
+		if res.Error != nil {
+			return fmt.Errorf("failed update await quest: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return apperr.ErrQuestStatusClosed
+		}
+		if penalty == nil {
+			return nil
+		}
+		if _, err := chargePenalty(tx, penalty, comment); err != nil {
+			return fmt.Errorf("failed charge penalty: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed update await quest: %w", err)
+		return fmt.Errorf("failed update await quest: %w", err)
 	}
-
-	return status, nil
+	return nil
 }
 
-func (s *Storage) NewMaster(ctx context.Context, user *models.User) (*models.UserMaster, error) {
+// NewMaster создает группу мастера: владелец становится ее участником и получает роль мастера квестов.
+func (s *Storage) NewMaster(ctx context.Context, master *models.UserMaster) (*models.UserMaster, error) {
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		err := tx.Save(user).Error
+		err := tx.Create(master).Error
 		if err != nil {
 			return fmt.Errorf("failed create master: %w", err)
 		}
 
+		err = tx.Create(&models.MasterMember{
+			UserMasterID: master.ID,
+			UserID:       master.UserID,
+			Role:         models.MemberOwner,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed add master owner: %w", err)
+		}
+
+		user := &models.User{}
+		err = tx.Where("id = ?", master.UserID).First(user).Error
+		if err != nil {
+			return fmt.Errorf("failed get master owner: %w", err)
+		}
+		role := &models.Role{}
+		err = tx.Where("id = ?", models.RoleQuestMasterObject.ID).First(role).Error
+		if err != nil {
+			return fmt.Errorf("failed get quest master role: %w", err)
+		}
+		err = tx.Model(user).Association("Roles").Append(role)
+		if err != nil {
+			return fmt.Errorf("failed add quest master role: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed create quest master: %w", err)
 	}
 
-	return user.QuestMaster, nil
+	return master, nil
+}
+
+// UpdMasterName переименовывает группу мастера.
+func (s *Storage) UpdMasterName(ctx context.Context, masterID uint, name string) error {
+	res := s.db.WithContext(ctx).Model(&models.UserMaster{}).Where("id = ?", masterID).
+		Updates(map[string]any{"name": name, "updated_at": time.Now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed update master name: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed update master name: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
 }
 
 func (s *Storage) GetMasterByCode(ctx context.Context, code string) (*models.UserMaster, error) {
@@ -312,7 +561,9 @@ func (s *Storage) AddPlayerForMaster(ctx context.Context, masterID, playerID uin
 			return fmt.Errorf("failed get player: %w", err)
 		}
 
-		err = tx.Model(master).Association("Players").Append([]models.User{*player})
+		// время вступления пишется в ту же таблицу связи, поэтому вставка идет в обход Association
+		err = tx.Exec("INSERT INTO master_players (user_master_id, user_id, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			master.ID, player.ID, time.Now()).Error
 		if err != nil {
 			return fmt.Errorf("failed add player for master: %w", err)
 		}
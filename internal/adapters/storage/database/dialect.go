@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+
+	sqliteScheme = "sqlite://"
+	sqliteMemory = ":memory:"
+)
+
+// sqlitePragmas включают внешние ключи и ожидание блокировки записи,
+// WAL позволяет читать базу во время записи.
+var sqlitePragmas = []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+
+// openDialector выбирает драйвер по схеме DSN: sqlite://path - встроенная база SQLite
+// (sqlite://:memory: - в памяти), остальные DSN открываются как Postgres.
+func openDialector(dsn string) (gorm.Dialector, string, error) {
+	if !strings.HasPrefix(dsn, sqliteScheme) {
+		return postgres.Open(dsn), dialectPostgres, nil
+	}
+
+	path, query, _ := strings.Cut(strings.TrimPrefix(dsn, sqliteScheme), "?")
+	if path == "" {
+		return nil, "", fmt.Errorf("empty sqlite path in dsn `%s`", dsn)
+	}
+	params := []string{}
+	if query != "" {
+		params = append(params, query)
+	}
+	for _, pragma := range sqlitePragmas {
+		name, _, _ := strings.Cut(pragma, "(")
+		if path == sqliteMemory && name == "journal_mode" {
+			continue
+		}
+		if !strings.Contains(query, "_pragma="+name) {
+			params = append(params, "_pragma="+pragma)
+		}
+	}
+
+	if path != sqliteMemory {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return nil, "", fmt.Errorf("failed create sqlite directory: %w", err)
+		}
+	}
+	return sqlite.Open(path + "?" + strings.Join(params, "&")), dialectSQLite, nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Storage) GetMasterMember(ctx context.Context, masterID, userID uint) (*models.MasterMember, error) {
+	member := &models.MasterMember{}
+	err := s.db.WithContext(ctx).Where("user_master_id = ? and user_id = ?", masterID, userID).First(member).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get master member: %w", err)
+	}
+	return member, nil
+}
+
+// GetMasterMembers возвращает участников группы мастера в порядке добавления.
+func (s *Storage) GetMasterMembers(ctx context.Context, masterID uint) (*[]models.MasterMember, error) {
+	members := &[]models.MasterMember{}
+	err := s.db.WithContext(ctx).Where("user_master_id = ?", masterID).Preload("User").Order("id").Find(members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get master members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *Storage) SetMemberRole(ctx context.Context, masterID, userID uint, role models.MemberRole) error {
+	res := s.db.WithContext(ctx).Model(&models.MasterMember{}).
+		Where("user_master_id = ? and user_id = ?", masterID, userID).
+		Updates(map[string]any{"role": role, "updated_at": time.Now()})
+	if res.Error != nil {
+		return fmt.Errorf("failed update member role: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed update member role: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteMasterMember(ctx context.Context, masterID, userID uint) error {
+	res := s.db.WithContext(ctx).Where("user_master_id = ? and user_id = ?", masterID, userID).Delete(&models.MasterMember{})
+	if res.Error != nil {
+		return fmt.Errorf("failed delete master member: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed delete master member: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *Storage) NewMasterInvite(ctx context.Context, invite *models.MasterInvite) (*models.MasterInvite, error) {
+	err := s.db.WithContext(ctx).Create(invite).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed create master invite: %w", err)
+	}
+	return invite, nil
+}
+
+// activeInvites отбирает приглашения, которые еще не приняты и действуют на момент now.
+func (s *Storage) activeInvites(ctx context.Context, now time.Time) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&models.MasterInvite{}).
+		Where("accepted_at is NULL and expires_at > ?", now)
+}
+
+// GetMasterInvite возвращает действующее приглашение по коду.
+func (s *Storage) GetMasterInvite(ctx context.Context, code string, now time.Time) (*models.MasterInvite, error) {
+	invite := &models.MasterInvite{}
+	err := s.activeInvites(ctx, now).Where("code = ?", code).First(invite).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get master invite: %w", err)
+	}
+	return invite, nil
+}
+
+// GetMasterInvites возвращает действующие приглашения группы, новые первыми.
+func (s *Storage) GetMasterInvites(ctx context.Context, masterID uint, now time.Time) (*[]models.MasterInvite, error) {
+	invites := &[]models.MasterInvite{}
+	err := s.activeInvites(ctx, now).Where("user_master_id = ?", masterID).Order("id desc").Find(invites).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get master invites: %w", err)
+	}
+	return invites, nil
+}
+
+// DeleteMasterInvite отзывает непринятое приглашение группы.
+func (s *Storage) DeleteMasterInvite(ctx context.Context, masterID, inviteID uint) error {
+	res := s.db.WithContext(ctx).
+		Where("id = ? and user_master_id = ? and accepted_at is NULL", inviteID, masterID).
+		Delete(&models.MasterInvite{})
+	if res.Error != nil {
+		return fmt.Errorf("failed delete master invite: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("failed delete master invite: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// AcceptMasterInvite добавляет пользователя в группу по действующему приглашению и закрывает его.
+// Если пользователь уже участник группы, возвращает gorm.ErrDuplicatedKey, приглашение остается действующим.
+func (s *Storage) AcceptMasterInvite(ctx context.Context, code string, userID uint, now time.Time) (*models.MasterMember, error) {
+	member := &models.MasterMember{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		invite := &models.MasterInvite{}
+		err := tx.Where("code = ? and accepted_at is NULL and expires_at > ?", code, now).First(invite).Error
+		if err != nil {
+			return fmt.Errorf("failed get master invite: %w", err)
+		}
+
+		var count int64
+		err = tx.Model(&models.MasterMember{}).
+			Where("user_master_id = ? and user_id = ?", invite.UserMasterID, userID).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed check master member: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("failed add master member: %w", gorm.ErrDuplicatedKey)
+		}
+
+		res := tx.Model(&models.MasterInvite{}).Where("id = ? and accepted_at is NULL", invite.ID).
+			Updates(map[string]any{"accepted_by_id": userID, "accepted_at": now})
+		if res.Error != nil {
+			return fmt.Errorf("failed close master invite: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("failed close master invite: %w", gorm.ErrRecordNotFound)
+		}
+
+		member.UserMasterID = invite.UserMasterID
+		member.UserID = userID
+		member.Role = invite.Role
+		err = tx.Create(member).Error
+		if err != nil {
+			return fmt.Errorf("failed add master member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed accept master invite: %w", err)
+	}
+	return member, nil
+}
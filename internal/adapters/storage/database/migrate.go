@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID - ключ advisory lock, под которым реплики применяют миграции по очереди.
+const migrationLockID = 72_617_001
+
+// migration - версия схемы со скриптами применения и отката.
+type migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus - состояние версии схемы.
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+// schemaMigration - запись о примененной версии.
+type schemaMigration struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// loadMigrations читает встроенные скрипты диалекта вида 0001_name.up.sql и 0001_name.down.sql.
+// Версии миграций одинаковы для всех диалектов.
+func loadMigrations(dialect string) ([]migration, error) {
+	files, err := fs.Glob(migrationFiles, path.Join("migrations", dialect, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("failed list migrations: %w", err)
+	}
+	byVersion := map[uint]*migration{}
+	for _, file := range files {
+		name := path.Base(file)
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name `%s`", name)
+		}
+		sVersion, title, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseUint(sVersion, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version `%s`: %w", name, err)
+		}
+		body, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed read migration `%s`: %w", name, err)
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &migration{Version: uint(version), Name: title}
+			byVersion[uint(version)] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have up and down scripts", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrate применяет все непримененные миграции.
+func (s *Storage) Migrate(ctx context.Context) error {
+	return s.withMigrationLock(ctx, func(conn *gorm.DB, migrations []migration, applied map[uint]time.Time) error {
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			err := s.runMigration(conn, m.Up, func(tx *gorm.DB) error {
+				return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed apply migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			s.log.Info("migration applied", zap.Uint("version", m.Version), zap.String("name", m.Name))
+		}
+		return nil
+	})
+}
+
+// Rollback откатывает steps последних примененных миграций.
+func (s *Storage) Rollback(ctx context.Context, steps int) error {
+	return s.withMigrationLock(ctx, func(conn *gorm.DB, migrations []migration, applied map[uint]time.Time) error {
+		for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			err := s.runMigration(conn, m.Down, func(tx *gorm.DB) error {
+				return tx.Delete(&schemaMigration{Version: m.Version}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed rollback migration %04d_%s: %w", m.Version, m.Name, err)
+			}
+			s.log.Info("migration rolled back", zap.Uint("version", m.Version), zap.String("name", m.Name))
+			steps--
+		}
+		return nil
+	})
+}
+
+// runMigration выполняет скрипт и запись о версии в одной транзакции. SQLite меняет ограничения
+// только пересозданием таблицы, а удаление таблицы при включенных внешних ключах удаляет и ссылки
+// на нее. Поэтому внешние ключи SQLite на время скрипта выключаются и проверяются перед фиксацией.
+func (s *Storage) runMigration(conn *gorm.DB, script string, record func(tx *gorm.DB) error) error {
+	if s.dialect == dialectSQLite {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return fmt.Errorf("failed disable foreign keys: %w", err)
+		}
+		defer func() {
+			if err := conn.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+				s.log.Error("failed enable foreign keys", zap.Error(err))
+			}
+		}()
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(script).Error; err != nil {
+			return err
+		}
+		if s.dialect == dialectSQLite {
+			violations := []map[string]any{}
+			if err := tx.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+				return fmt.Errorf("failed check foreign keys: %w", err)
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("foreign key violations: %v", violations)
+			}
+		}
+		return record(tx)
+	})
+}
+
+// MigrationStatus возвращает все известные миграции и время их применения.
+func (s *Storage) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	status := []MigrationStatus{}
+	err := s.withMigrationLock(ctx, func(_ *gorm.DB, migrations []migration, applied map[uint]time.Time) error {
+		for _, m := range migrations {
+			item := MigrationStatus{Version: m.Version, Name: m.Name}
+			if at, ok := applied[m.Version]; ok {
+				item.AppliedAt = &at
+			}
+			status = append(status, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// withMigrationLock выполняет fn на одном соединении под advisory lock,
+// передавая список миграций и уже примененные версии.
+func (s *Storage) withMigrationLock(ctx context.Context, fn func(conn *gorm.DB, migrations []migration, applied map[uint]time.Time) error) error {
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// SQLite работает в одном процессе с одним соединением, блокировка не нужна
+		if s.dialect == dialectPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("failed acquire migration lock: %w", err)
+			}
+			defer func() {
+				if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID).Error; err != nil {
+					s.log.Error("failed release migration lock", zap.Error(err))
+				}
+			}()
+		}
+
+		err := conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamp NOT NULL
+		)`).Error
+		if err != nil {
+			return fmt.Errorf("failed create schema_migrations: %w", err)
+		}
+
+		rows := []schemaMigration{}
+		if err := conn.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed get applied migrations: %w", err)
+		}
+		applied := map[uint]time.Time{}
+		for _, r := range rows {
+			applied[r.Version] = r.AppliedAt
+		}
+
+		return fn(conn, migrations, applied)
+	})
+}
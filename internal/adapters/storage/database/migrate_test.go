@@ -0,0 +1,288 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	postgres, err := loadMigrations(dialectPostgres)
+	if err != nil {
+		t.Fatalf("loadMigrations(postgres) error = %v", err)
+	}
+	if len(postgres) == 0 {
+		t.Fatal("loadMigrations(postgres) returned no migrations")
+	}
+	sqlite, err := loadMigrations(dialectSQLite)
+	if err != nil {
+		t.Fatalf("loadMigrations(sqlite) error = %v", err)
+	}
+	if len(sqlite) != len(postgres) {
+		t.Fatalf("sqlite has %d migrations, postgres has %d", len(sqlite), len(postgres))
+	}
+	for i, m := range postgres {
+		if m.Version != uint(i+1) {
+			t.Errorf("migration %d has version %d, versions must go without gaps", i, m.Version)
+		}
+		if m.Name == "" {
+			t.Errorf("migration %04d has empty name", m.Version)
+		}
+		if sqlite[i].Version != m.Version || sqlite[i].Name != m.Name {
+			t.Errorf("sqlite migration %04d_%s differs from postgres %04d_%s",
+				sqlite[i].Version, sqlite[i].Name, m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, "sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	migrations, err := loadMigrations(dialectSQLite)
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+
+	if err := s.Rollback(ctx, len(migrations)); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() after rollback error = %v", err)
+	}
+	status, err := s.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error = %v", err)
+	}
+	for _, m := range status {
+		if m.AppliedAt == nil {
+			t.Errorf("migration %04d_%s is not applied", m.Version, m.Name)
+		}
+	}
+
+	if _, err := s.NewUser(ctx, "Player", "hash"); err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if _, err := s.NewUser(ctx, "player", "hash"); err == nil {
+		t.Error("NewUser() with same login in other case succeeded, want unique violation")
+	}
+	if _, err := s.GetPlayerQuests(ctx, 1); err != nil {
+		t.Errorf("GetPlayerQuests() error = %v", err)
+	}
+
+	// пересоздание user_masters при откате и повторном применении сохраняет ссылки на группу
+	owner, err := s.NewUser(ctx, "owner", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	master, err := s.NewMaster(ctx, &models.UserMaster{UserID: owner.ID, Name: "Класс", UniqueCode: "code"})
+	if err != nil {
+		t.Fatalf("NewMaster() error = %v", err)
+	}
+	if err := s.AddPlayerForMaster(ctx, master.ID, 1); err != nil {
+		t.Fatalf("AddPlayerForMaster() error = %v", err)
+	}
+	if err := s.Rollback(ctx, 1); err != nil {
+		t.Fatalf("Rollback(1) error = %v", err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() after Rollback(1) error = %v", err)
+	}
+	got, err := s.GetMasterByID(ctx, master.ID)
+	if err != nil || len(got.Players) != 1 {
+		t.Errorf("GetMasterByID() = %+v, %v, want master with its player", got, err)
+	}
+	if _, err := s.GetMasterMember(ctx, master.ID, owner.ID); err != nil {
+		t.Errorf("GetMasterMember() owner error = %v", err)
+	}
+	err = s.db.Create(&models.MasterMember{UserMasterID: master.ID + 100, UserID: owner.ID, Role: models.MemberCoMaster}).Error
+	if err == nil {
+		t.Error("Create() member of unknown master succeeded, want foreign key violation")
+	}
+}
+
+// Модели baseline* повторяют схему, которую создавал AutoMigrate до появления миграций.
+type baselineAction struct {
+	gorm.Model
+	Name string
+}
+
+type baselineRole struct {
+	gorm.Model
+	Name string
+}
+
+type baselineUser struct {
+	ID           uint   `gorm:"primarykey"`
+	Login        string `gorm:"index"`
+	PasswordHash string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type baselineUserMaster struct {
+	gorm.Model
+	UserID     uint   `gorm:"unique"`
+	UniqueCode string `gorm:"unique"`
+}
+
+type baselinePlayerWallet struct {
+	gorm.Model
+	UserMasterID uint
+	PlayerID     uint
+	Prise        int
+}
+
+type baselineQuest struct {
+	gorm.Model
+	Title       string
+	Description string
+	Type        string
+	UserID      uint
+	Price       uint
+	StartTime   *time.Time
+	EndTime     *time.Time
+	IsActive    bool
+}
+
+type baselineQuestPlayerStatus struct {
+	gorm.Model
+	PlayerID           uint `gorm:"index:idx_player_id"`
+	QuestID            uint `gorm:"index:idx_quest_id"`
+	RequestExecuteDate *time.Time
+	RejectExecuteDate  *time.Time
+	ConfirmationDate   *time.Time
+	AccrualDate        *time.Time
+}
+
+type baselineJoin struct {
+	table string
+	left  string
+	right string
+}
+
+func (baselineAction) TableName() string            { return "actions" }
+func (baselineRole) TableName() string              { return "roles" }
+func (baselineUser) TableName() string              { return "users" }
+func (baselineUserMaster) TableName() string        { return "user_masters" }
+func (baselinePlayerWallet) TableName() string      { return "player_wallets" }
+func (baselineQuest) TableName() string             { return "quests" }
+func (baselineQuestPlayerStatus) TableName() string { return "quest_player_statuses" }
+
+func TestMigrateBaselineSchema(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(ctx, "sqlite://:memory:", SkipMigrate())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	err = s.db.AutoMigrate(&baselineAction{}, &baselineRole{}, &baselineUser{}, &baselineUserMaster{},
+		&baselinePlayerWallet{}, &baselineQuest{}, &baselineQuestPlayerStatus{})
+	if err != nil {
+		t.Fatalf("AutoMigrate() baseline error = %v", err)
+	}
+	joins := []baselineJoin{
+		{table: "role_actions", left: "role_id", right: "action_id"},
+		{table: "user_roles", left: "user_id", right: "role_id"},
+		{table: "master_players", left: "user_master_id", right: "user_id"},
+		{table: "quest_players", left: "quest_id", right: "user_id"},
+	}
+	for _, j := range joins {
+		err := s.db.Exec("CREATE TABLE " + j.table + " (" + j.left + " integer, " + j.right + " integer, PRIMARY KEY (" + j.left + ", " + j.right + "))").Error
+		if err != nil {
+			t.Fatalf("create %s error = %v", j.table, err)
+		}
+	}
+
+	// данные, которые могли быть в базе до миграций
+	now := time.Now()
+	seed := []any{
+		&baselineAction{Model: gorm.Model{ID: 1}, Name: models.ActionCreateQuest},
+		&baselineRole{Model: gorm.Model{ID: 2}, Name: models.RoleQuestMaster},
+		&baselineUser{ID: 1, Login: "master", PasswordHash: "hash"},
+		&baselineUser{ID: 2, Login: "player", PasswordHash: "hash"},
+		&baselineUserMaster{Model: gorm.Model{ID: 1}, UserID: 1, UniqueCode: "code"},
+		&baselinePlayerWallet{Model: gorm.Model{ID: 1}, UserMasterID: 1, PlayerID: 2, Prise: 15},
+		// второй кошелек того же игрока, созданный гонкой до уникального индекса
+		&baselinePlayerWallet{Model: gorm.Model{ID: 2}, UserMasterID: 1, PlayerID: 2, Prise: 5},
+		&baselineQuest{Model: gorm.Model{ID: 1}, Title: "Зарядка", Type: string(models.Daily), UserID: 1, Price: 5, IsActive: true},
+		&baselineQuestPlayerStatus{Model: gorm.Model{ID: 1}, PlayerID: 2, QuestID: 1, RequestExecuteDate: &now},
+		// повторная отправка, записанная гонкой до уникального индекса
+		&baselineQuestPlayerStatus{Model: gorm.Model{ID: 2}, PlayerID: 2, QuestID: 1, RequestExecuteDate: &now,
+			ConfirmationDate: &now, AccrualDate: &now},
+	}
+	for _, row := range seed {
+		if err := s.db.Create(row).Error; err != nil {
+			t.Fatalf("Create(%T) error = %v", row, err)
+		}
+	}
+	for _, q := range []string{
+		"INSERT INTO role_actions (role_id, action_id) VALUES (2, 1)",
+		"INSERT INTO master_players (user_master_id, user_id) VALUES (1, 2)",
+		"INSERT INTO quest_players (quest_id, user_id) VALUES (1, 2)",
+	} {
+		if err := s.db.Exec(q).Error; err != nil {
+			t.Fatalf("Exec(%s) error = %v", q, err)
+		}
+	}
+
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() on baseline schema error = %v", err)
+	}
+
+	columns := []struct {
+		model  any
+		column string
+	}{
+		{&models.User{}, "time_zone"},
+		{&models.User{}, "active_master_id"},
+		{&models.UserMaster{}, "name"},
+		{&models.PlayerWallet{}, "balance"},
+		{&models.Quest{}, "schedule_week_days"},
+		{&models.Quest{}, "schedule_month_days"},
+		{&models.Quest{}, "schedule_times"},
+		{&models.Quest{}, "penalty"},
+		{&models.Quest{}, "user_master_id"},
+		{&models.QuestPlayerStatus{}, "period_key"},
+		{&models.QuestPlayerStatus{}, "proof_comment"},
+		{&models.QuestPlayerStatus{}, "reject_reason"},
+		{&models.WalletTransaction{}, "quest_penalty_id"},
+	}
+	for _, c := range columns {
+		if !s.db.Migrator().HasColumn(c.model, c.column) {
+			t.Errorf("column %T.%s is missing after Migrate()", c.model, c.column)
+		}
+	}
+
+	wallets, err := s.GetWallets(ctx, 2)
+	if err != nil || len(*wallets) != 1 || (*wallets)[0].ID != 1 || (*wallets)[0].Balance != 20 {
+		t.Errorf("GetWallets() = %+v, %v, want merged wallet 1 with balance 20", wallets, err)
+	}
+	if count, err := s.CountUnbalancedWallets(ctx); err != nil || count != 0 {
+		t.Errorf("CountUnbalancedWallets() = %d, %v, want 0", count, err)
+	}
+	if err := s.db.Create(&models.PlayerWallet{UserMasterID: 1, PlayerID: 2}).Error; err == nil {
+		t.Error("Create() second wallet succeeded, want unique violation")
+	}
+	_, total, err := s.GetWalletTransactions(ctx, 1, 0, 10)
+	if err != nil || total != 2 {
+		t.Errorf("GetWalletTransactions() = %d, %v, want opening balances of both wallets", total, err)
+	}
+	statuses := []models.QuestPlayerStatus{}
+	if err := s.db.Find(&statuses).Error; err != nil || len(statuses) != 1 || statuses[0].ID != 2 {
+		t.Errorf("statuses after Migrate() = %+v, %v, want only the paid one", statuses, err)
+	}
+	quests, err := s.GetPlayerQuests(ctx, 2)
+	if err != nil || len(*quests) != 1 {
+		t.Errorf("GetPlayerQuests() = %+v, %v, want 1 quest", quests, err)
+	}
+	if _, err := s.GetMasterMember(ctx, 1, 1); err != nil {
+		t.Errorf("GetMasterMember() owner error = %v", err)
+	}
+}
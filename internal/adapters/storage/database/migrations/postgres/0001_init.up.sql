@@ -0,0 +1,122 @@
+-- Схема, которую создавал AutoMigrate до появления миграций. IF NOT EXISTS позволяет
+-- применить миграцию к такой базе, остальные таблицы и колонки добавляют следующие миграции.
+
+CREATE TABLE IF NOT EXISTS actions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_actions_deleted_at ON actions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS role_actions (
+    role_id bigint,
+    action_id bigint,
+    PRIMARY KEY (role_id, action_id),
+    CONSTRAINT fk_role_actions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_actions_action FOREIGN KEY (action_id) REFERENCES actions (id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    login text,
+    password_hash text,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_users_login ON users (login);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id bigint,
+    role_id bigint,
+    PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_masters (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id bigint,
+    unique_code text,
+    CONSTRAINT fk_users_quest_master FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uni_user_masters_user_id UNIQUE (user_id),
+    CONSTRAINT uni_user_masters_unique_code UNIQUE (unique_code)
+);
+CREATE INDEX IF NOT EXISTS idx_user_masters_deleted_at ON user_masters (deleted_at);
+
+CREATE TABLE IF NOT EXISTS master_players (
+    user_master_id bigint,
+    user_id bigint,
+    PRIMARY KEY (user_master_id, user_id),
+    CONSTRAINT fk_master_players_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id) ON DELETE CASCADE,
+    CONSTRAINT fk_master_players_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS player_wallets (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_master_id bigint,
+    player_id bigint,
+    prise bigint,
+    CONSTRAINT fk_player_wallets_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id),
+    CONSTRAINT fk_player_wallets_player FOREIGN KEY (player_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_player_wallets_deleted_at ON player_wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quests (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    title text,
+    description text,
+    type text,
+    user_id bigint,
+    price bigint,
+    start_time timestamptz,
+    end_time timestamptz,
+    is_active boolean,
+    CONSTRAINT fk_quests_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_quests_deleted_at ON quests (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quest_players (
+    quest_id bigint,
+    user_id bigint,
+    PRIMARY KEY (quest_id, user_id),
+    CONSTRAINT fk_quest_players_quest FOREIGN KEY (quest_id) REFERENCES quests (id) ON DELETE CASCADE,
+    CONSTRAINT fk_quest_players_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS quest_player_statuses (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    player_id bigint,
+    quest_id bigint,
+    request_execute_date timestamptz,
+    reject_execute_date timestamptz,
+    confirmation_date timestamptz,
+    accrual_date timestamptz,
+    CONSTRAINT fk_quest_player_statuses_player FOREIGN KEY (player_id) REFERENCES users (id),
+    CONSTRAINT fk_quest_player_statuses_quest FOREIGN KEY (quest_id) REFERENCES quests (id)
+);
+CREATE INDEX IF NOT EXISTS idx_player_id ON quest_player_statuses (player_id);
+CREATE INDEX IF NOT EXISTS idx_quest_id ON quest_player_statuses (quest_id);
+CREATE INDEX IF NOT EXISTS idx_quest_player_statuses_deleted_at ON quest_player_statuses (deleted_at);
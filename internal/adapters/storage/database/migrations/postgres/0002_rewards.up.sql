@@ -0,0 +1,36 @@
+-- Награды мастера и их покупки игроками.
+
+CREATE TABLE IF NOT EXISTS rewards (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_master_id bigint,
+    title text,
+    description text,
+    cost bigint,
+    stock bigint,
+    is_active boolean,
+    CONSTRAINT fk_rewards_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id)
+);
+CREATE INDEX IF NOT EXISTS idx_reward_master_id ON rewards (user_master_id);
+CREATE INDEX IF NOT EXISTS idx_rewards_deleted_at ON rewards (deleted_at);
+
+CREATE TABLE IF NOT EXISTS reward_purchases (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    reward_id bigint,
+    player_id bigint,
+    user_master_id bigint,
+    cost bigint,
+    status text,
+    closed_date timestamptz,
+    CONSTRAINT fk_reward_purchases_reward FOREIGN KEY (reward_id) REFERENCES rewards (id),
+    CONSTRAINT fk_reward_purchases_player FOREIGN KEY (player_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_purchase_reward_id ON reward_purchases (reward_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_player_id ON reward_purchases (player_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_master_id ON reward_purchases (user_master_id);
+CREATE INDEX IF NOT EXISTS idx_reward_purchases_deleted_at ON reward_purchases (deleted_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_wallet_master_player;
+DROP TABLE IF EXISTS wallet_transactions;
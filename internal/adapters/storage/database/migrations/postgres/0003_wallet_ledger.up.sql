@@ -0,0 +1,26 @@
+-- Журнал операций по кошелькам.
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    wallet_id bigint,
+    user_master_id bigint,
+    player_id bigint,
+    type text,
+    amount bigint,
+    balance bigint,
+    comment text,
+    author_id bigint,
+    quest_player_status_id bigint,
+    reward_purchase_id bigint,
+    reversal_of_id bigint,
+    CONSTRAINT fk_wallet_transactions_wallet FOREIGN KEY (wallet_id) REFERENCES player_wallets (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_wallet_id ON wallet_transactions (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_player_id ON wallet_transactions (player_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_reversal_of_id ON wallet_transactions (reversal_of_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_deleted_at ON wallet_transactions (deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_master_player ON player_wallets (user_master_id, player_id);
@@ -0,0 +1,2 @@
+ALTER TABLE quest_player_statuses DROP COLUMN IF EXISTS period_key;
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
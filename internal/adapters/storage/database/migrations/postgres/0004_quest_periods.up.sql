@@ -0,0 +1,4 @@
+-- Часовой пояс игрока и период, за который отправлено выполнение.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS time_zone text NOT NULL DEFAULT 'UTC';
+ALTER TABLE quest_player_statuses ADD COLUMN IF NOT EXISTS period_key text NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE quests DROP COLUMN IF EXISTS schedule_times;
+ALTER TABLE quests DROP COLUMN IF EXISTS schedule_month_days;
+ALTER TABLE quests DROP COLUMN IF EXISTS schedule_week_days;
@@ -0,0 +1,5 @@
+-- Расписание квестов по дням недели, дням месяца и числу раз в неделю.
+
+ALTER TABLE quests ADD COLUMN IF NOT EXISTS schedule_week_days text;
+ALTER TABLE quests ADD COLUMN IF NOT EXISTS schedule_month_days text;
+ALTER TABLE quests ADD COLUMN IF NOT EXISTS schedule_times bigint;
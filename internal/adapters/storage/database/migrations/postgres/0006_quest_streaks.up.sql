@@ -0,0 +1,28 @@
+-- Серии выполнений и бонусы за них.
+
+CREATE TABLE IF NOT EXISTS quest_streak_bonus (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    quest_id bigint,
+    days bigint,
+    bonus bigint,
+    CONSTRAINT fk_quests_streak_bonuses FOREIGN KEY (quest_id) REFERENCES quests (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_streak_bonus_quest_id ON quest_streak_bonus (quest_id);
+CREATE INDEX IF NOT EXISTS idx_quest_streak_bonus_deleted_at ON quest_streak_bonus (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quest_streaks (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    quest_id bigint,
+    player_id bigint,
+    current bigint,
+    best bigint,
+    last_period text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_streak_quest_player ON quest_streaks (quest_id, player_id);
+CREATE INDEX IF NOT EXISTS idx_quest_streaks_deleted_at ON quest_streaks (deleted_at);
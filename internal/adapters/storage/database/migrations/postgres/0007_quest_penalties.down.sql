@@ -0,0 +1,3 @@
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS quest_penalty_id;
+DROP TABLE IF EXISTS quest_penalties;
+ALTER TABLE quests DROP COLUMN IF EXISTS penalty;
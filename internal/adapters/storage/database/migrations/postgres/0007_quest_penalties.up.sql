@@ -0,0 +1,22 @@
+-- Штрафы за пропуск и отклонение квеста.
+
+ALTER TABLE quests ADD COLUMN IF NOT EXISTS penalty bigint;
+
+CREATE TABLE IF NOT EXISTS quest_penalties (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    quest_id bigint,
+    player_id bigint,
+    user_master_id bigint,
+    period_key text,
+    reason text,
+    amount bigint,
+    quest_player_status_id bigint,
+    CONSTRAINT fk_quest_penalties_quest FOREIGN KEY (quest_id) REFERENCES quests (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_penalty_missed ON quest_penalties (quest_id, player_id, period_key) WHERE reason = 'missed';
+CREATE INDEX IF NOT EXISTS idx_quest_penalties_deleted_at ON quest_penalties (deleted_at);
+
+ALTER TABLE wallet_transactions ADD COLUMN IF NOT EXISTS quest_penalty_id bigint;
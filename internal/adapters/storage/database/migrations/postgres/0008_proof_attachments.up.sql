@@ -0,0 +1,18 @@
+-- Комментарий и вложения к отправке выполнения.
+
+ALTER TABLE quest_player_statuses ADD COLUMN IF NOT EXISTS proof_comment text;
+
+CREATE TABLE IF NOT EXISTS proof_attachments (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    quest_player_status_id bigint,
+    file_name text,
+    content_type text,
+    size bigint,
+    blob_key text,
+    CONSTRAINT fk_quest_player_statuses_attachments FOREIGN KEY (quest_player_status_id) REFERENCES quest_player_statuses (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_attachment_status_id ON proof_attachments (quest_player_status_id);
+CREATE INDEX IF NOT EXISTS idx_proof_attachments_deleted_at ON proof_attachments (deleted_at);
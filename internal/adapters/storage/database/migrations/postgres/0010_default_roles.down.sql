@@ -0,0 +1,4 @@
+DELETE FROM user_roles WHERE role_id IN (1, 2);
+DELETE FROM role_actions WHERE role_id IN (1, 2);
+DELETE FROM roles WHERE id IN (1, 2);
+DELETE FROM actions WHERE id = 1;
@@ -0,0 +1,18 @@
+-- Роли и действия по умолчанию, идентификаторы совпадают с models.RoleQuestMasterObject.
+
+INSERT INTO actions (id, created_at, updated_at, name)
+VALUES (1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'create_quest')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO roles (id, created_at, updated_at, name)
+VALUES
+    (1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'admin'),
+    (2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'quest_master')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO role_actions (role_id, action_id)
+VALUES (2, 1)
+ON CONFLICT DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('actions', 'id'), (SELECT MAX(id) FROM actions));
+SELECT setval(pg_get_serial_sequence('roles', 'id'), (SELECT MAX(id) FROM roles));
@@ -0,0 +1,3 @@
+-- Начальные балансы неотличимы от ручных корректировок с тем же комментарием,
+-- поэтому откат оставляет журнал как есть.
+SELECT 1;
@@ -0,0 +1,21 @@
+-- Логины уникальны без учета регистра. Если в базе уже есть дубли, миграция
+-- останавливается и перечисляет их: дубли нужно исправить вручную.
+
+DO $$
+DECLARE
+    duplicates text;
+BEGIN
+    SELECT STRING_AGG(d.login || ' (' || d.user_ids || ')', ', ')
+    INTO duplicates
+    FROM (
+        SELECT LOWER(login) AS login, STRING_AGG(CAST(id AS text), ',' ORDER BY id) AS user_ids
+        FROM users
+        GROUP BY LOWER(login)
+        HAVING COUNT(*) > 1
+    ) d;
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'duplicate logins: %', duplicates;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login_lower ON users (LOWER(login));
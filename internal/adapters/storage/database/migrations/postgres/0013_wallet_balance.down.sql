@@ -0,0 +1 @@
+ALTER TABLE player_wallets RENAME COLUMN balance TO prise;
@@ -0,0 +1,19 @@
+-- Каталог действий для проверки прав, идентификаторы совпадают с models.Permissions.
+-- Админ получает все действия, мастер квестов - управление своей группой.
+
+INSERT INTO actions (id, created_at, updated_at, name)
+VALUES
+    (2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'confirm_submission'),
+    (3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'manage_players'),
+    (4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'manage_rewards'),
+    (5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'manage_wallets'),
+    (6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, 'admin_users')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO role_actions (role_id, action_id)
+VALUES
+    (1, 1), (1, 2), (1, 3), (1, 4), (1, 5), (1, 6),
+    (2, 2), (2, 3), (2, 4), (2, 5)
+ON CONFLICT DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('actions', 'id'), (SELECT MAX(id) FROM actions));
@@ -0,0 +1,6 @@
+-- Откат возможен, пока у каждого пользователя не больше одной группы.
+
+ALTER TABLE users DROP COLUMN IF EXISTS active_master_id;
+ALTER TABLE user_masters DROP COLUMN IF EXISTS name;
+DROP INDEX IF EXISTS idx_user_master_user_id;
+ALTER TABLE user_masters ADD CONSTRAINT uni_user_masters_user_id UNIQUE (user_id);
@@ -0,0 +1,9 @@
+-- У пользователя может быть несколько групп мастера с названиями.
+-- Группа, которой пользователь управляет сейчас, хранится у пользователя.
+
+ALTER TABLE user_masters DROP CONSTRAINT IF EXISTS uni_user_masters_user_id;
+CREATE INDEX IF NOT EXISTS idx_user_master_user_id ON user_masters (user_id);
+ALTER TABLE user_masters ADD COLUMN IF NOT EXISTS name text NOT NULL DEFAULT '';
+UPDATE user_masters SET name = 'Основная группа' WHERE name = '';
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS active_master_id bigint REFERENCES user_masters (id) ON DELETE SET NULL;
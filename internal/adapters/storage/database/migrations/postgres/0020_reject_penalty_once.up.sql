@@ -0,0 +1,3 @@
+-- Штраф за отклонение списывается с отправки один раз.
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_penalty_rejected ON quest_penalties (quest_player_status_id) WHERE reason = 'rejected';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_wallet_master_player;
+CREATE INDEX idx_wallet_master_player ON player_wallets (user_master_id, player_id);
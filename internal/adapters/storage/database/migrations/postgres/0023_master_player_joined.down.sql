@@ -0,0 +1 @@
+ALTER TABLE master_players DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,3 @@
+-- После сброса пароля админом пользователь должен сменить временный пароль при входе.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password boolean NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS quest_player_statuses;
+DROP TABLE IF EXISTS quest_players;
+DROP TABLE IF EXISTS quests;
+DROP TABLE IF EXISTS player_wallets;
+DROP TABLE IF EXISTS master_players;
+DROP TABLE IF EXISTS user_masters;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS role_actions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS actions;
@@ -0,0 +1,122 @@
+-- Схема, которую создавал AutoMigrate до появления миграций. IF NOT EXISTS позволяет
+-- применить миграцию к такой базе, остальные таблицы и колонки добавляют следующие миграции.
+
+CREATE TABLE IF NOT EXISTS actions (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_actions_deleted_at ON actions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS roles (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_roles_deleted_at ON roles (deleted_at);
+
+CREATE TABLE IF NOT EXISTS role_actions (
+    role_id integer,
+    action_id integer,
+    PRIMARY KEY (role_id, action_id),
+    CONSTRAINT fk_role_actions_role FOREIGN KEY (role_id) REFERENCES roles (id),
+    CONSTRAINT fk_role_actions_action FOREIGN KEY (action_id) REFERENCES actions (id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    login text,
+    password_hash text,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_users_login ON users (login);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id integer,
+    role_id integer,
+    PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id)
+);
+
+CREATE TABLE IF NOT EXISTS user_masters (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id integer,
+    unique_code text,
+    CONSTRAINT fk_users_quest_master FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uni_user_masters_user_id UNIQUE (user_id),
+    CONSTRAINT uni_user_masters_unique_code UNIQUE (unique_code)
+);
+CREATE INDEX IF NOT EXISTS idx_user_masters_deleted_at ON user_masters (deleted_at);
+
+CREATE TABLE IF NOT EXISTS master_players (
+    user_master_id integer,
+    user_id integer,
+    PRIMARY KEY (user_master_id, user_id),
+    CONSTRAINT fk_master_players_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id) ON DELETE CASCADE,
+    CONSTRAINT fk_master_players_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS player_wallets (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_master_id integer,
+    player_id integer,
+    prise integer,
+    CONSTRAINT fk_player_wallets_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id),
+    CONSTRAINT fk_player_wallets_player FOREIGN KEY (player_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_player_wallets_deleted_at ON player_wallets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quests (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    title text,
+    description text,
+    type text,
+    user_id integer,
+    price integer,
+    start_time datetime,
+    end_time datetime,
+    is_active boolean,
+    CONSTRAINT fk_quests_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_quests_deleted_at ON quests (deleted_at);
+
+CREATE TABLE IF NOT EXISTS quest_players (
+    quest_id integer,
+    user_id integer,
+    PRIMARY KEY (quest_id, user_id),
+    CONSTRAINT fk_quest_players_quest FOREIGN KEY (quest_id) REFERENCES quests (id) ON DELETE CASCADE,
+    CONSTRAINT fk_quest_players_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS quest_player_statuses (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    player_id integer,
+    quest_id integer,
+    request_execute_date datetime,
+    reject_execute_date datetime,
+    confirmation_date datetime,
+    accrual_date datetime,
+    CONSTRAINT fk_quest_player_statuses_player FOREIGN KEY (player_id) REFERENCES users (id),
+    CONSTRAINT fk_quest_player_statuses_quest FOREIGN KEY (quest_id) REFERENCES quests (id)
+);
+CREATE INDEX IF NOT EXISTS idx_player_id ON quest_player_statuses (player_id);
+CREATE INDEX IF NOT EXISTS idx_quest_id ON quest_player_statuses (quest_id);
+CREATE INDEX IF NOT EXISTS idx_quest_player_statuses_deleted_at ON quest_player_statuses (deleted_at);
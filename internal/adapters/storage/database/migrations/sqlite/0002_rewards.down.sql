@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reward_purchases;
+DROP TABLE IF EXISTS rewards;
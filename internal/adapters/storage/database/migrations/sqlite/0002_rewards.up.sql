@@ -0,0 +1,36 @@
+-- Награды мастера и их покупки игроками.
+
+CREATE TABLE IF NOT EXISTS rewards (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_master_id integer,
+    title text,
+    description text,
+    cost integer,
+    stock integer,
+    is_active boolean,
+    CONSTRAINT fk_rewards_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id)
+);
+CREATE INDEX IF NOT EXISTS idx_reward_master_id ON rewards (user_master_id);
+CREATE INDEX IF NOT EXISTS idx_rewards_deleted_at ON rewards (deleted_at);
+
+CREATE TABLE IF NOT EXISTS reward_purchases (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    reward_id integer,
+    player_id integer,
+    user_master_id integer,
+    cost integer,
+    status text,
+    closed_date datetime,
+    CONSTRAINT fk_reward_purchases_reward FOREIGN KEY (reward_id) REFERENCES rewards (id),
+    CONSTRAINT fk_reward_purchases_player FOREIGN KEY (player_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_purchase_reward_id ON reward_purchases (reward_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_player_id ON reward_purchases (player_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_master_id ON reward_purchases (user_master_id);
+CREATE INDEX IF NOT EXISTS idx_reward_purchases_deleted_at ON reward_purchases (deleted_at);
@@ -0,0 +1,26 @@
+-- Журнал операций по кошелькам.
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    wallet_id integer,
+    user_master_id integer,
+    player_id integer,
+    type text,
+    amount integer,
+    balance integer,
+    comment text,
+    author_id integer,
+    quest_player_status_id integer,
+    reward_purchase_id integer,
+    reversal_of_id integer,
+    CONSTRAINT fk_wallet_transactions_wallet FOREIGN KEY (wallet_id) REFERENCES player_wallets (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_wallet_id ON wallet_transactions (wallet_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_player_id ON wallet_transactions (player_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_reversal_of_id ON wallet_transactions (reversal_of_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_deleted_at ON wallet_transactions (deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_master_player ON player_wallets (user_master_id, player_id);
@@ -0,0 +1,2 @@
+ALTER TABLE quest_player_statuses DROP COLUMN period_key;
+ALTER TABLE users DROP COLUMN time_zone;
@@ -0,0 +1,3 @@
+ALTER TABLE quests DROP COLUMN schedule_times;
+ALTER TABLE quests DROP COLUMN schedule_month_days;
+ALTER TABLE quests DROP COLUMN schedule_week_days;
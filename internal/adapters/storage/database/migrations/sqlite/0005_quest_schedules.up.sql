@@ -0,0 +1,5 @@
+-- Расписание квестов по дням недели, дням месяца и числу раз в неделю.
+
+ALTER TABLE quests ADD COLUMN schedule_week_days text;
+ALTER TABLE quests ADD COLUMN schedule_month_days text;
+ALTER TABLE quests ADD COLUMN schedule_times integer;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quest_streaks;
+DROP TABLE IF EXISTS quest_streak_bonus;
@@ -0,0 +1,3 @@
+ALTER TABLE wallet_transactions DROP COLUMN quest_penalty_id;
+DROP TABLE IF EXISTS quest_penalties;
+ALTER TABLE quests DROP COLUMN penalty;
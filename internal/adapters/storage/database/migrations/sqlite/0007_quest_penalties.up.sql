@@ -0,0 +1,22 @@
+-- Штрафы за пропуск и отклонение квеста.
+
+ALTER TABLE quests ADD COLUMN penalty integer;
+
+CREATE TABLE IF NOT EXISTS quest_penalties (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    quest_id integer,
+    player_id integer,
+    user_master_id integer,
+    period_key text,
+    reason text,
+    amount integer,
+    quest_player_status_id integer,
+    CONSTRAINT fk_quest_penalties_quest FOREIGN KEY (quest_id) REFERENCES quests (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_penalty_missed ON quest_penalties (quest_id, player_id, period_key) WHERE reason = 'missed';
+CREATE INDEX IF NOT EXISTS idx_quest_penalties_deleted_at ON quest_penalties (deleted_at);
+
+ALTER TABLE wallet_transactions ADD COLUMN quest_penalty_id integer;
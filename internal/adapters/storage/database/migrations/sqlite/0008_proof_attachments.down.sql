@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS proof_attachments;
+ALTER TABLE quest_player_statuses DROP COLUMN proof_comment;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quest_comments;
+ALTER TABLE quest_player_statuses DROP COLUMN reject_reason;
@@ -0,0 +1,17 @@
+-- Причина отклонения и обсуждение отправки.
+
+ALTER TABLE quest_player_statuses ADD COLUMN reject_reason text;
+
+CREATE TABLE IF NOT EXISTS quest_comments (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    quest_player_status_id integer,
+    author_id integer,
+    text text,
+    CONSTRAINT fk_quest_comments_author FOREIGN KEY (author_id) REFERENCES users (id),
+    CONSTRAINT fk_quest_player_statuses_comments FOREIGN KEY (quest_player_status_id) REFERENCES quest_player_statuses (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_comment_status_id ON quest_comments (quest_player_status_id);
+CREATE INDEX IF NOT EXISTS idx_quest_comments_deleted_at ON quest_comments (deleted_at);
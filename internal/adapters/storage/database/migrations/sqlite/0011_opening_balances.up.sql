@@ -0,0 +1,9 @@
+-- Кошельки, созданные до журнала операций, получают запись с начальным балансом,
+-- чтобы баланс оставался равен сумме журнала.
+
+INSERT INTO wallet_transactions
+    (created_at, updated_at, wallet_id, user_master_id, player_id, type, amount, balance, comment)
+SELECT CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, w.id, w.user_master_id, w.player_id, 'adjustment', w.prise, w.prise, 'opening balance'
+FROM player_wallets w
+WHERE w.prise <> 0 AND w.deleted_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM wallet_transactions t WHERE t.wallet_id = w.id);
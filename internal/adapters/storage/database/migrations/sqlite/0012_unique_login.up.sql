@@ -0,0 +1,4 @@
+-- Логины уникальны без учета регистра. База SQLite создается уже с проверкой
+-- логина при регистрации, поэтому дублей в ней нет.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login_lower ON users (LOWER(login));
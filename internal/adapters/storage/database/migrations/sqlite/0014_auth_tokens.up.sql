@@ -0,0 +1,25 @@
+-- Токены обновления сессий, отозванные токены доступа и версия токенов пользователя
+-- для выхода со всех устройств.
+
+ALTER TABLE users ADD COLUMN token_version integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id integer NOT NULL,
+    token_hash text NOT NULL,
+    expires_at datetime NOT NULL,
+    revoked_at datetime,
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_refresh_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_token_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti text PRIMARY KEY,
+    expires_at datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_token_expires_at ON revoked_tokens (expires_at);
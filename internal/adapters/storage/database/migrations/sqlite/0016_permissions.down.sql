@@ -0,0 +1,2 @@
+DELETE FROM role_actions WHERE role_id = 1 OR action_id IN (2, 3, 4, 5, 6);
+DELETE FROM actions WHERE id IN (2, 3, 4, 5, 6);
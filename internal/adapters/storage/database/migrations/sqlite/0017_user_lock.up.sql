@@ -0,0 +1,3 @@
+-- Блокировка учетной записи админом.
+
+ALTER TABLE users ADD COLUMN locked_at datetime;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_quest_master_id;
+ALTER TABLE quests DROP COLUMN user_master_id;
+DROP TABLE IF EXISTS master_invites;
+DROP TABLE IF EXISTS master_members;
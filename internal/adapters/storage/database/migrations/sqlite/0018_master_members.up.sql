@@ -0,0 +1,43 @@
+-- Группой мастера управляют несколько пользователей: владелец, со-мастера и проверяющие.
+-- Квест принадлежит группе, user_id остается автором. Владельцы существующих групп
+-- становятся участниками с ролью owner.
+
+CREATE TABLE IF NOT EXISTS master_members (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_master_id integer NOT NULL,
+    user_id integer NOT NULL,
+    role text NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    CONSTRAINT fk_master_members_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id) ON DELETE CASCADE,
+    CONSTRAINT fk_master_members_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_master_member ON master_members (user_master_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_master_member_user_id ON master_members (user_id);
+
+INSERT INTO master_members (user_master_id, user_id, role, created_at, updated_at)
+SELECT id, user_id, 'owner', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+FROM user_masters
+WHERE deleted_at IS NULL AND user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS master_invites (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_master_id integer NOT NULL,
+    code text NOT NULL,
+    role text NOT NULL,
+    author_id integer NOT NULL,
+    expires_at datetime NOT NULL,
+    accepted_by_id integer,
+    accepted_at datetime,
+    created_at datetime,
+    CONSTRAINT fk_master_invites_user_master FOREIGN KEY (user_master_id) REFERENCES user_masters (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_master_invite_code ON master_invites (code);
+CREATE INDEX IF NOT EXISTS idx_master_invite_master_id ON master_invites (user_master_id);
+
+-- без внешнего ключа: SQLite не удаляет колонку со ссылкой при откате
+ALTER TABLE quests ADD COLUMN user_master_id integer;
+UPDATE quests SET user_master_id = (SELECT um.id FROM user_masters um WHERE um.user_id = quests.user_id)
+WHERE user_master_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_quest_master_id ON quests (user_master_id);
@@ -0,0 +1,20 @@
+-- Откат возможен, пока у каждого пользователя не больше одной группы.
+
+ALTER TABLE users DROP COLUMN active_master_id;
+
+CREATE TABLE user_masters_old (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id integer,
+    unique_code text,
+    CONSTRAINT fk_users_quest_master FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uni_user_masters_user_id UNIQUE (user_id),
+    CONSTRAINT uni_user_masters_unique_code UNIQUE (unique_code)
+);
+INSERT INTO user_masters_old (id, created_at, updated_at, deleted_at, user_id, unique_code)
+SELECT id, created_at, updated_at, deleted_at, user_id, unique_code FROM user_masters;
+DROP TABLE user_masters;
+ALTER TABLE user_masters_old RENAME TO user_masters;
+CREATE INDEX IF NOT EXISTS idx_user_masters_deleted_at ON user_masters (deleted_at);
@@ -0,0 +1,24 @@
+-- У пользователя может быть несколько групп мастера с названиями.
+-- Группа, которой пользователь управляет сейчас, хранится у пользователя.
+-- SQLite снимает ограничение уникальности только пересозданием таблицы.
+
+CREATE TABLE user_masters_new (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id integer,
+    name text NOT NULL DEFAULT '',
+    unique_code text,
+    CONSTRAINT fk_users_quest_master FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uni_user_masters_unique_code UNIQUE (unique_code)
+);
+INSERT INTO user_masters_new (id, created_at, updated_at, deleted_at, user_id, name, unique_code)
+SELECT id, created_at, updated_at, deleted_at, user_id, 'Основная группа', unique_code FROM user_masters;
+DROP TABLE user_masters;
+ALTER TABLE user_masters_new RENAME TO user_masters;
+CREATE INDEX IF NOT EXISTS idx_user_masters_deleted_at ON user_masters (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_master_user_id ON user_masters (user_id);
+
+-- без внешнего ключа: SQLite не удаляет колонку со ссылкой при откате
+ALTER TABLE users ADD COLUMN active_master_id integer;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS uq_status_quest_player_period;
@@ -0,0 +1,15 @@
+-- Игрок отправляет квест один раз за период. Повторные отправки, которые успели записаться
+-- до появления индекса, удаляются мягко: остается оплаченная, иначе самая ранняя.
+
+UPDATE quest_player_statuses SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND id <> (
+    SELECT k.id FROM quest_player_statuses k
+    WHERE k.quest_id = quest_player_statuses.quest_id
+        AND k.player_id = quest_player_statuses.player_id
+        AND k.period_key = quest_player_statuses.period_key
+        AND k.deleted_at IS NULL
+    ORDER BY k.accrual_date IS NULL, k.id
+    LIMIT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_status_quest_player_period ON quest_player_statuses (quest_id, player_id, period_key) WHERE deleted_at IS NULL;
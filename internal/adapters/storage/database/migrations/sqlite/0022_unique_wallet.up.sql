@@ -0,0 +1,41 @@
+-- У игрока один кошелек в группе мастера. Кошельки-дубли, созданные одновременными
+-- первыми начислениями, сливаются в самый ранний: записи журнала переносятся на него,
+-- баланс складывается, дубль удаляется мягко.
+
+UPDATE player_wallets SET balance = balance + (
+    SELECT COALESCE(SUM(d.balance), 0) FROM player_wallets d
+    WHERE d.user_master_id = player_wallets.user_master_id
+        AND d.player_id = player_wallets.player_id
+        AND d.deleted_at IS NULL
+        AND d.id > player_wallets.id
+)
+WHERE deleted_at IS NULL AND id = (
+    SELECT min(k.id) FROM player_wallets k
+    WHERE k.user_master_id = player_wallets.user_master_id
+        AND k.player_id = player_wallets.player_id
+        AND k.deleted_at IS NULL
+);
+
+UPDATE wallet_transactions SET wallet_id = (
+    SELECT min(k.id) FROM player_wallets k
+    JOIN player_wallets w ON w.user_master_id = k.user_master_id AND w.player_id = k.player_id
+    WHERE w.id = wallet_transactions.wallet_id AND k.deleted_at IS NULL
+)
+WHERE wallet_id IN (
+    SELECT w.id FROM player_wallets w
+    WHERE w.deleted_at IS NULL AND w.id > (
+        SELECT min(k.id) FROM player_wallets k
+        WHERE k.user_master_id = w.user_master_id AND k.player_id = w.player_id AND k.deleted_at IS NULL
+    )
+);
+
+UPDATE player_wallets SET deleted_at = CURRENT_TIMESTAMP
+WHERE deleted_at IS NULL AND id > (
+    SELECT min(k.id) FROM player_wallets k
+    WHERE k.user_master_id = player_wallets.user_master_id
+        AND k.player_id = player_wallets.player_id
+        AND k.deleted_at IS NULL
+);
+
+DROP INDEX IF EXISTS idx_wallet_master_player;
+CREATE UNIQUE INDEX idx_wallet_master_player ON player_wallets (user_master_id, player_id) WHERE deleted_at IS NULL;
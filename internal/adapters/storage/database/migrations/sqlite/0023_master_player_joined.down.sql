@@ -0,0 +1 @@
+ALTER TABLE master_players DROP COLUMN created_at;
@@ -0,0 +1,4 @@
+-- Время вступления игрока в группу: за периоды до него штраф за пропуск не списывается.
+-- У записей, созданных раньше, время неизвестно и остается пустым.
+
+ALTER TABLE master_players ADD COLUMN created_at datetime;
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// GetPenaltyQuests - активные повторяющиеся квесты со штрафом за пропуск.
+func (s *Storage) GetPenaltyQuests(ctx context.Context) (*[]models.Quest, error) {
+	quests := []models.Quest{}
+	err := s.db.WithContext(ctx).
+		Where("is_active = true and penalty > 0 and type <> ?", models.OneTime).
+		Preload("Players").
+		Preload("UserMaster").Preload("UserMaster.Players").
+		Find(&quests).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get penalty quests: %w", err)
+	}
+	return &quests, nil
+}
+
+// FindQuestStatuses - отправки квеста игроком в периодах, ключ которых подходит под шаблон LIKE.
+func (s *Storage) FindQuestStatuses(ctx context.Context, questID, playerID uint, periodPattern string) (*[]models.QuestPlayerStatus, error) {
+	statuses := []models.QuestPlayerStatus{}
+	err := s.db.WithContext(ctx).
+		Where("quest_id = ? and player_id = ? and period_key like ?", questID, playerID, periodPattern).
+		Order("period_key").
+		Find(&statuses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed find quest statuses: %w", err)
+	}
+	return &statuses, nil
+}
+
+// ChargePenalty записывает штраф и списывает его с кошелька игрока, баланс может уйти в минус.
+// Возвращает false, если штраф за этот пропуск уже был списан.
+func (s *Storage) ChargePenalty(ctx context.Context, penalty *models.QuestPenalty, comment string) (bool, error) {
+	charged := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		charged, err = chargePenalty(tx, penalty, comment)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed charge penalty: %w", err)
+	}
+	return charged, nil
+}
+
+// chargePenalty записывает штраф и списывает его с кошелька в транзакции tx.
+func chargePenalty(tx *gorm.DB, penalty *models.QuestPenalty, comment string) (bool, error) {
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(penalty)
+	if res.Error != nil {
+		return false, fmt.Errorf("failed create penalty: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	penaltyID := penalty.ID
+	err := postTransaction(tx, &models.WalletTransaction{
+		UserMasterID:        penalty.UserMasterID,
+		PlayerID:            penalty.PlayerID,
+		Type:                models.TransactionPenalty,
+		Amount:              -int(penalty.Amount),
+		Comment:             comment,
+		QuestPlayerStatusID: penalty.QuestPlayerStatusID,
+		QuestPenaltyID:      &penaltyID,
+	}, true)
+	if err != nil {
+		return false, fmt.Errorf("failed debit wallet: %w", err)
+	}
+	return true, nil
+}
@@ -5,67 +5,39 @@ import (
 	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/mod-develop/backend/internal/models"
 )
 
+// playerQuests отбирает активные квесты мастеров игрока, которые ему доступны:
+// квест без списка игроков или с игроком в списке, еще не выполненный разовый квест.
+func (s *Storage) playerQuests(ctx context.Context, playerID uint) *gorm.DB {
+	now := time.Now().UTC()
+	return s.db.WithContext(ctx).Model(&models.Quest{}).
+		Joins("join master_players mp on mp.user_master_id = quests.user_master_id and mp.user_id = ?", playerID).
+		Where("not exists (select 1 from quest_players qp where qp.quest_id = quests.id) or "+
+			"exists (select 1 from quest_players qp where qp.quest_id = quests.id and qp.user_id = ?)", playerID).
+		Where("quests.type <> ? or not exists (select 1 from quest_player_statuses qps "+
+			"where qps.quest_id = quests.id and qps.player_id = ? and qps.accrual_date is not null)", models.OneTime, playerID).
+		Where("quests.start_time is null or quests.start_time <= ?", now).
+		Where("quests.end_time is null or quests.end_time >= ?", now).
+		Where("quests.is_active = ?", true)
+}
+
 func (s *Storage) GetPlayerQuests(ctx context.Context, playerID uint) (*[]models.Quest, error) {
 	quests := []models.Quest{}
-	err := s.db.WithContext(ctx).Raw("? UNION ?",
-		s.db.Joins("left join quest_players qp on qp.quest_id = quests.id").
-			Joins("join master_players mp on mp.user_id = ? and mp.user_id = qp.user_id ", playerID).
-			Joins("join user_masters um on um.id = mp.user_master_id and um.user_id = quests.user_id ").
-			Joins("left join quest_player_statuses qps on qps.quest_id = quests.id and qps.player_id = mp.user_id").
-			Where("start_time is null or start_time <= ?", time.Now().UTC()).
-			Where("end_time is null or end_time >= ?", time.Now().UTC()).
-			Where("quests.is_active = true").
-			Where("qps.accrual_date is NULL").
-			Group("quests.id").
-			Find(&[]models.Quest{}),
-		s.db.Joins("left join quest_players qp on qp.quest_id = quests.id").
-			Joins("join user_masters um on um.user_id = quests.user_id").
-			Joins("join master_players mp on mp.user_master_id = um.id  and mp.user_id = ?", playerID).
-			Joins("left join quest_player_statuses qps on qps.quest_id = quests.id and qps.player_id = mp.user_id").
-			Where("start_time is null or start_time <= ?", time.Now().UTC()).
-			Where("end_time is null or end_time >= ?", time.Now().UTC()).
-			Where("quests.is_active = true").
-			Where("qp.user_id is NULL").
-			Where("qps.accrual_date is NULL").
-			Group("quests.id").
-			Find(&[]models.Quest{}),
-	).Order("updated_at desc").Find(&quests).Error
+	err := s.playerQuests(ctx, playerID).Order("quests.updated_at desc").Find(&quests).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed find quests: %w", err)
 	}
-	fmt.Println(time.Now().UTC())
 
 	return &quests, nil
 }
 
 func (s *Storage) GetPlayerQuest(ctx context.Context, questID, playerID uint) (*models.Quest, error) {
 	quest := models.Quest{}
-	err := s.db.WithContext(ctx).Raw("? UNION ?",
-		s.db.Joins("left join quest_players qp on qp.quest_id = quests.id").
-			Joins("join master_players mp on mp.user_id = ? and mp.user_id = qp.user_id ", playerID).
-			Joins("join user_masters um on um.id = mp.user_master_id and um.user_id = quests.user_id ").
-			Joins("left join quest_player_statuses qps on qps.quest_id = quests.id and qps.player_id = mp.user_id").
-			Where("start_time is null or start_time <= ?", time.Now().UTC()).
-			Where("end_time is null or end_time >= ?", time.Now().UTC()).
-			Where("quests.is_active = true and quests.id = ?", questID).
-			Where("qps.accrual_date is NULL").
-			Group("quests.id").
-			Find(&models.Quest{}),
-		s.db.Joins("left join quest_players qp on qp.quest_id = quests.id").
-			Joins("join user_masters um on um.user_id = quests.user_id").
-			Joins("join master_players mp on mp.user_master_id = um.id and mp.user_id = ?", playerID).
-			Joins("left join quest_player_statuses qps on qps.quest_id = quests.id and qps.player_id = mp.user_id").
-			Where("start_time is null or start_time <= ?", time.Now().UTC()).
-			Where("end_time is null or end_time >= ?", time.Now().UTC()).
-			Where("quests.is_active = true and quests.id = ?", questID).
-			Where("qp.user_id is NULL").
-			Where("qps.accrual_date is NULL").
-			Group("quests.id").
-			Find(&models.Quest{}),
-	).First(&quest).Error
+	err := s.playerQuests(ctx, playerID).Where("quests.id = ?", questID).First(&quest).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed find quest by player: %w", err)
 	}
@@ -73,10 +45,14 @@ func (s *Storage) GetPlayerQuest(ctx context.Context, questID, playerID uint) (*
 	return &quest, nil
 }
 
-func (s *Storage) GetPlayerQuestStatus(ctx context.Context, questID, playerID uint) (*models.QuestPlayerStatus, error) {
+func (s *Storage) GetPlayerQuestStatus(ctx context.Context, questID, playerID uint, period string) (*models.QuestPlayerStatus, error) {
 	status := &models.QuestPlayerStatus{}
 	err := s.db.WithContext(ctx).
-		Where("quest_id = ? and player_id = ?", questID, playerID).Preload("Quest").First(status).Error
+		Where("quest_id = ? and player_id = ? and period_key = ?", questID, playerID, period).
+		Preload("Quest").Preload("Attachments").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Comments.Author").
+		First(status).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed find quest player status: %w", err)
 	}
@@ -84,19 +60,22 @@ func (s *Storage) GetPlayerQuestStatus(ctx context.Context, questID, playerID ui
 	return status, nil
 }
 
-func (s *Storage) SendPlayerQuest(ctx context.Context, questID, playerID uint) (*models.QuestPlayerStatus, error) {
+func (s *Storage) SendPlayerQuest(ctx context.Context, questID, playerID uint, period, comment string, attachments []models.ProofAttachment) (*models.QuestPlayerStatus, error) {
 	current := time.Now().UTC()
 	status := &models.QuestPlayerStatus{
 		QuestID:            questID,
 		PlayerID:           playerID,
+		PeriodKey:          period,
 		RequestExecuteDate: &current,
+		ProofComment:       comment,
+		Attachments:        attachments,
 	}
 	err := s.db.WithContext(ctx).Save(status).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed send quest status: %w", err)
 	}
 
-	status, err = s.GetPlayerQuestStatus(ctx, questID, playerID)
+	status, err = s.GetPlayerQuestStatus(ctx, questID, playerID, period)
 	if err != nil {
 		return nil, fmt.Errorf("failed get quest status: %w", err)
 	}
@@ -104,16 +83,49 @@ func (s *Storage) SendPlayerQuest(ctx context.Context, questID, playerID uint) (
 	return status, nil
 }
 
+// UpdSendStatusPlayerQuest повторно отправляет квест на проверку.
+// Подтверждение прошлой отправки заменяется новым.
 func (s *Storage) UpdSendStatusPlayerQuest(ctx context.Context, status *models.QuestPlayerStatus) (*models.QuestPlayerStatus, error) {
 	currentTime := time.Now().UTC()
 	status.RequestExecuteDate = &currentTime
-	err := s.db.WithContext(ctx).Updates(status).Error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("quest_player_status_id = ?", status.ID).Delete(&models.ProofAttachment{}).Error
+		if err != nil {
+			return fmt.Errorf("failed delete proof attachments: %w", err)
+		}
+		err = tx.Model(status).Select("RequestExecuteDate", "ProofComment").Updates(status).Error
+		if err != nil {
+			return fmt.Errorf("failed update quest send status: %w", err)
+		}
+		for i := range status.Attachments {
+			status.Attachments[i].ID = 0
+			status.Attachments[i].QuestPlayerStatusID = status.ID
+		}
+		if len(status.Attachments) > 0 {
+			err = tx.Create(&status.Attachments).Error
+			if err != nil {
+				return fmt.Errorf("failed create proof attachments: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed update quest send status: %w", err)
 	}
 	return status, nil
 }
 
+func (s *Storage) GetProofAttachment(ctx context.Context, attachmentID uint) (*models.ProofAttachment, error) {
+	attachment := &models.ProofAttachment{}
+	err := s.db.WithContext(ctx).Where("id = ?", attachmentID).
+		Preload("QuestPlayerStatus").Preload("QuestPlayerStatus.Quest").
+		First(attachment).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get proof attachment: %w", err)
+	}
+	return attachment, nil
+}
+
 func (s *Storage) GetWallets(ctx context.Context, playerID uint) (*[]models.PlayerWallet, error) {
 	wallets := []models.PlayerWallet{}
 	err := s.db.WithContext(ctx).Find(&wallets, "player_id = ?", playerID).Error
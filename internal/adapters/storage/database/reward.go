@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Storage) NewReward(ctx context.Context, reward *models.Reward) (*models.Reward, error) {
+	err := s.db.WithContext(ctx).Save(reward).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed create reward: %w", err)
+	}
+	return reward, nil
+}
+
+func (s *Storage) GetReward(ctx context.Context, rewardID uint) (*models.Reward, error) {
+	reward := &models.Reward{}
+	err := s.db.WithContext(ctx).Where("id = ?", rewardID).First(reward).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get reward: %w", err)
+	}
+	return reward, nil
+}
+
+func (s *Storage) GetRewards(ctx context.Context, masterID uint) (*[]models.Reward, error) {
+	rewards := []models.Reward{}
+	err := s.db.WithContext(ctx).Where("user_master_id = ?", masterID).Order("updated_at desc").Find(&rewards).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get rewards: %w", err)
+	}
+	return &rewards, nil
+}
+
+func (s *Storage) UpdReward(ctx context.Context, reward *models.Reward) (*models.Reward, error) {
+	err := s.db.WithContext(ctx).
+		Model(reward).
+		Select("Title", "Description", "Cost", "Stock", "IsActive").
+		Updates(reward).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed update reward: %w", err)
+	}
+	return reward, nil
+}
+
+func (s *Storage) GetPlayerRewards(ctx context.Context, playerID uint) (*[]models.Reward, error) {
+	rewards := []models.Reward{}
+	err := s.db.WithContext(ctx).
+		Joins("join master_players mp on mp.user_master_id = rewards.user_master_id and mp.user_id = ?", playerID).
+		Where("rewards.is_active = true").
+		Order("rewards.cost").
+		Find(&rewards).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get player rewards: %w", err)
+	}
+	return &rewards, nil
+}
+
+// BuyReward списывает стоимость награды с кошелька игрока и создает покупку.
+// Остаток уменьшается условным обновлением, а кошелек блокируется на время списания,
+// поэтому параллельные покупки не могут увести баланс или остаток в минус.
+func (s *Storage) BuyReward(ctx context.Context, rewardID, playerID uint) (*models.RewardPurchase, error) {
+	purchase := &models.RewardPurchase{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reward := &models.Reward{}
+		err := tx.
+			Joins("join master_players mp on mp.user_master_id = rewards.user_master_id and mp.user_id = ?", playerID).
+			Where("rewards.id = ? and rewards.is_active = true", rewardID).
+			First(reward).Error
+		if err != nil {
+			return fmt.Errorf("failed get reward: %w", err)
+		}
+
+		res := tx.Model(&models.Reward{}).
+			Where("id = ? and (stock is NULL or stock > 0)", reward.ID).
+			Update("stock", gorm.Expr("stock - 1"))
+		if res.Error != nil {
+			return fmt.Errorf("failed update reward stock: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return apperr.ErrRewardOutOfStock
+		}
+
+		purchase.RewardID = reward.ID
+		purchase.PlayerID = playerID
+		purchase.UserMasterID = reward.UserMasterID
+		purchase.Cost = reward.Cost
+		purchase.Status = models.PurchasePending
+		err = tx.Save(purchase).Error
+		if err != nil {
+			return fmt.Errorf("failed create purchase: %w", err)
+		}
+
+		purchaseID := purchase.ID
+		err = postTransaction(tx, &models.WalletTransaction{
+			UserMasterID:     reward.UserMasterID,
+			PlayerID:         playerID,
+			Type:             models.TransactionPurchase,
+			Amount:           -int(reward.Cost),
+			Comment:          reward.Title,
+			RewardPurchaseID: &purchaseID,
+		}, false)
+		if err != nil {
+			return fmt.Errorf("failed debit wallet: %w", err)
+		}
+		purchase.Reward = *reward
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed buy reward: %w", err)
+	}
+
+	return purchase, nil
+}
+
+func (s *Storage) GetRewardPurchases(ctx context.Context, masterID uint) (*[]models.RewardPurchase, error) {
+	purchases := []models.RewardPurchase{}
+	err := s.db.WithContext(ctx).
+		Where("user_master_id = ? and status = ?", masterID, models.PurchasePending).
+		Preload("Reward").Preload("Player").
+		Order("created_at").
+		Find(&purchases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get reward purchases: %w", err)
+	}
+	return &purchases, nil
+}
+
+func (s *Storage) GetPlayerPurchases(ctx context.Context, playerID uint) (*[]models.RewardPurchase, error) {
+	purchases := []models.RewardPurchase{}
+	err := s.db.WithContext(ctx).
+		Where("player_id = ?", playerID).
+		Preload("Reward").
+		Order("created_at desc").
+		Limit(50).
+		Find(&purchases).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get player purchases: %w", err)
+	}
+	return &purchases, nil
+}
+
+func (s *Storage) GetRewardPurchase(ctx context.Context, purchaseID uint) (*models.RewardPurchase, error) {
+	purchase := &models.RewardPurchase{}
+	err := s.db.WithContext(ctx).Where("id = ?", purchaseID).Preload("Reward").First(purchase).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get reward purchase: %w", err)
+	}
+	return purchase, nil
+}
+
+func (s *Storage) FulfilRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error {
+	currentTime := time.Now().UTC()
+	res := s.db.WithContext(ctx).Model(&models.RewardPurchase{}).
+		Where("id = ? and status = ?", purchase.ID, models.PurchasePending).
+		Updates(map[string]any{"status": models.PurchaseFulfilled, "closed_date": currentTime})
+	if res.Error != nil {
+		return fmt.Errorf("failed fulfil purchase: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return apperr.ErrPurchaseAlreadyClosed
+	}
+	purchase.Status = models.PurchaseFulfilled
+	purchase.ClosedDate = &currentTime
+	return nil
+}
+
+// RefundRewardPurchase возвращает баллы на кошелек игрока и награду на склад.
+func (s *Storage) RefundRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error {
+	currentTime := time.Now().UTC()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RewardPurchase{}).
+			Where("id = ? and status = ?", purchase.ID, models.PurchasePending).
+			Updates(map[string]any{"status": models.PurchaseRefunded, "closed_date": currentTime})
+		if res.Error != nil {
+			return fmt.Errorf("failed refund purchase: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return apperr.ErrPurchaseAlreadyClosed
+		}
+
+		purchaseID := purchase.ID
+		err := postTransaction(tx, &models.WalletTransaction{
+			UserMasterID:     purchase.UserMasterID,
+			PlayerID:         purchase.PlayerID,
+			Type:             models.TransactionRefund,
+			Amount:           int(purchase.Cost),
+			Comment:          purchase.Reward.Title,
+			RewardPurchaseID: &purchaseID,
+		}, true)
+		if err != nil {
+			return fmt.Errorf("failed credit wallet: %w", err)
+		}
+
+		err = tx.Model(&models.Reward{}).
+			Where("id = ? and stock is not NULL", purchase.RewardID).
+			Update("stock", gorm.Expr("stock + 1")).Error
+		if err != nil {
+			return fmt.Errorf("failed restore reward stock: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed refund reward purchase: %w", err)
+	}
+
+	purchase.Status = models.PurchaseRefunded
+	purchase.ClosedDate = &currentTime
+	return nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// NewSession создает сессию и ее первый токен обновления.
+func (s *Storage) NewSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return fmt.Errorf("failed create session: %w", err)
+		}
+		token.SessionID = session.ID
+		if err := tx.Create(token).Error; err != nil {
+			return fmt.Errorf("failed create refresh token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed start session: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	session := &models.Session{}
+	err := s.db.WithContext(ctx).Where("id = ?", sessionID).First(session).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get session: %w", err)
+	}
+	return session, nil
+}
+
+// GetUserSessions возвращает действующие на момент now сессии пользователя, последние активные первыми.
+func (s *Storage) GetUserSessions(ctx context.Context, userID uint, now time.Time) (*[]models.Session, error) {
+	sessions := &[]models.Session{}
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? and revoked_at is NULL and expires_at > ?", userID, now).
+		Order("last_seen_at desc").Order("created_at desc").
+		Find(sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get user sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// TouchSession сохраняет время последней активности, адрес и срок сессии.
+func (s *Storage) TouchSession(ctx context.Context, session *models.Session) error {
+	err := s.db.WithContext(ctx).Model(&models.Session{}).Where("id = ?", session.ID).Updates(map[string]any{
+		"last_seen_at": session.LastSeenAt,
+		"ip":           session.IP,
+		"expires_at":   session.ExpiresAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed touch session: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession отзывает сессию и ее токены обновления.
+func (s *Storage) RevokeSession(ctx context.Context, sessionID string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		err := tx.Model(&models.Session{}).
+			Where("id = ? and revoked_at is NULL", sessionID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed revoke session: %w", err)
+		}
+		err = tx.Model(&models.RefreshToken{}).
+			Where("session_id = ? and revoked_at is NULL", sessionID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed revoke session: %w", err)
+	}
+	return nil
+}
+
+func (s *Storage) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	err := s.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(token).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// RotateRefreshToken отзывает токен old и создает next. Возвращает false, если old
+// уже отозван: тогда токен использован повторно и next не создается.
+func (s *Storage) RotateRefreshToken(ctx context.Context, old *models.RefreshToken, next *models.RefreshToken) (bool, error) {
+	rotated := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? and revoked_at is NULL", old.ID).
+			Update("revoked_at", time.Now().UTC())
+		if res.Error != nil {
+			return fmt.Errorf("failed revoke refresh token: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		err := tx.Create(next).Error
+		if err != nil {
+			return fmt.Errorf("failed create refresh token: %w", err)
+		}
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed rotate refresh token: %w", err)
+	}
+	return rotated, nil
+}
+
+// RevokeUserTokens отзывает все сессии и токены обновления пользователя и увеличивает версию его токенов,
+// после чего выданные ранее токены доступа перестают приниматься.
+func (s *Storage) RevokeUserTokens(ctx context.Context, userID uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("token_version", gorm.Expr("token_version + 1")).Error
+		if err != nil {
+			return fmt.Errorf("failed update token version: %w", err)
+		}
+		err = tx.Model(&models.Session{}).
+			Where("user_id = ? and revoked_at is NULL", userID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed revoke sessions: %w", err)
+		}
+		err = tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? and revoked_at is NULL", userID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return fmt.Errorf("failed revoke refresh tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredTokens удаляет сессии и токены обновления, срок которых истек до before.
+// Возвращает число удаленных сессий и токенов.
+func (s *Storage) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.Session{}).Select("id").Where("expires_at < ?", before)
+		res := tx.Unscoped().Where("expires_at < ? or session_id in (?)", before, expired).Delete(&models.RefreshToken{})
+		if res.Error != nil {
+			return fmt.Errorf("failed delete refresh tokens: %w", res.Error)
+		}
+		deleted += res.RowsAffected
+		res = tx.Where("expires_at < ?", before).Delete(&models.Session{})
+		if res.Error != nil {
+			return fmt.Errorf("failed delete sessions: %w", res.Error)
+		}
+		deleted += res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed delete expired tokens: %w", err)
+	}
+	return deleted, nil
+}
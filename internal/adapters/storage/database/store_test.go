@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mod-develop/backend/internal/core/discipline"
+	"github.com/mod-develop/backend/internal/core/discipline/disciplinetest"
+)
+
+// TestStorageContract прогоняет общий набор тестов хранилища на SQLite в памяти
+// и на Postgres, если задан TEST_DATABASE_URI. База Postgres очищается перед каждым тестом.
+func TestStorageContract(t *testing.T) {
+	t.Run("sqlite", func(t *testing.T) {
+		disciplinetest.TestStore(t, func(t *testing.T) discipline.Store {
+			return newTestStorage(t, "sqlite://:memory:")
+		})
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_URI")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URI is not set")
+		}
+		disciplinetest.TestStore(t, func(t *testing.T) discipline.Store {
+			return newTestStorage(t, dsn)
+		})
+	})
+}
+
+// newTestStorage открывает хранилище и пересоздает схему миграциями.
+func newTestStorage(t *testing.T, dsn string) *Storage {
+	t.Helper()
+	ctx := context.Background()
+	s, err := New(ctx, dsn, SkipMigrate())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	migrations, err := loadMigrations(s.dialect)
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+	if err := s.Rollback(ctx, len(migrations)); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Storage) GetQuestStreak(ctx context.Context, questID, playerID uint) (*models.QuestStreak, error) {
+	streak := &models.QuestStreak{}
+	err := s.db.WithContext(ctx).Where("quest_id = ? and player_id = ?", questID, playerID).First(streak).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest streak: %w", err)
+	}
+	return streak, nil
+}
+
+// lockStreak выбирает серию игрока с блокировкой до конца транзакции, создавая ее при первой оплате.
+// Одновременную вставку второй серии отсекает уникальный индекс, как у кошелька.
+func lockStreak(tx *gorm.DB, questID, playerID uint) (*models.QuestStreak, error) {
+	streak := &models.QuestStreak{}
+	find := func() error {
+		return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("quest_id = ? and player_id = ?", questID, playerID).
+			First(streak).Error
+	}
+	err := find()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.QuestStreak{QuestID: questID, PlayerID: playerID}).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed create quest streak: %w", err)
+		}
+		err = find()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return streak, nil
+}
+
+func (s *Storage) GetStreakBonuses(ctx context.Context, questID uint) (*[]models.QuestStreakBonus, error) {
+	bonuses := []models.QuestStreakBonus{}
+	err := s.db.WithContext(ctx).Where("quest_id = ?", questID).Order("days").Find(&bonuses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get streak bonuses: %w", err)
+	}
+	return &bonuses, nil
+}
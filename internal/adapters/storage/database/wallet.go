@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// postTransaction добавляет запись в журнал кошелька и обновляет его баланс.
+// Кошелек блокируется на время транзакции, при отсутствии он создается.
+// Если allowOverdraft ложно, списание в минус возвращает apperr.ErrInsufficientFunds.
+func postTransaction(tx *gorm.DB, txn *models.WalletTransaction, allowOverdraft bool) error {
+	wallet, err := lockWallet(tx, txn.UserMasterID, txn.PlayerID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed get wallet: %w", err)
+		}
+		// пустой результат ничего не блокирует, одновременную вставку второго кошелька
+		// отсекает уникальный индекс, после нее кошелек выбирается и блокируется заново
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.PlayerWallet{UserMasterID: txn.UserMasterID, PlayerID: txn.PlayerID}).Error
+		if err != nil {
+			return fmt.Errorf("failed create wallet: %w", err)
+		}
+		wallet, err = lockWallet(tx, txn.UserMasterID, txn.PlayerID)
+		if err != nil {
+			return fmt.Errorf("failed get created wallet: %w", err)
+		}
+	}
+
+	balance := wallet.Balance + txn.Amount
+	if !allowOverdraft && txn.Amount < 0 && balance < 0 {
+		return apperr.ErrInsufficientFunds
+	}
+
+	err = tx.Model(wallet).Update("balance", balance).Error
+	if err != nil {
+		return fmt.Errorf("failed update wallet balance: %w", err)
+	}
+
+	txn.WalletID = wallet.ID
+	txn.Balance = balance
+	err = tx.Create(txn).Error
+	if err != nil {
+		return fmt.Errorf("failed create wallet transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockWallet выбирает кошелек игрока в группе мастера с блокировкой до конца транзакции.
+func lockWallet(tx *gorm.DB, masterID, playerID uint) (*models.PlayerWallet, error) {
+	wallet := &models.PlayerWallet{}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_master_id = ? and player_id = ?", masterID, playerID).
+		First(wallet).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// CountUnbalancedWallets - количество кошельков, баланс которых не равен сумме записей журнала.
+func (s *Storage) CountUnbalancedWallets(ctx context.Context) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FROM player_wallets w
+		WHERE w.deleted_at IS NULL AND w.balance <> (
+			SELECT COALESCE(SUM(t.amount), 0) FROM wallet_transactions t
+			WHERE t.wallet_id = w.id AND t.deleted_at IS NULL
+		)`).Scan(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed count unbalanced wallets: %w", err)
+	}
+	return count, nil
+}
+
+func (s *Storage) GetWallet(ctx context.Context, walletID uint) (*models.PlayerWallet, error) {
+	wallet := &models.PlayerWallet{}
+	err := s.db.WithContext(ctx).Where("id = ?", walletID).Preload("Player").First(wallet).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get wallet: %w", err)
+	}
+	return wallet, nil
+}
+
+func (s *Storage) GetMasterWallets(ctx context.Context, masterID uint) (*[]models.PlayerWallet, error) {
+	wallets := []models.PlayerWallet{}
+	err := s.db.WithContext(ctx).
+		Where("user_master_id = ?", masterID).
+		Preload("Player").
+		Order("player_id").
+		Find(&wallets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get master wallets: %w", err)
+	}
+	return &wallets, nil
+}
+
+func (s *Storage) AdjustWallet(ctx context.Context, txn *models.WalletTransaction) (*models.WalletTransaction, error) {
+	txn.Type = models.TransactionAdjustment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return postTransaction(tx, txn, true)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed adjust wallet: %w", err)
+	}
+	return txn, nil
+}
+
+func (s *Storage) GetWalletTransaction(ctx context.Context, transactionID uint) (*models.WalletTransaction, error) {
+	txn := &models.WalletTransaction{}
+	err := s.db.WithContext(ctx).Where("id = ?", transactionID).First(txn).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed get wallet transaction: %w", err)
+	}
+	return txn, nil
+}
+
+// ReverseWalletTransaction добавляет в журнал запись, отменяющую исходную.
+// Повторная отмена одной записи возвращает apperr.ErrTransactionReversed.
+func (s *Storage) ReverseWalletTransaction(ctx context.Context, original *models.WalletTransaction, authorID uint) (*models.WalletTransaction, error) {
+	reversal := &models.WalletTransaction{
+		UserMasterID: original.UserMasterID,
+		PlayerID:     original.PlayerID,
+		Type:         models.TransactionReversal,
+		Amount:       -original.Amount,
+		Comment:      fmt.Sprintf("reversal of #%d", original.ID),
+		AuthorID:     &authorID,
+		ReversalOfID: &original.ID,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", original.ID).First(&models.WalletTransaction{}).Error
+		if err != nil {
+			return fmt.Errorf("failed lock wallet transaction: %w", err)
+		}
+
+		var count int64
+		err = tx.Model(&models.WalletTransaction{}).Where("reversal_of_id = ?", original.ID).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("failed check reversal: %w", err)
+		}
+		if count > 0 {
+			return apperr.ErrTransactionReversed
+		}
+
+		return postTransaction(tx, reversal, true)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed reverse wallet transaction: %w", err)
+	}
+	return reversal, nil
+}
+
+func (s *Storage) GetWalletTransactions(ctx context.Context, walletID uint, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	return s.findTransactions(ctx, "wallet_id = ?", walletID, offset, limit)
+}
+
+func (s *Storage) GetPlayerTransactions(ctx context.Context, playerID uint, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	return s.findTransactions(ctx, "player_id = ?", playerID, offset, limit)
+}
+
+func (s *Storage) findTransactions(ctx context.Context, where string, id uint, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	var total int64
+	err := s.db.WithContext(ctx).Model(&models.WalletTransaction{}).Where(where, id).Count(&total).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed count wallet transactions: %w", err)
+	}
+
+	txns := []models.WalletTransaction{}
+	err = s.db.WithContext(ctx).
+		Select("wallet_transactions.*, EXISTS(SELECT 1 FROM wallet_transactions r "+
+			"WHERE r.reversal_of_id = wallet_transactions.id AND r.deleted_at IS NULL) AS is_reversed").
+		Where(where, id).
+		Order("id desc").
+		Offset(offset).
+		Limit(limit).
+		Find(&txns).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed get wallet transactions: %w", err)
+	}
+
+	return &txns, total, nil
+}
@@ -1,14 +1,32 @@
 package types
 
-import "github.com/mod-develop/backend/internal/models"
+import (
+	"io"
+	"time"
+
+	"github.com/mod-develop/backend/internal/models"
+)
 
 type TAction struct {
 	IsQuestCreater bool
 }
 
+// TMaster - группа мастера, которой управляет пользователь, Role - его роль в группе.
 type TMaster struct {
 	ID   uint
+	Name string
 	Code string
+	Role models.MemberRole
+}
+
+// IsOwner - пользователь владелец группы.
+func (m TMaster) IsOwner() bool {
+	return m.Role == models.MemberOwner
+}
+
+// RoleTitle - название роли пользователя в группе.
+func (m TMaster) RoleTitle() string {
+	return MemberRoleTitle(m.Role)
 }
 
 type TPlayer struct {
@@ -17,12 +35,190 @@ type TPlayer struct {
 
 type TUser struct {
 	ID            uint
+	SessionID     string
 	Login         string
 	IsAdmin       bool
 	IsQuestMaster bool
 	Action        TAction
-	Master        TMaster
+	Permissions   map[string]bool // действия всех ролей пользователя
+	Master        TMaster         // группа, которой пользователь управляет сейчас
+	Masters       []TMaster       // все группы, между которыми он может переключаться
 	Player        TPlayer
+	// MustChangePassword - пароль сброшен админом, до смены доступны только смена пароля и выход
+	MustChangePassword bool
+}
+
+// Can - у пользователя есть роль с действием action.
+func (u TUser) Can(action string) bool {
+	return u.Permissions[action]
+}
+
+// TAuthTokens - выданная пара токенов, ExpiresIn - время жизни токена доступа.
+type TAuthTokens struct {
+	Access    string
+	Refresh   string
+	ExpiresIn time.Duration
+}
+
+// TSession - сессия пользователя на устройстве, Current - сессия текущего запроса.
+type TSession struct {
+	ID         string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	Current    bool
+}
+
+type TSessionsPage struct {
+	User     TUser
+	Sessions []TSession
+}
+
+// TRole - роль и ее действия.
+type TRole struct {
+	ID      uint
+	Name    string
+	Actions []string
+}
+
+// TUserRoles - пользователь с ролями для экрана назначения ролей.
+type TUserRoles struct {
+	ID        uint
+	Login     string
+	IsMaster  bool
+	Locked    bool
+	CreatedAt time.Time
+	Roles     map[string]bool
+}
+
+// TUsersList - страница пользователей админки.
+type TUsersList struct {
+	Users      []TUserRoles
+	Pagination TPagination
+}
+
+type TAdminUsersPage struct {
+	User  TUser
+	Query string
+	Page  int
+	Roles []TRole
+	Users TUsersList
+	Error string
+}
+
+// TAdminUser - пользователь в админке вместе с группами, которыми он владеет.
+type TAdminUser struct {
+	TUserRoles
+	Masters []TAdminMaster
+}
+
+// TAdminMaster - группа мастера в админке: ее игроки и квесты.
+type TAdminMaster struct {
+	ID      uint
+	Name    string
+	Code    string
+	Players []TQuestPlayer
+	Quests  []TQuest
+}
+
+// TAdminUserPage - Password заполнен один раз, сразу после сброса пароля.
+type TAdminUserPage struct {
+	User     TUser
+	Account  TAdminUser
+	Password string
+	Error    string
+}
+
+// THealth - состояние системы. UnpaidQuests - подтвержденные квесты, за которые еще не начислены баллы,
+// OldestUnpaid - время подтверждения самого старого из них.
+type THealth struct {
+	Users        int
+	LockedUsers  int
+	Masters      int
+	UnpaidQuests int
+	OldestUnpaid *time.Time
+}
+
+type TAdminHealthPage struct {
+	User   TUser
+	Health THealth
+}
+
+// TMemberRole - роль участника группы мастера.
+type TMemberRole struct {
+	Title string
+	Value models.MemberRole
+}
+
+// MemberRoles - роли, с которыми можно пригласить в группу.
+var MemberRoles = []TMemberRole{
+	{Title: "Со-мастер", Value: models.MemberCoMaster},
+	{Title: "Проверяющий", Value: models.MemberReviewer},
+}
+
+// MemberRoleTitle возвращает название роли участника.
+func MemberRoleTitle(role models.MemberRole) string {
+	if role == models.MemberOwner {
+		return "Владелец"
+	}
+	for _, r := range MemberRoles {
+		if r.Value == role {
+			return r.Title
+		}
+	}
+	return string(role)
+}
+
+type TMasterMember struct {
+	UserID    uint
+	Login     string
+	Role      models.MemberRole
+	RoleTitle string
+	IsSelf    bool
+}
+
+// TMasterInvite - приглашение в группу мастера, Master - логин владельца группы.
+type TMasterInvite struct {
+	ID        uint
+	Code      string
+	Role      models.MemberRole
+	RoleTitle string
+	Master    string
+	ExpiresAt time.Time
+}
+
+// TMasterTeam - участники группы мастера. Приглашения заполнены только для владельца.
+type TMasterTeam struct {
+	Members []TMasterMember
+	Invites []TMasterInvite
+}
+
+type TTeamPage struct {
+	User  TUser
+	Team  TMasterTeam
+	Roles []TMemberRole
+	Error string
+}
+
+// TMastersPage - группы, которыми управляет пользователь, и создание новой.
+type TMastersPage struct {
+	User  TUser
+	Error string
+}
+
+// TInvitePage - страница приглашения по коду Code, Invite пуст, если приглашение не действует.
+type TInvitePage struct {
+	User   TUser
+	Code   string
+	Invite *TMasterInvite
+	Error  string
+}
+
+type TErrorPage struct {
+	Status  int
+	Message string
+	Details map[string]string
 }
 
 type TMainPage struct {
@@ -33,6 +229,13 @@ type TProfilePage struct {
 	User TUser
 }
 
+// TPasswordPage - смена пароля, после сброса админом пользователь попадает сюда при входе.
+type TPasswordPage struct {
+	User   TUser
+	Error  string
+	Fields map[string]string // ошибки полей формы
+}
+
 type TQuestGiverPage struct {
 	User   TUser
 	Quests []TQuest
@@ -59,6 +262,41 @@ var QuestTypes = []TQuestType{
 		Title: "Ежедневный",
 		Value: models.Daily,
 	},
+	{
+		Title: "Еженедельный",
+		Value: models.Weekly,
+	},
+	{
+		Title: "Ежемесячный",
+		Value: models.Monthly,
+	},
+	{
+		Title: "По дням недели",
+		Value: models.WeekDays,
+	},
+	{
+		Title: "Несколько раз в неделю",
+		Value: models.TimesPerWeek,
+	},
+}
+
+type TWeekDay struct {
+	Title    string
+	Value    int
+	Selected bool
+}
+
+// NewWeekDays возвращает дни недели начиная с понедельника, 0 - воскресенье.
+func NewWeekDays() []TWeekDay {
+	return []TWeekDay{
+		{Title: "Пн", Value: 1},
+		{Title: "Вт", Value: 2},
+		{Title: "Ср", Value: 3},
+		{Title: "Чт", Value: 4},
+		{Title: "Пт", Value: 5},
+		{Title: "Сб", Value: 6},
+		{Title: "Вс", Value: 0},
+	}
 }
 
 type TQuest struct {
@@ -66,6 +304,7 @@ type TQuest struct {
 	Title          string
 	Description    string
 	Price          uint
+	Penalty        uint
 	IsActive       bool
 	Players        []TQuestPlayer
 	Types          []TQuestType
@@ -73,27 +312,73 @@ type TQuest struct {
 	DateStart      string
 	DateEnd        string
 	TimeZoneOffset int
+	WeekDays       []TWeekDay
+	MonthDays      string
+	Times          uint
+	Schedule       string
+	StreakBonuses  []TStreakBonus
+}
+
+type TStreakBonus struct {
+	Days  uint
+	Bonus uint
 }
 
 type TQuestEdit struct {
 	User    TUser
 	Quest   TQuest
 	Error   string
+	Fields  map[string]string // ошибки полей формы
 	Success string
 }
 
 type TQuestNew struct {
-	User  TUser
-	Quest TQuest
-	Error string
+	User   TUser
+	Quest  TQuest
+	Error  string
+	Fields map[string]string // ошибки полей формы
 }
 
 type TQuestAwait struct {
+	ID           uint
+	Title        string
+	Description  string
+	PlayerName   string
+	Price        uint
+	Period       string
+	ProofComment string
+	Attachments  []TProofAttachment
+	Comments     []TQuestComment
+}
+
+// TQuestComment - сообщение в обсуждении отправленного квеста.
+type TQuestComment struct {
+	ID       uint
+	Author   string
+	Text     string
+	Date     string
+	IsMaster bool
+}
+
+// TProofFile - файл подтверждения, загружаемый игроком.
+type TProofFile struct {
+	Name    string
+	Size    int64
+	Content io.Reader
+}
+
+// TProof - подтверждение выполнения квеста: комментарий и файлы.
+type TProof struct {
+	Comment string
+	Files   []TProofFile
+}
+
+type TProofAttachment struct {
 	ID          uint
-	Title       string
-	Description string
-	PlayerName  string
-	Price       uint
+	Name        string
+	ContentType string
+	Size        int64
+	IsImage     bool
 }
 
 type TQuestAwaitPage struct {
@@ -111,13 +396,28 @@ type TPlayerProfilePage struct {
 }
 
 type TPlayerQuest struct {
-	ID          uint
-	Title       string
-	Description string
-	Price       uint
-	IsSended    bool
-	IsRejected  bool
-	IsConfirmed bool
+	ID            uint
+	Title         string
+	Description   string
+	Price         uint
+	Penalty       uint
+	IsSended      bool
+	IsRejected    bool
+	IsConfirmed   bool
+	IsRecurring   bool
+	Period        string
+	Schedule      string
+	IsScheduled   bool
+	Done          int
+	Total         int
+	StreakCurrent uint
+	StreakBest    uint
+	StreakBonuses []TStreakBonus
+	StatusID      uint
+	ProofComment  string
+	Attachments   []TProofAttachment
+	RejectReason  string
+	Comments      []TQuestComment
 }
 
 type TPlayerQuestsPage struct {
@@ -133,11 +433,132 @@ type TPlayerQuestPage struct {
 }
 
 type TPlayerSettingsPage struct {
-	User    TUser
-	Masters []TMaster
+	User     TUser
+	Masters  []TMaster
+	TimeZone string
 }
 
 type TPlayerWaller struct {
-	Score    int
-	PlayerID uint
+	ID         uint
+	Score      int
+	PlayerID   uint
+	PlayerName string
+	MasterID   uint
+}
+
+type TPagination struct {
+	Page  int
+	Pages int
+	Total int64
+}
+
+func (p TPagination) HasPrev() bool {
+	return p.Page > 1
+}
+
+func (p TPagination) HasNext() bool {
+	return p.Page < p.Pages
+}
+
+func (p TPagination) Prev() int {
+	return p.Page - 1
+}
+
+func (p TPagination) Next() int {
+	return p.Page + 1
+}
+
+type TWalletTransaction struct {
+	ID         uint
+	Type       models.WalletTransactionType
+	TypeTitle  string
+	Amount     int
+	Balance    int
+	Comment    string
+	Date       string
+	IsReversed bool
+	CanReverse bool
+}
+
+type TWalletHistory struct {
+	Wallet       TPlayerWaller
+	Transactions []TWalletTransaction
+	Pagination   TPagination
+}
+
+type TWalletsPage struct {
+	User    TUser
+	Wallets []TPlayerWaller
+	Error   string
+	Success string
+}
+
+type TWalletHistoryPage struct {
+	User    TUser
+	History TWalletHistory
+	Error   string
+	Success string
+}
+
+type TReward struct {
+	ID          uint
+	Title       string
+	Description string
+	Cost        uint
+	Stock       uint
+	IsUnlimited bool
+	IsActive    bool
+}
+
+type TRewardsPage struct {
+	User    TUser
+	Rewards []TReward
+}
+
+type TRewardEdit struct {
+	User    TUser
+	Reward  TReward
+	Error   string
+	Fields  map[string]string // ошибки полей формы
+	Success string
+}
+
+type TRewardNew struct {
+	User   TUser
+	Reward TReward
+	Error  string
+	Fields map[string]string // ошибки полей формы
+}
+
+type TRewardPurchase struct {
+	ID         uint
+	Title      string
+	PlayerName string
+	Cost       uint
+	Status     models.RewardPurchaseStatus
+	Date       string
+}
+
+type TRewardPurchasesPage struct {
+	User      TUser
+	Purchases []TRewardPurchase
+}
+
+type TPlayerReward struct {
+	ID          uint
+	Title       string
+	Description string
+	Cost        uint
+	Stock       uint
+	IsUnlimited bool
+	Score       int
+	CanBuy      bool
+}
+
+type TPlayerRewardsPage struct {
+	User      TUser
+	Rewards   []TPlayerReward
+	Purchases []TRewardPurchase
+	Error     string
+	Success   string
 }
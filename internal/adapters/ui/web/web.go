@@ -17,21 +17,22 @@ func New() (*Web, error) {
 	return w, nil
 }
 
-func baseLayout(wr http.ResponseWriter, temp string) error {
+func baseLayout(wr http.ResponseWriter, temp string, data any) error {
 	tmpl, err := template.ParseFiles("templates/base.html", temp)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
 
-	err = tmpl.ExecuteTemplate(wr, "base", nil)
+	err = tmpl.ExecuteTemplate(wr, "base", data)
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
 	return nil
 }
 
-func baseUserLayout(wr http.ResponseWriter, temp string, data any) error {
-	tmpl, err := template.ParseFiles("templates/user.html", temp)
+func baseUserLayout(wr http.ResponseWriter, temp string, data any, partials ...string) error {
+	files := append([]string{"templates/user.html", temp}, partials...)
+	tmpl, err := template.ParseFiles(files...)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
@@ -43,8 +44,9 @@ func baseUserLayout(wr http.ResponseWriter, temp string, data any) error {
 	return nil
 }
 
-func baseManagerLayout(wr http.ResponseWriter, temp string, data any) error {
-	tmpl, err := template.ParseFiles("templates/user.html", "templates/manager/tabs.html", temp)
+func baseManagerLayout(wr http.ResponseWriter, temp string, data any, partials ...string) error {
+	files := append([]string{"templates/user.html", "templates/manager/tabs.html", temp}, partials...)
+	tmpl, err := template.ParseFiles(files...)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
@@ -56,8 +58,23 @@ func baseManagerLayout(wr http.ResponseWriter, temp string, data any) error {
 	return nil
 }
 
-func basePlayerLayout(wr http.ResponseWriter, temp string, data any) error {
-	tmpl, err := template.ParseFiles("templates/player/base.html", "templates/player/navigate.html", temp)
+func baseAdminLayout(wr http.ResponseWriter, temp string, data any, partials ...string) error {
+	files := append([]string{"templates/user.html", "templates/admin/tabs.html", temp}, partials...)
+	tmpl, err := template.ParseFiles(files...)
+	if err != nil {
+		return fmt.Errorf("failed parse page: %w", err)
+	}
+
+	err = tmpl.ExecuteTemplate(wr, "base", data)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func basePlayerLayout(wr http.ResponseWriter, temp string, data any, partials ...string) error {
+	files := append([]string{"templates/player/base.html", "templates/player/navigate.html", temp}, partials...)
+	tmpl, err := template.ParseFiles(files...)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
@@ -70,7 +87,7 @@ func basePlayerLayout(wr http.ResponseWriter, temp string, data any) error {
 }
 
 func (w *Web) RegistrationPage(wr http.ResponseWriter) error {
-	err := baseLayout(wr, "templates/registration.html")
+	err := baseLayout(wr, "templates/registration.html", nil)
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -78,21 +95,26 @@ func (w *Web) RegistrationPage(wr http.ResponseWriter) error {
 }
 
 func (w *Web) Error500Page(wr http.ResponseWriter) error {
-	tmpl, err := template.ParseFiles("templates/500.html")
+	wr.WriteHeader(http.StatusInternalServerError)
+	err := baseLayout(wr, "templates/500.html", nil)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
+	return nil
+}
 
-	wr.WriteHeader(http.StatusInternalServerError)
-	err = tmpl.Execute(wr, nil)
+func (w *Web) Error403Page(wr http.ResponseWriter) error {
+	err := baseLayout(wr, "templates/403.html", nil)
 	if err != nil {
-		return fmt.Errorf("failed execute template: %w", err)
+		return fmt.Errorf("failed parse page: %w", err)
 	}
 	return nil
 }
 
-func (w *Web) Error403Page(wr http.ResponseWriter) error {
-	err := baseLayout(wr, "templates/403.html")
+// ErrorPage - страница ошибки, для которой нет отдельного шаблона.
+func (w *Web) ErrorPage(wr http.ResponseWriter, page *types.TErrorPage) error {
+	wr.WriteHeader(page.Status)
+	err := baseLayout(wr, "templates/error.html", page)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
@@ -100,7 +122,7 @@ func (w *Web) Error403Page(wr http.ResponseWriter) error {
 }
 
 func (w *Web) Error404Page(wr http.ResponseWriter) error {
-	err := baseLayout(wr, "templates/404.html")
+	err := baseLayout(wr, "templates/404.html", nil)
 	if err != nil {
 		return fmt.Errorf("failed parse page: %w", err)
 	}
@@ -108,7 +130,7 @@ func (w *Web) Error404Page(wr http.ResponseWriter) error {
 }
 
 func (w *Web) AuthorizationPage(wr http.ResponseWriter) error {
-	err := baseLayout(wr, "templates/authorization.html")
+	err := baseLayout(wr, "templates/authorization.html", nil)
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -131,6 +153,14 @@ func (w *Web) ProfilePage(wr http.ResponseWriter, page *types.TProfilePage) erro
 	return nil
 }
 
+func (w *Web) PasswordPage(wr http.ResponseWriter, page *types.TPasswordPage) error {
+	err := baseUserLayout(wr, "templates/user/password.html", page, "templates/partials/field_error.html")
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
 func (w *Web) QuestGiverPage(wr http.ResponseWriter, page *types.TQuestGiverPage) error {
 	err := baseManagerLayout(wr, "templates/manager/quests/index.html", page)
 	if err != nil {
@@ -140,7 +170,7 @@ func (w *Web) QuestGiverPage(wr http.ResponseWriter, page *types.TQuestGiverPage
 }
 
 func (w *Web) QuestEdit(wr http.ResponseWriter, page *types.TQuestEdit) error {
-	err := baseManagerLayout(wr, "templates/manager/quests/edit.html", page)
+	err := baseManagerLayout(wr, "templates/manager/quests/edit.html", page, "templates/manager/quests/schedule.html", "templates/partials/field_error.html")
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -148,7 +178,7 @@ func (w *Web) QuestEdit(wr http.ResponseWriter, page *types.TQuestEdit) error {
 }
 
 func (w *Web) QuestNew(wr http.ResponseWriter, page *types.TQuestNew) error {
-	err := baseManagerLayout(wr, "templates/manager/quests/new.html", page)
+	err := baseManagerLayout(wr, "templates/manager/quests/new.html", page, "templates/manager/quests/schedule.html", "templates/partials/field_error.html")
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -156,7 +186,8 @@ func (w *Web) QuestNew(wr http.ResponseWriter, page *types.TQuestNew) error {
 }
 
 func (w *Web) QuestAwait(wr http.ResponseWriter, page *types.TQuestAwaitPage) error {
-	err := baseManagerLayout(wr, "templates/manager/await/index.html", page)
+	err := baseManagerLayout(wr, "templates/manager/await/index.html", page,
+		"templates/partials/proof.html", "templates/partials/comments.html")
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -180,7 +211,8 @@ func (w *Web) PlayerProfile(wr http.ResponseWriter, page *types.TPlayerProfilePa
 }
 
 func (w *Web) PlayerQuest(wr http.ResponseWriter, page *types.TPlayerQuestPage) error {
-	err := basePlayerLayout(wr, "templates/player/quests/get.html", page)
+	err := basePlayerLayout(wr, "templates/player/quests/get.html", page,
+		"templates/partials/proof.html", "templates/partials/comments.html")
 	if err != nil {
 		return fmt.Errorf("failed execute template: %w", err)
 	}
@@ -194,3 +226,123 @@ func (w *Web) PlayerSettings(wr http.ResponseWriter, page *types.TPlayerSettings
 	}
 	return nil
 }
+
+func (w *Web) PlayerSessions(wr http.ResponseWriter, page *types.TSessionsPage) error {
+	err := basePlayerLayout(wr, "templates/player/sessions.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) Rewards(wr http.ResponseWriter, page *types.TRewardsPage) error {
+	err := baseManagerLayout(wr, "templates/manager/rewards/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) RewardEdit(wr http.ResponseWriter, page *types.TRewardEdit) error {
+	err := baseManagerLayout(wr, "templates/manager/rewards/edit.html", page, "templates/manager/rewards/form.html", "templates/partials/field_error.html")
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) RewardNew(wr http.ResponseWriter, page *types.TRewardNew) error {
+	err := baseManagerLayout(wr, "templates/manager/rewards/new.html", page, "templates/manager/rewards/form.html", "templates/partials/field_error.html")
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) RewardPurchases(wr http.ResponseWriter, page *types.TRewardPurchasesPage) error {
+	err := baseManagerLayout(wr, "templates/manager/rewards/purchases.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) PlayerRewards(wr http.ResponseWriter, page *types.TPlayerRewardsPage) error {
+	err := basePlayerLayout(wr, "templates/player/rewards/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) Wallets(wr http.ResponseWriter, page *types.TWalletsPage) error {
+	err := baseManagerLayout(wr, "templates/manager/wallets/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) WalletHistory(wr http.ResponseWriter, page *types.TWalletHistoryPage) error {
+	err := baseManagerLayout(wr, "templates/manager/wallets/history.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) PlayerWallet(wr http.ResponseWriter, page *types.TWalletHistoryPage) error {
+	err := basePlayerLayout(wr, "templates/player/wallet/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) AdminUsers(wr http.ResponseWriter, page *types.TAdminUsersPage) error {
+	err := baseAdminLayout(wr, "templates/admin/users.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) AdminUser(wr http.ResponseWriter, page *types.TAdminUserPage) error {
+	err := baseAdminLayout(wr, "templates/admin/user.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) AdminHealth(wr http.ResponseWriter, page *types.TAdminHealthPage) error {
+	err := baseAdminLayout(wr, "templates/admin/health.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) Team(wr http.ResponseWriter, page *types.TTeamPage) error {
+	err := baseManagerLayout(wr, "templates/manager/members/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) Masters(wr http.ResponseWriter, page *types.TMastersPage) error {
+	err := baseManagerLayout(wr, "templates/manager/masters/index.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
+
+func (w *Web) Invite(wr http.ResponseWriter, page *types.TInvitePage) error {
+	err := baseUserLayout(wr, "templates/user/invite.html", page)
+	if err != nil {
+		return fmt.Errorf("failed execute template: %w", err)
+	}
+	return nil
+}
@@ -2,26 +2,47 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 
 	"github.com/mod-develop/backend/internal/adapters/api/rest"
+	"github.com/mod-develop/backend/internal/adapters/auth"
+	"github.com/mod-develop/backend/internal/adapters/storage/blob"
 	"github.com/mod-develop/backend/internal/adapters/storage/database"
 )
 
 // Config конфигурация сервиса.
 type Config struct {
 	LogLevel string `env:"LOG_LEVEL"`
+	DevMode  bool   `env:"DEV_MODE"` // режим разработки: разрешает запуск без SECRET_KEY
 	Rest     rest.Config
+	Auth     auth.Config
 	Store    database.Config
+	Blob     blob.Config
 }
 
 // Init инициализирует конфигурацию сервиса.
 func Init() (*Config, error) {
 	cfg := Config{
 		Rest: rest.Config{
-			Address: "localhost:8080",
+			Address:       "localhost:8080",
+			RateLimit:     rest.RateLimit{Requests: 300, Period: time.Minute},
+			AuthRateLimit: rest.RateLimit{Requests: 20, Period: time.Minute},
+			LoginLockout: rest.LoginLockout{
+				Attempts:   5,
+				IPAttempts: 20,
+				Base:       time.Minute,
+				Max:        time.Hour,
+			},
+		},
+		Auth: auth.Config{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Blob: blob.Config{
+			Dir: "data/blobs",
 		},
 	}
 	_ = godotenv.Load(".env")
@@ -0,0 +1,154 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// Проверки доступа. Методы, которые читают или меняют квест, отправку, кошелек, операцию,
+// награду или покупку по идентификатору из запроса, получают их только через эти функции.
+// Чужие данные для пользователя не существуют: проверка возвращает apperr.ErrDataNotFound,
+// чтобы не раскрывать их наличие. apperr.ErrForbidden - у пользователя нет группы мастера квестов.
+// Данные мастера принадлежат группе, с ними работает любой ее участник: masterID - группа,
+// которой управляет пользователь запроса.
+
+// notFound переводит ненайденную запись в apperr.ErrDataNotFound, остальные ошибки оборачивает msg.
+func notFound(err error, msg string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Join(err, apperr.ErrDataNotFound)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// checkMaster проверяет, что действие выполняет мастер квестов: у админа без своей группы masterID нулевой.
+func checkMaster(masterID uint) error {
+	if masterID == 0 {
+		return apperr.ErrForbidden
+	}
+	return nil
+}
+
+// masterQuest возвращает квест группы masterID.
+func (s *Discipline) masterQuest(ctx context.Context, questID, masterID uint) (*models.Quest, error) {
+	if err := checkMaster(masterID); err != nil {
+		return nil, err
+	}
+	quest, err := s.store.GetQuest(ctx, questID)
+	if err != nil {
+		return nil, notFound(err, "failed get quest")
+	}
+	if quest.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return quest, nil
+}
+
+// masterStatus возвращает отправку квеста группы masterID.
+func (s *Discipline) masterStatus(ctx context.Context, statusID, masterID uint) (*models.QuestPlayerStatus, error) {
+	if err := checkMaster(masterID); err != nil {
+		return nil, err
+	}
+	status, err := s.store.GetAwaitQUest(ctx, statusID)
+	if err != nil {
+		return nil, notFound(err, "failed get quest status")
+	}
+	if status.Quest.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return status, nil
+}
+
+// questStatusForUser возвращает отправку квеста, если она доступна пользователю:
+// игроку, который ее отправил, или участнику группы мастера квеста.
+func (s *Discipline) questStatusForUser(ctx context.Context, statusID, userID uint) (*models.QuestPlayerStatus, error) {
+	status, err := s.store.GetAwaitQUest(ctx, statusID)
+	if err != nil {
+		return nil, notFound(err, "failed get quest status")
+	}
+	if err := s.checkSeeStatus(ctx, status, userID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *Discipline) checkSeeStatus(ctx context.Context, status *models.QuestPlayerStatus, userID uint) error {
+	if status.PlayerID == userID {
+		return nil
+	}
+	_, err := s.store.GetMasterMember(ctx, status.Quest.UserMasterID, userID)
+	if err != nil {
+		return notFound(err, "failed get master member")
+	}
+	return nil
+}
+
+// masterPlayer проверяет, что playerID - игрок мастера masterID.
+func (s *Discipline) masterPlayer(ctx context.Context, masterID, playerID uint) error {
+	if err := checkMaster(masterID); err != nil {
+		return err
+	}
+	master, err := s.store.GetMasterByID(ctx, masterID)
+	if err != nil {
+		return notFound(err, "failed get master")
+	}
+	for _, p := range master.Players {
+		if p.ID == playerID {
+			return nil
+		}
+	}
+	return apperr.ErrDataNotFound
+}
+
+// masterWallet возвращает кошелек игрока у мастера masterID.
+func (s *Discipline) masterWallet(ctx context.Context, walletID, masterID uint) (*models.PlayerWallet, error) {
+	wallet, err := s.store.GetWallet(ctx, walletID)
+	if err != nil {
+		return nil, notFound(err, "failed get wallet")
+	}
+	if wallet.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return wallet, nil
+}
+
+// masterTransaction возвращает операцию кошелька у мастера masterID.
+func (s *Discipline) masterTransaction(ctx context.Context, transactionID, masterID uint) (*models.WalletTransaction, error) {
+	txn, err := s.store.GetWalletTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, notFound(err, "failed get wallet transaction")
+	}
+	if txn.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return txn, nil
+}
+
+// masterReward возвращает награду мастера masterID.
+func (s *Discipline) masterReward(ctx context.Context, rewardID, masterID uint) (*models.Reward, error) {
+	reward, err := s.store.GetReward(ctx, rewardID)
+	if err != nil {
+		return nil, notFound(err, "failed get reward")
+	}
+	if reward.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return reward, nil
+}
+
+// masterPurchase возвращает покупку награды мастера masterID.
+func (s *Discipline) masterPurchase(ctx context.Context, purchaseID, masterID uint) (*models.RewardPurchase, error) {
+	purchase, err := s.store.GetRewardPurchase(ctx, purchaseID)
+	if err != nil {
+		return nil, notFound(err, "failed get reward purchase")
+	}
+	if purchase.UserMasterID != masterID {
+		return nil, apperr.ErrDataNotFound
+	}
+	return purchase, nil
+}
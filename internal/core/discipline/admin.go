@@ -0,0 +1,119 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/pkg/tools"
+)
+
+// tempPasswordBytes - длина временного пароля после сброса, 12 символов base64url.
+var tempPasswordBytes = 9
+
+// GetAdminUser возвращает пользователя с ролями, а мастеру - игроков и квесты каждой его группы.
+func (s *Discipline) GetAdminUser(ctx context.Context, userID uint) (*types.TAdminUser, error) {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, "failed get user")
+	}
+	result := &types.TAdminUser{
+		TUserRoles: userRolesView(user),
+		Masters:    []types.TAdminMaster{},
+	}
+	for _, master := range user.OwnedMasters() {
+		players, err := s.GetPlayers(ctx, master.ID)
+		if err != nil {
+			return nil, err
+		}
+		quests, err := s.GetQuests(ctx, master.ID)
+		if err != nil && !errors.Is(err, apperr.ErrDataNotFound) {
+			return nil, err
+		}
+		result.Masters = append(result.Masters, types.TAdminMaster{
+			ID:      master.ID,
+			Name:    master.Name,
+			Code:    master.UniqueCode,
+			Players: *players,
+			Quests:  *quests,
+		})
+	}
+	return result, nil
+}
+
+// LockUser блокирует пользователя userID и завершает его сессии, блокирует actorID.
+// Себя заблокировать нельзя.
+func (s *Discipline) LockUser(ctx context.Context, actorID, userID uint) error {
+	if actorID == userID {
+		return apperr.ErrValidation.WithField("user", "Нельзя заблокировать себя")
+	}
+	now := time.Now().UTC()
+	if err := s.store.SetUserLocked(ctx, userID, &now); err != nil {
+		return notFound(err, "failed lock user")
+	}
+	if err := s.store.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser снимает блокировку пользователя userID.
+func (s *Discipline) UnlockUser(ctx context.Context, userID uint) error {
+	if err := s.store.SetUserLocked(ctx, userID, nil); err != nil {
+		return notFound(err, "failed unlock user")
+	}
+	return nil
+}
+
+// ResetPassword задает пользователю userID временный пароль и завершает его сессии.
+// Пароль возвращается один раз, чтобы админ передал его пользователю; после входа
+// с ним пользователь должен сменить пароль.
+func (s *Discipline) ResetPassword(ctx context.Context, userID uint) (string, error) {
+	password, err := tools.RandomToken(tempPasswordBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed create password: %w", err)
+	}
+	passwordHash, err := tools.HashPassword(password)
+	if err != nil {
+		return "", fmt.Errorf("failed hashing password: %w", err)
+	}
+	if err := s.store.SetUserPassword(ctx, userID, passwordHash, true); err != nil {
+		return "", notFound(err, "failed set password")
+	}
+	if err := s.store.RevokeUserTokens(ctx, userID); err != nil {
+		return "", fmt.Errorf("failed revoke user tokens: %w", err)
+	}
+	return password, nil
+}
+
+// GetHealth собирает состояние системы: пользователей, мастеров и очередь начисления баллов.
+func (s *Discipline) GetHealth(ctx context.Context) (*types.THealth, error) {
+	users, err := s.store.GetUserStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed get user stats: %w", err)
+	}
+	health := &types.THealth{
+		Users:       int(users.Users),
+		LockedUsers: int(users.Locked),
+		Masters:     int(users.Masters),
+	}
+
+	unpaid, err := s.store.GetQuestNotPayed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed get not payed quests: %w", err)
+	}
+	health.UnpaidQuests = len(*unpaid)
+	for _, status := range *unpaid {
+		if status.ConfirmationDate == nil {
+			continue
+		}
+		if health.OldestUnpaid == nil || status.ConfirmationDate.Before(*health.OldestUnpaid) {
+			confirmed := *status.ConfirmationDate
+			health.OldestUnpaid = &confirmed
+		}
+	}
+	return health, nil
+}
@@ -0,0 +1,89 @@
+package discipline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	maxQuestCommentLength = 2000
+	maxRejectReasonLength = 500
+)
+
+// commentsToView переводит обсуждение в представление, playerID - игрок, отправивший квест.
+// Остальные сообщения написаны мастерами группы.
+func commentsToView(comments []models.QuestComment, playerID uint) []types.TQuestComment {
+	result := []types.TQuestComment{}
+	for _, c := range comments {
+		result = append(result, commentToView(&c, playerID))
+	}
+	return result
+}
+
+func commentToView(c *models.QuestComment, playerID uint) types.TQuestComment {
+	return types.TQuestComment{
+		ID:       c.ID,
+		Author:   c.Author.Login,
+		Text:     c.Text,
+		Date:     c.CreatedAt.UTC().Format(defaultViewDateTimeFormat),
+		IsMaster: c.AuthorID != playerID,
+	}
+}
+
+func validateRejectReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > maxRejectReasonLength {
+		return "", apperr.ErrRejectReasonLength.
+			WithField("reason", fmt.Sprintf("Причина должна быть не длиннее %d символов", maxRejectReasonLength))
+	}
+	return reason, nil
+}
+
+// GetQuestComments возвращает обсуждение отправки квеста.
+func (s *Discipline) GetQuestComments(ctx context.Context, statusID, userID uint) (*[]types.TQuestComment, error) {
+	status, err := s.questStatusForUser(ctx, statusID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.store.GetQuestComments(ctx, status.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest comments: %w", err)
+	}
+	result := commentsToView(*comments, status.PlayerID)
+	return &result, nil
+}
+
+// AddQuestComment добавляет сообщение мастера или игрока в обсуждение отправки квеста.
+func (s *Discipline) AddQuestComment(ctx context.Context, statusID, userID uint, text string) (*types.TQuestComment, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, apperr.ErrCommentEmpty.WithField("text", "Напишите сообщение")
+	}
+	if utf8.RuneCountInString(text) > maxQuestCommentLength {
+		return nil, apperr.ErrCommentLength.
+			WithField("text", fmt.Sprintf("Сообщение должно быть не длиннее %d символов", maxQuestCommentLength))
+	}
+
+	status, err := s.questStatusForUser(ctx, statusID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.store.NewQuestComment(ctx, &models.QuestComment{
+		QuestPlayerStatusID: status.ID,
+		AuthorID:            userID,
+		Text:                text,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed add quest comment: %w", err)
+	}
+	result := commentToView(comment, status.PlayerID)
+	return &result, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -17,35 +19,106 @@ import (
 
 var (
 	defaultFormDateTimeFormat = "2006-01-02T15:04"
+	defaultViewDateTimeFormat = "02.01.2006 15:04"
 )
 
 type Store interface {
 	NewUser(ctx context.Context, login, passwordHash string) (*models.User, error)
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
 	GetUserByID(ctx context.Context, userID uint) (*models.User, error)
-	GetUsers(ctx context.Context) (*[]models.User, error)
+	FindUsers(ctx context.Context, query string, offset, limit int) (*[]models.User, int64, error)
+	GetUserStats(ctx context.Context) (*models.UserStats, error)
+	GetRoles(ctx context.Context) (*[]models.Role, error)
+	SetUserRoles(ctx context.Context, userID uint, roleIDs []uint) error
+	SetUserLocked(ctx context.Context, userID uint, lockedAt *time.Time) error
+	SetUserPassword(ctx context.Context, userID uint, passwordHash string, mustChange bool) error
+	SetUserTimeZone(ctx context.Context, userID uint, timeZone string) error
+	SetUserActiveMaster(ctx context.Context, userID uint, masterID *uint) error
 	GetMasterByID(ctx context.Context, masterID uint) (*models.UserMaster, error)
 	NewQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error)
 	GetQuest(ctx context.Context, questID uint) (*models.Quest, error)
-	GetQuests(ctx context.Context, userID uint) (*[]models.Quest, error)
+	GetQuests(ctx context.Context, masterID uint) (*[]models.Quest, error)
 	UpdQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error)
-	GetAwaitQuests(ctx context.Context, userID uint) (*[]models.QuestPlayerStatus, error)
+	GetAwaitQuests(ctx context.Context, masterID uint) (*[]models.QuestPlayerStatus, error)
 	GetAwaitQUest(ctx context.Context, awaitID uint) (*models.QuestPlayerStatus, error)
-	UpdAwaitQuest(ctx context.Context, status *models.QuestPlayerStatus) (*models.QuestPlayerStatus, error)
-	NewMaster(ctx context.Context, master *models.User) (*models.UserMaster, error)
+	UpdAwaitQuest(ctx context.Context, status *models.QuestPlayerStatus, penalty *models.QuestPenalty, comment string) error
+	NewMaster(ctx context.Context, master *models.UserMaster) (*models.UserMaster, error)
+	UpdMasterName(ctx context.Context, masterID uint, name string) error
 	AddPlayerForMaster(ctx context.Context, masterID, playerID uint) error
+	GetMasterPlayerJoinedAt(ctx context.Context, masterID, playerID uint) (*time.Time, error)
+
+	GetMasterMember(ctx context.Context, masterID, userID uint) (*models.MasterMember, error)
+	GetMasterMembers(ctx context.Context, masterID uint) (*[]models.MasterMember, error)
+	SetMemberRole(ctx context.Context, masterID, userID uint, role models.MemberRole) error
+	DeleteMasterMember(ctx context.Context, masterID, userID uint) error
+	NewMasterInvite(ctx context.Context, invite *models.MasterInvite) (*models.MasterInvite, error)
+	GetMasterInvite(ctx context.Context, code string, now time.Time) (*models.MasterInvite, error)
+	GetMasterInvites(ctx context.Context, masterID uint, now time.Time) (*[]models.MasterInvite, error)
+	DeleteMasterInvite(ctx context.Context, masterID, inviteID uint) error
+	AcceptMasterInvite(ctx context.Context, code string, userID uint, now time.Time) (*models.MasterMember, error)
 
 	GetPlayerQuests(ctx context.Context, playerID uint) (*[]models.Quest, error)
 	GetPlayerQuest(ctx context.Context, questID, playerID uint) (*models.Quest, error)
-	GetPlayerQuestStatus(ctx context.Context, questID, playerID uint) (*models.QuestPlayerStatus, error)
-	SendPlayerQuest(ctx context.Context, questID, playerID uint) (*models.QuestPlayerStatus, error)
+	GetPlayerQuestStatus(ctx context.Context, questID, playerID uint, period string) (*models.QuestPlayerStatus, error)
+	SendPlayerQuest(ctx context.Context, questID, playerID uint, period, comment string, attachments []models.ProofAttachment) (*models.QuestPlayerStatus, error)
 	UpdSendStatusPlayerQuest(ctx context.Context, status *models.QuestPlayerStatus) (*models.QuestPlayerStatus, error)
+	GetProofAttachment(ctx context.Context, attachmentID uint) (*models.ProofAttachment, error)
+	GetQuestComments(ctx context.Context, statusID uint) (*[]models.QuestComment, error)
+	NewQuestComment(ctx context.Context, comment *models.QuestComment) (*models.QuestComment, error)
 	GetMasterByCode(ctx context.Context, code string) (*models.UserMaster, error)
 	GetMastersByPlayerID(ctx context.Context, playerID uint) (*[]models.UserMaster, error)
 	GetWallets(ctx context.Context, playerID uint) (*[]models.PlayerWallet, error)
 
 	GetQuestNotPayed(ctx context.Context) (*[]models.QuestPlayerStatus, error)
-	PayQuest(ctx context.Context, quest *models.QuestPlayerStatus) error
+	PayQuest(ctx context.Context, quest *models.QuestPlayerStatus, onStreak StreakFunc) error
+	GetPenaltyQuests(ctx context.Context) (*[]models.Quest, error)
+	FindQuestStatuses(ctx context.Context, questID, playerID uint, periodPattern string) (*[]models.QuestPlayerStatus, error)
+	ChargePenalty(ctx context.Context, penalty *models.QuestPenalty, comment string) (bool, error)
+	GetQuestStreak(ctx context.Context, questID, playerID uint) (*models.QuestStreak, error)
+	GetStreakBonuses(ctx context.Context, questID uint) (*[]models.QuestStreakBonus, error)
+
+	NewReward(ctx context.Context, reward *models.Reward) (*models.Reward, error)
+	GetReward(ctx context.Context, rewardID uint) (*models.Reward, error)
+	GetRewards(ctx context.Context, masterID uint) (*[]models.Reward, error)
+	UpdReward(ctx context.Context, reward *models.Reward) (*models.Reward, error)
+	GetPlayerRewards(ctx context.Context, playerID uint) (*[]models.Reward, error)
+	BuyReward(ctx context.Context, rewardID, playerID uint) (*models.RewardPurchase, error)
+	GetRewardPurchases(ctx context.Context, masterID uint) (*[]models.RewardPurchase, error)
+	GetPlayerPurchases(ctx context.Context, playerID uint) (*[]models.RewardPurchase, error)
+	GetRewardPurchase(ctx context.Context, purchaseID uint) (*models.RewardPurchase, error)
+	FulfilRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error
+	RefundRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error
+
+	GetWallet(ctx context.Context, walletID uint) (*models.PlayerWallet, error)
+	GetMasterWallets(ctx context.Context, masterID uint) (*[]models.PlayerWallet, error)
+	AdjustWallet(ctx context.Context, txn *models.WalletTransaction) (*models.WalletTransaction, error)
+	GetWalletTransaction(ctx context.Context, transactionID uint) (*models.WalletTransaction, error)
+	ReverseWalletTransaction(ctx context.Context, original *models.WalletTransaction, authorID uint) (*models.WalletTransaction, error)
+	GetWalletTransactions(ctx context.Context, walletID uint, offset, limit int) (*[]models.WalletTransaction, int64, error)
+	GetPlayerTransactions(ctx context.Context, playerID uint, offset, limit int) (*[]models.WalletTransaction, int64, error)
+	CountUnbalancedWallets(ctx context.Context) (int64, error)
+
+	NewSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	GetSession(ctx context.Context, sessionID string) (*models.Session, error)
+	GetUserSessions(ctx context.Context, userID uint, now time.Time) (*[]models.Session, error)
+	TouchSession(ctx context.Context, session *models.Session) error
+	RevokeSession(ctx context.Context, sessionID string) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, old *models.RefreshToken, next *models.RefreshToken) (bool, error)
+	RevokeUserTokens(ctx context.Context, userID uint) error
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StreakFunc пересчитывает серию игрока по ключам периодов всех оплаченных выполнений квеста,
+// включая оплачиваемое, и возвращает бонусы за достигнутые отметки. PayQuest вызывает ее
+// в транзакции оплаты, заблокировав серию.
+type StreakFunc = func(streak *models.QuestStreak, paidPeriods []string) []models.QuestStreakBonus
+
+// BlobStore - хранилище файлов вложений.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
 }
 
 var (
@@ -54,6 +127,7 @@ var (
 
 type Discipline struct {
 	store Store
+	blob  BlobStore
 	log   *zap.Logger
 }
 
@@ -65,16 +139,36 @@ func SetLogger(log *zap.Logger) option {
 	}
 }
 
+// SetBlobStore задает хранилище файлов подтверждений выполнения квестов.
+func SetBlobStore(blob BlobStore) option {
+	return func(d *Discipline) {
+		d.blob = blob
+	}
+}
+
 func New(ctx context.Context, store Store, options ...option) (*Discipline, error) {
 	d := &Discipline{
 		store: store,
+		log:   zap.NewNop(),
 	}
 
 	for _, opt := range options {
 		opt(d)
 	}
 
+	// баланс только проверяется: исправлять его здесь, без блокировки кошельков,
+	// значит гоняться с начислениями других реплик
+	unbalanced, err := d.store.CountUnbalancedWallets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed check wallets: %w", err)
+	}
+	if unbalanced > 0 {
+		d.log.Warn("wallet balances differ from ledger", zap.Int64("wallets", unbalanced))
+	}
+
 	go d.workerQuestPayer(ctx)
+	go d.workerQuestPenalty(ctx)
+	go d.workerTokenCleanup(ctx)
 
 	return d, nil
 }
@@ -96,7 +190,7 @@ func (s *Discipline) workerQuestPayer(ctx context.Context) {
 			}
 
 			for _, quest := range *quests {
-				if err := s.store.PayQuest(ctx, &quest); err != nil {
+				if err := s.store.PayQuest(ctx, &quest, streakOnPay(&quest)); err != nil {
 					s.log.Error("failed pay quest", zap.Error(err))
 				}
 			}
@@ -105,12 +199,19 @@ func (s *Discipline) workerQuestPayer(ctx context.Context) {
 }
 
 func (s *Discipline) Registration(ctx context.Context, login, password string) error {
+	login = strings.TrimSpace(login)
+	if err := s.validateRegistration(ctx, login, password); err != nil {
+		return err
+	}
 	passwordHash, err := tools.HashPassword(password)
 	if err != nil {
 		return fmt.Errorf("failed hashing password: %w", err)
 	}
 	_, err = s.store.NewUser(ctx, login, passwordHash)
 	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errLoginTaken(err)
+		}
 		return fmt.Errorf("failed create user: %w", err)
 	}
 	return nil
@@ -120,12 +221,53 @@ func (s *Discipline) Login(ctx context.Context, login, password string) (*models
 	user, err := s.store.GetUserByLogin(ctx, login)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.Join(err, apperr.ErrDataNotFound)
+			return nil, apperr.ErrInvalidCredentials.Wrap(err)
 		}
 		return nil, fmt.Errorf("failed get user: %w", err)
 	}
 	if !tools.CheckPasswordHash(password, user.PasswordHash) {
-		return nil, apperr.ErrDataNotFound
+		return nil, apperr.ErrInvalidCredentials
+	}
+	// блокировка проверяется после пароля, чтобы не раскрывать ее подбирающему пароль
+	if user.LockedAt != nil {
+		return nil, apperr.ErrAccountLocked
+	}
+	return user, nil
+}
+
+// ChangePassword меняет пароль пользователя userID после проверки текущего и снимает
+// требование сменить пароль после сброса. Остальные сессии пользователя завершаются,
+// вызывающий выдает новые токены текущему устройству по возвращенному пользователю.
+func (s *Discipline) ChangePassword(ctx context.Context, userID uint, current, password string) (*models.User, error) {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, "failed get user")
+	}
+	v := &validation{}
+	if !tools.CheckPasswordHash(current, user.PasswordHash) {
+		v.add("current_password", "Неверный текущий пароль")
+	}
+	validatePassword(v, password)
+	if password == current {
+		v.add("password", "Новый пароль должен отличаться от текущего")
+	}
+	if err := v.error(); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := tools.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed hashing password: %w", err)
+	}
+	if err := s.store.SetUserPassword(ctx, userID, passwordHash, false); err != nil {
+		return nil, notFound(err, "failed set password")
+	}
+	if err := s.store.RevokeUserTokens(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed revoke user tokens: %w", err)
+	}
+	user, err = s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, "failed get user")
 	}
 	return user, nil
 }
@@ -160,55 +302,30 @@ func (s *Discipline) GetPlayers(ctx context.Context, masterID uint) (*[]types.TQ
 	return &p, nil
 }
 
-func (s *Discipline) NewQuest(ctx context.Context, quest *types.TQuest, userID uint) (*models.Quest, error) {
-	q := &models.Quest{
-		Title:       quest.Title,
-		Description: quest.Description,
-		Type:        models.OneTime,
-		UserID:      userID,
-		Price:       quest.Price,
-		IsActive:    quest.IsActive,
-	}
-	if quest.DateStart != "" {
-		if date, err := time.Parse(defaultFormDateTimeFormat, quest.DateStart); err == nil {
-			date = date.Add(time.Duration(quest.TimeZoneOffset) * time.Minute)
-			q.StartTime = &date
-		}
-	}
-	if quest.DateEnd != "" {
-		if date, err := time.Parse(defaultFormDateTimeFormat, quest.DateEnd); err == nil {
-			date = date.Add(time.Duration(quest.TimeZoneOffset) * time.Minute)
-			q.EndTime = &date
-		}
+// NewQuest создает квест группы masterID, автор квеста - userID.
+func (s *Discipline) NewQuest(ctx context.Context, quest *types.TQuest, masterID, userID uint) (*models.Quest, error) {
+	if err := checkMaster(masterID); err != nil {
+		return nil, err
 	}
-
-	for _, t := range quest.Types {
-		if t.Selected {
-			q.Type = t.Value
-		}
-	}
-	for _, t := range quest.Players {
-		if t.Selected {
-			q.Players = append(q.Players, models.User{ID: t.ID})
-		}
+	q, err := s.questFromView(ctx, quest, masterID, userID)
+	if err != nil {
+		return nil, err
 	}
-	q, err := s.store.NewQuest(ctx, q)
+	q, err = s.store.NewQuest(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("failed create quest: %w", err)
 	}
 	return q, nil
 }
 
-func (s *Discipline) GetQuest(ctx context.Context, questID uint) (*types.TQuest, error) {
-	quest, err := s.store.GetQuest(ctx, questID)
+// GetQuest возвращает квест группы masterID для редактирования.
+func (s *Discipline) GetQuest(ctx context.Context, questID, masterID uint) (*types.TQuest, error) {
+	quest, err := s.masterQuest(ctx, questID, masterID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.Join(err, apperr.ErrDataNotFound)
-		}
-		return nil, fmt.Errorf("failed get quest: %w", err)
+		return nil, err
 	}
 
-	players, err := s.GetPlayers(ctx, quest.User.QuestMaster.ID)
+	players, err := s.GetPlayers(ctx, quest.UserMasterID)
 	if err != nil {
 		return nil, fmt.Errorf("failed get players:  %w", err)
 	}
@@ -219,6 +336,7 @@ func (s *Discipline) GetQuest(ctx context.Context, questID uint) (*types.TQuest,
 		Description: quest.Description,
 		IsActive:    quest.IsActive,
 		Price:       quest.Price,
+		Penalty:     quest.Penalty,
 		Types:       types.QuestTypes,
 		// Players:     *players,
 	}
@@ -244,12 +362,15 @@ func (s *Discipline) GetQuest(ctx context.Context, questID uint) (*types.TQuest,
 	for i := range q.Types {
 		q.Types[i].Selected = quest.Type == q.Types[i].Value
 	}
+	scheduleToView(q, quest.Schedule)
+	q.Schedule = scheduleTitle(quest)
+	q.StreakBonuses = streakBonusesToView(quest.StreakBonuses)
 
 	return q, nil
 }
 
-func (s *Discipline) GetQuests(ctx context.Context, userID uint) (*[]types.TQuest, error) {
-	qs, err := s.store.GetQuests(ctx, userID)
+func (s *Discipline) GetQuests(ctx context.Context, masterID uint) (*[]types.TQuest, error) {
+	qs, err := s.store.GetQuests(ctx, masterID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &[]types.TQuest{}, nil
@@ -263,6 +384,7 @@ func (s *Discipline) GetQuests(ctx context.Context, userID uint) (*[]types.TQues
 			Title:       q.Title,
 			Description: q.Description,
 			Price:       q.Price,
+			Penalty:     q.Penalty,
 			IsActive:    q.IsActive,
 			Players:     []types.TQuestPlayer{},
 		}
@@ -288,49 +410,24 @@ func (s *Discipline) GetQuests(ctx context.Context, userID uint) (*[]types.TQues
 			t.Selected = t.Value == q.Type
 			upQ.Types = append(upQ.Types, t)
 		}
+		scheduleToView(&upQ, q.Schedule)
+		upQ.Schedule = scheduleTitle(&q)
 		quests = append(quests, upQ)
 	}
 	return &quests, apperr.ErrDataNotFound
 }
 
-func (s *Discipline) EditQuest(ctx context.Context, quest *types.TQuest, userID uint) (*types.TQuest, error) {
-	q := &models.Quest{
-		Model:       gorm.Model{ID: quest.ID},
-		Title:       quest.Title,
-		Description: quest.Description,
-		UserID:      userID,
-		Price:       quest.Price,
-		IsActive:    quest.IsActive,
-	}
-	if quest.DateStart != "" {
-		if date, err := time.Parse(defaultFormDateTimeFormat, quest.DateStart); err == nil {
-			date = date.Add(time.Duration(quest.TimeZoneOffset) * time.Minute)
-			q.StartTime = &date
-		}
-	} else {
-		q.StartTime = nil
-	}
-	if quest.DateEnd != "" {
-		if date, err := time.Parse(defaultFormDateTimeFormat, quest.DateEnd); err == nil {
-			date = date.Add(time.Duration(quest.TimeZoneOffset) * time.Minute)
-			q.EndTime = &date
-		}
-	} else {
-		q.EndTime = nil
-	}
-	for _, t := range quest.Types {
-		if t.Selected {
-			q.Type = t.Value
-			break
-		}
+// EditQuest сохраняет квест группы masterID, автор квеста не меняется. Чужой квест не меняется.
+func (s *Discipline) EditQuest(ctx context.Context, quest *types.TQuest, masterID uint) (*types.TQuest, error) {
+	stored, err := s.masterQuest(ctx, quest.ID, masterID)
+	if err != nil {
+		return nil, err
 	}
-	for _, p := range quest.Players {
-		if p.Selected {
-			q.Players = append(q.Players, models.User{ID: p.ID})
-		}
+	q, err := s.questFromView(ctx, quest, masterID, stored.UserID)
+	if err != nil {
+		return nil, err
 	}
-
-	q, err := s.store.UpdQuest(ctx, q)
+	q, err = s.store.UpdQuest(ctx, q)
 	if err != nil {
 		return nil, fmt.Errorf("failed update quest: %w", err)
 	}
@@ -340,43 +437,57 @@ func (s *Discipline) EditQuest(ctx context.Context, quest *types.TQuest, userID
 	return quest, nil
 }
 
-func (s *Discipline) GetAwaitQuests(ctx context.Context, userID uint) (*[]types.TQuestAwait, error) {
+func (s *Discipline) GetAwaitQuests(ctx context.Context, masterID uint) (*[]types.TQuestAwait, error) {
 	result := []types.TQuestAwait{}
-	awaits, err := s.store.GetAwaitQuests(ctx, userID)
+	awaits, err := s.store.GetAwaitQuests(ctx, masterID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, fmt.Errorf("failed get await quests: %w", err)
 	}
 	for _, s := range *awaits {
 		result = append(result, types.TQuestAwait{
-			ID:          s.ID,
-			Title:       s.Quest.Title,
-			Description: s.Quest.Description,
-			PlayerName:  s.Player.Login,
-			Price:       s.Quest.Price,
+			ID:           s.ID,
+			Title:        s.Quest.Title,
+			Description:  s.Quest.Description,
+			PlayerName:   s.Player.Login,
+			Price:        s.Quest.Price,
+			Period:       s.PeriodKey,
+			ProofComment: s.ProofComment,
+			Attachments:  attachmentsToView(s.Attachments),
+			Comments:     commentsToView(s.Comments, s.PlayerID),
 		})
 	}
 
 	return &result, nil
 }
 
-func (s *Discipline) ManageQuestConfirmation(ctx context.Context, statusID, userID uint, confirm bool) error {
-	status, err := s.store.GetAwaitQUest(ctx, statusID)
+// ManageQuestConfirmation подтверждает или возвращает квест игроку.
+// При возврате мастер может указать причину, ее увидит игрок.
+// Проверить можно только отправку, которая ждет решения мастера.
+func (s *Discipline) ManageQuestConfirmation(ctx context.Context, statusID, masterID uint, confirm bool, reason string) error {
+	reason, err := validateRejectReason(reason)
 	if err != nil {
-		return fmt.Errorf("failed get await quest: %w", err)
+		return err
 	}
 
-	if status.Quest.UserID != userID {
-		return apperr.ErrDataNotFound
+	status, err := s.masterStatus(ctx, statusID, masterID)
+	if err != nil {
+		return err
+	}
+	if status.ConfirmationDate != nil || isRejected(status) {
+		return apperr.ErrQuestStatusClosed
 	}
 
+	var penalty *models.QuestPenalty
 	currentTime := time.Now().UTC()
 	if confirm {
 		status.ConfirmationDate = &currentTime
 	} else {
 		status.RejectExecuteDate = &currentTime
+		status.RejectReason = reason
+		penalty = rejectPenalty(status)
 	}
 
-	_, err = s.store.UpdAwaitQuest(ctx, status)
+	err = s.store.UpdAwaitQuest(ctx, status, penalty, fmt.Sprintf("%s: выполнение отклонено", status.Quest.Title))
 	if err != nil {
 		return fmt.Errorf("failed update status quest: %w", err)
 	}
@@ -384,28 +495,63 @@ func (s *Discipline) ManageQuestConfirmation(ctx context.Context, statusID, user
 	return nil
 }
 
-func (s *Discipline) ManageCreateSelfMaster(ctx context.Context, userID uint) (*models.User, error) {
-	user, err := s.store.GetUserByID(ctx, userID)
+// ManageCreateSelfMaster создает пользователю userID новую группу name и делает ее активной.
+// Пользователь может владеть несколькими группами, пустое имя заменяется defaultMasterName.
+func (s *Discipline) ManageCreateSelfMaster(ctx context.Context, userID uint, name string) (*models.UserMaster, error) {
+	name, err := validateMasterName(name, defaultMasterName)
 	if err != nil {
-		return nil, fmt.Errorf("failed get user by id: %w", err)
+		return nil, err
 	}
-	user.Roles = append(user.Roles, models.RoleQuestMasterObject)
-	code := tools.RandomString(lengthMasterCode)
-	user.QuestMaster = &models.UserMaster{
-		UserID:     userID,
-		UniqueCode: code,
+	if _, err := s.store.GetUserByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed get user by id: %w", err)
 	}
-	master, err := s.store.NewMaster(ctx, user)
+
+	master, err := s.newMaster(ctx, userID, name)
 	if err != nil {
-		return nil, fmt.Errorf("failed create quest master: %w", err)
+		return nil, err
+	}
+	if err := s.store.SetUserActiveMaster(ctx, userID, &master.ID); err != nil {
+		return nil, fmt.Errorf("failed set active master: %w", err)
+	}
+	return master, nil
+}
+
+// applyPlayerStatus переносит состояние выполнения квеста за период в представление игрока.
+func applyPlayerStatus(quest *types.TPlayerQuest, status *models.QuestPlayerStatus) {
+	if status == nil {
+		return
 	}
+	quest.IsSended = status.RequestExecuteDate != nil
+	quest.IsRejected = status.RejectExecuteDate != nil
+	quest.IsConfirmed = status.ConfirmationDate != nil
+	quest.StatusID = status.ID
+	quest.ProofComment = status.ProofComment
+	quest.Attachments = attachmentsToView(status.Attachments)
+	quest.RejectReason = status.RejectReason
+	quest.Comments = commentsToView(status.Comments, status.PlayerID)
 
-	user, err = s.store.GetUserByID(ctx, master.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("failed get user: %w", err)
+	if status.RejectExecuteDate != nil && status.RequestExecuteDate != nil {
+		quest.IsSended = status.RequestExecuteDate.After(*status.RejectExecuteDate)
+		quest.IsRejected = status.RejectExecuteDate.After(*status.RequestExecuteDate)
 	}
+}
 
-	return user, nil
+func playerQuestToView(q *models.Quest, state *periodState) types.TPlayerQuest {
+	quest := types.TPlayerQuest{
+		ID:          q.ID,
+		Title:       q.Title,
+		Description: q.Description,
+		Price:       q.Price,
+		Penalty:     q.Penalty,
+		IsRecurring: q.Type != models.OneTime,
+		Period:      state.Period,
+		Schedule:    scheduleTitle(q),
+		IsScheduled: state.Scheduled,
+		Done:        state.Done,
+		Total:       state.Total,
+	}
+	applyPlayerStatus(&quest, state.Status)
+	return quest
 }
 
 func (s *Discipline) GetQuestsPlayer(ctx context.Context, playerID uint) (*[]types.TPlayerQuest, error) {
@@ -418,28 +564,22 @@ func (s *Discipline) GetQuestsPlayer(ctx context.Context, playerID uint) (*[]typ
 		return nil, fmt.Errorf("failed get quests by player `%v`: %w", playerID, err)
 	}
 
+	now := time.Now()
+	loc := s.playerLocation(ctx, playerID)
 	for _, q := range *data {
-		quest := types.TPlayerQuest{
-			ID:          q.ID,
-			Title:       q.Title,
-			Description: q.Description,
-			Price:       q.Price,
-		}
-		status, err := s.store.GetPlayerQuestStatus(ctx, q.ID, playerID)
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		state, err := s.questState(ctx, &q, playerID, now, loc)
+		if err != nil {
 			return nil, fmt.Errorf("failed get quest player: %w", err)
 		}
-		if status != nil {
-			quest.IsSended = status.RequestExecuteDate != nil
-			quest.IsRejected = status.RejectExecuteDate != nil
-			quest.IsConfirmed = status.ConfirmationDate != nil
-
-			if status.RejectExecuteDate != nil && status.RequestExecuteDate != nil {
-				quest.IsSended = status.RequestExecuteDate.After(*status.RejectExecuteDate)
-				quest.IsRejected = status.RejectExecuteDate.After(*status.RequestExecuteDate)
-			}
+		if !state.Scheduled {
+			continue
 		}
 
+		quest := playerQuestToView(&q, state)
+		err = s.applyPlayerStreak(ctx, &quest, &q, playerID, now, loc)
+		if err != nil {
+			return nil, fmt.Errorf("failed get quest player streak: %w", err)
+		}
 		quests = append(quests, quest)
 	}
 
@@ -449,6 +589,9 @@ func (s *Discipline) GetQuestsPlayer(ctx context.Context, playerID uint) (*[]typ
 func (s *Discipline) AddMaster(ctx context.Context, masterCode string, playerID uint) error {
 	master, err := s.store.GetMasterByCode(ctx, masterCode)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperr.ErrMasterNotFound.Wrap(err)
+		}
 		return fmt.Errorf("failed get master by code `%s`: %w", masterCode, err)
 	}
 
@@ -469,63 +612,78 @@ func (s *Discipline) GetQuestPlayer(ctx context.Context, questID uint, playerID
 		return nil, fmt.Errorf("failed get quest player: %w", err)
 	}
 
-	playerQuests := &types.TPlayerQuest{
-		ID:          quest.ID,
-		Title:       quest.Title,
-		Description: quest.Description,
-		Price:       quest.Price,
+	now := time.Now()
+	loc := s.playerLocation(ctx, playerID)
+	state, err := s.questState(ctx, quest, playerID, now, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest player: %w", err)
+	}
+	playerQuests := playerQuestToView(quest, state)
+	err = s.applyPlayerStreak(ctx, &playerQuests, quest, playerID, now, loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed get quest player streak: %w", err)
 	}
 
-	status, err := s.store.GetPlayerQuestStatus(ctx, questID, playerID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed get quest player: %w", err)
+	return &playerQuests, nil
+}
+
+func (s *Discipline) SendQuestPlayer(ctx context.Context, questID, playerID uint, proof *types.TProof) (*types.TPlayerQuest, error) {
+	if err := validateProof(proof); err != nil {
+		return nil, err
 	}
-	if status != nil {
-		playerQuests.IsSended = status.RequestExecuteDate != nil
-		playerQuests.IsRejected = status.RejectExecuteDate != nil
-		playerQuests.IsConfirmed = status.ConfirmationDate != nil
 
-		if status.RejectExecuteDate != nil && status.RequestExecuteDate != nil {
-			playerQuests.IsSended = status.RequestExecuteDate.After(*status.RejectExecuteDate)
-			playerQuests.IsRejected = status.RejectExecuteDate.After(*status.RequestExecuteDate)
+	quest, err := s.store.GetPlayerQuest(ctx, questID, playerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Join(err, apperr.ErrDataNotFound)
 		}
+		return nil, fmt.Errorf("failed get quest player: %w", err)
 	}
 
-	return playerQuests, nil
-}
-
-func (s *Discipline) SendQuestPlayer(ctx context.Context, questID, playerID uint) (*types.TPlayerQuest, error) {
-	status, err := s.store.GetPlayerQuestStatus(ctx, questID, playerID)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	state, err := s.questState(ctx, quest, playerID, time.Now(), s.playerLocation(ctx, playerID))
+	if err != nil {
 		return nil, fmt.Errorf("failed get quest player: %w", err)
 	}
+	if !state.Scheduled {
+		return nil, apperr.ErrQuestNotScheduled
+	}
+	if !state.CanSend() {
+		return nil, apperr.ErrPlayerQuestStatusExists
+	}
 
-	if status != nil && status.ID != 0 {
-		if status.RejectExecuteDate != nil && status.RequestExecuteDate != nil {
-			if status.RejectExecuteDate.Before(*status.RequestExecuteDate) {
-				return nil, apperr.ErrPlayerQuestStatusExists
-			}
-		}
-		status, err = s.store.UpdSendStatusPlayerQuest(ctx, status)
+	attachments, err := s.storeProofFiles(ctx, questID, playerID, proof.Files)
+	if err != nil {
+		return nil, fmt.Errorf("failed store proof files: %w", err)
+	}
+
+	var status *models.QuestPlayerStatus
+	if state.Status != nil {
+		previous := state.Status.Attachments
+		state.Status.ProofComment = proof.Comment
+		state.Status.Attachments = attachments
+		status, err = s.store.UpdSendStatusPlayerQuest(ctx, state.Status)
 		if err != nil {
+			s.deleteProofFiles(ctx, attachments)
 			return nil, fmt.Errorf("failed update send status quest: %w", err)
 		}
+		s.deleteProofFiles(ctx, previous)
 	} else {
-		status, err = s.store.SendPlayerQuest(ctx, questID, playerID)
+		status, err = s.store.SendPlayerQuest(ctx, questID, playerID, state.Period, proof.Comment, attachments)
 		if err != nil {
+			s.deleteProofFiles(ctx, attachments)
+			// параллельная отправка за тот же период уже записана
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return nil, errors.Join(err, apperr.ErrPlayerQuestStatusExists)
+			}
 			return nil, fmt.Errorf("failed send quest player: %w", err)
 		}
 	}
+	state.Status = status
+	state.Done++
 
-	result := &types.TPlayerQuest{
-		ID:          status.Quest.ID,
-		Title:       status.Quest.Title,
-		Description: status.Quest.Description,
-		Price:       status.Quest.Price,
-		IsSended:    true,
-	}
+	result := playerQuestToView(quest, state)
 
-	return result, nil
+	return &result, nil
 }
 
 func (s *Discipline) GetPlayerMasters(ctx context.Context, playerID uint) (*[]models.UserMaster, error) {
@@ -550,10 +708,18 @@ func (s *Discipline) GetWallets(ctx context.Context, playerID uint) (*[]types.TP
 	}
 	result := []types.TPlayerWaller{}
 	for _, w := range *wallets {
-		result = append(result, types.TPlayerWaller{
-			Score:    w.Prise,
-			PlayerID: w.PlayerID,
-		})
+		result = append(result, walletToView(&w))
 	}
 	return &result, nil
 }
+
+func (s *Discipline) SetTimeZone(ctx context.Context, userID uint, timeZone string) error {
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" || timeZone == "Local" {
+		return errors.Join(err, apperr.ErrInvalidTimeZone)
+	}
+	err := s.store.SetUserTimeZone(ctx, userID, timeZone)
+	if err != nil {
+		return fmt.Errorf("failed set time zone: %w", err)
+	}
+	return nil
+}
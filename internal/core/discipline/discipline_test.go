@@ -0,0 +1,861 @@
+package discipline_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/core/discipline"
+	"github.com/mod-develop/backend/internal/core/discipline/disciplinetest"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func newDiscipline(t *testing.T) *discipline.Discipline {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	d, err := discipline.New(ctx, disciplinetest.NewStore())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return d
+}
+
+func TestRegistration(t *testing.T) {
+	d := newDiscipline(t)
+	ctx := context.Background()
+	if err := d.Registration(ctx, " player ", "secret123"); err != nil {
+		t.Fatalf("Registration() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		login    string
+		password string
+		wantErr  *apperr.Error
+		field    string
+	}{
+		{name: "empty login", login: "", password: "secret123", wantErr: apperr.ErrValidation, field: "login"},
+		{name: "empty password", login: "player2", password: "", wantErr: apperr.ErrValidation, field: "password"},
+		{name: "short login", login: "ab", password: "secret123", wantErr: apperr.ErrValidation, field: "login"},
+		{name: "invalid login", login: "игрок", password: "secret123", wantErr: apperr.ErrValidation, field: "login"},
+		{name: "short password", login: "player2", password: "abc1", wantErr: apperr.ErrValidation, field: "password"},
+		{name: "password without digits", login: "player2", password: "password", wantErr: apperr.ErrValidation, field: "password"},
+		{name: "login taken in other case", login: "PLAYER", password: "secret123", wantErr: apperr.ErrLoginTaken, field: "login"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.Registration(ctx, tt.login, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Registration() error = %v, want %v", err, tt.wantErr)
+			}
+			appErr, _ := apperr.As(err)
+			if _, ok := appErr.Details[tt.field]; !ok {
+				t.Errorf("Registration() details = %v, want field %s", appErr.Details, tt.field)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	d := newDiscipline(t)
+	ctx := context.Background()
+	if err := d.Registration(ctx, "player", "secret123"); err != nil {
+		t.Fatalf("Registration() error = %v", err)
+	}
+
+	user, err := d.Login(ctx, "Player", "secret123")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if user.Login != "player" {
+		t.Errorf("Login() user = %s, want player", user.Login)
+	}
+	if _, err := d.Login(ctx, "player", "wrong123"); !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Errorf("Login() wrong password error = %v, want apperr.ErrInvalidCredentials", err)
+	}
+	if _, err := d.Login(ctx, "nobody", "secret123"); !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Errorf("Login() unknown login error = %v, want apperr.ErrInvalidCredentials", err)
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	user, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	sessionID, first, err := d.StartSession(ctx, user.ID, "browser", "10.0.0.1", time.Hour)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	got, gotSession, second, err := d.RefreshSession(ctx, first, "10.0.0.2", time.Hour)
+	if err != nil {
+		t.Fatalf("RefreshSession() error = %v", err)
+	}
+	if got.ID != user.ID || gotSession != sessionID || second == first {
+		t.Errorf("RefreshSession() = user %d, session %q, same token %v, want user %d, session %q and new token",
+			got.ID, gotSession, second == first, user.ID, sessionID)
+	}
+	if _, _, _, err := d.RefreshSession(ctx, first, "", time.Hour); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("RefreshSession() with used token error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+	if _, _, _, err := d.RefreshSession(ctx, "unknown", "", time.Hour); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("RefreshSession() with unknown token error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+	_, expired, err := d.StartSession(ctx, user.ID, "", "", -time.Minute)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if _, _, _, err := d.RefreshSession(ctx, expired, "", time.Hour); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("RefreshSession() with expired session error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+
+	sessions, err := d.GetSessions(ctx, user.ID, sessionID)
+	if err != nil {
+		t.Fatalf("GetSessions() error = %v", err)
+	}
+	if len(*sessions) != 1 || !(*sessions)[0].Current || (*sessions)[0].IP != "10.0.0.2" {
+		t.Errorf("GetSessions() = %+v, want current session from 10.0.0.2", *sessions)
+	}
+	other, err := store.NewUser(ctx, "other", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := d.RevokeSession(ctx, other.ID, sessionID); !errors.Is(err, apperr.ErrDataNotFound) {
+		t.Errorf("RevokeSession() of other user session error = %v, want %v", err, apperr.ErrDataNotFound)
+	}
+	if _, err := d.CheckAccessToken(ctx, user.ID, sessionID, 0, "10.0.0.2"); err != nil {
+		t.Fatalf("CheckAccessToken() error = %v", err)
+	}
+	if err := d.RevokeSession(ctx, user.ID, sessionID); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if _, err := d.CheckAccessToken(ctx, user.ID, sessionID, 0, "10.0.0.2"); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("CheckAccessToken() of revoked session error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+	if _, _, _, err := d.RefreshSession(ctx, second, "", time.Hour); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("RefreshSession() of revoked session error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+
+	sessionID, refresh, err := d.StartSession(ctx, user.ID, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	if err := d.LogoutAll(ctx, user.ID); err != nil {
+		t.Fatalf("LogoutAll() error = %v", err)
+	}
+	if _, err := d.CheckAccessToken(ctx, user.ID, sessionID, 0, ""); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("CheckAccessToken() after LogoutAll error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+	if _, _, _, err := d.RefreshSession(ctx, refresh, "", time.Hour); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("RefreshSession() after LogoutAll error = %v, want %v", err, apperr.ErrUnauthorized)
+	}
+}
+
+// TestCrossMasterAccess проверяет, что мастер не видит и не меняет квесты, отправки,
+// кошельки и награды другого мастера.
+func TestCrossMasterAccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	newMaster := func(login string) (uint, uint, string) {
+		t.Helper()
+		user, err := store.NewUser(ctx, login, "hash")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		master, err := d.ManageCreateSelfMaster(ctx, user.ID, "")
+		if err != nil {
+			t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+		}
+		return user.ID, master.ID, master.UniqueCode
+	}
+	ownerID, ownerMasterID, ownerCode := newMaster("owner")
+	otherID, otherMasterID, _ := newMaster("other")
+	player, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := d.AddMaster(ctx, ownerCode, player.ID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+
+	quest, err := d.NewQuest(ctx, &types.TQuest{Title: "Зарядка", Price: 10, IsActive: true}, ownerMasterID, ownerID)
+	if err != nil {
+		t.Fatalf("NewQuest() error = %v", err)
+	}
+	sent, err := d.SendQuestPlayer(ctx, quest.ID, player.ID, &types.TProof{})
+	if err != nil {
+		t.Fatalf("SendQuestPlayer() error = %v", err)
+	}
+	if err := d.AdjustWallet(ctx, ownerMasterID, player.ID, ownerID, 50, "бонус"); err != nil {
+		t.Fatalf("AdjustWallet() error = %v", err)
+	}
+	wallets, err := d.GetMasterWallets(ctx, ownerMasterID)
+	if err != nil || len(*wallets) != 1 {
+		t.Fatalf("GetMasterWallets() = %v, %v, want one wallet", wallets, err)
+	}
+	walletID := (*wallets)[0].ID
+	history, err := d.GetMasterWalletHistory(ctx, walletID, ownerMasterID, 1)
+	if err != nil || len(history.Transactions) == 0 {
+		t.Fatalf("GetMasterWalletHistory() = %v, %v, want transactions", history, err)
+	}
+	transactionID := history.Transactions[0].ID
+	reward, err := d.NewReward(ctx, &types.TReward{Title: "Кино", Cost: 10, IsUnlimited: true, IsActive: true}, ownerMasterID)
+	if err != nil {
+		t.Fatalf("NewReward() error = %v", err)
+	}
+	if err := d.BuyReward(ctx, reward.ID, player.ID); err != nil {
+		t.Fatalf("BuyReward() error = %v", err)
+	}
+	purchases, err := d.GetRewardPurchases(ctx, ownerMasterID)
+	if err != nil || len(*purchases) != 1 {
+		t.Fatalf("GetRewardPurchases() = %v, %v, want one purchase", purchases, err)
+	}
+	purchaseID := (*purchases)[0].ID
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr *apperr.Error
+	}{
+		{name: "get quest", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.GetQuest(ctx, quest.ID, otherMasterID)
+			return err
+		}},
+		{name: "edit quest", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.EditQuest(ctx, &types.TQuest{ID: quest.ID, Title: "Чужой"}, otherMasterID)
+			return err
+		}},
+		{name: "confirm submission", wantErr: apperr.ErrDataNotFound, call: func() error {
+			return d.ManageQuestConfirmation(ctx, sent.StatusID, otherMasterID, true, "")
+		}},
+		{name: "read comments", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.GetQuestComments(ctx, sent.StatusID, otherID)
+			return err
+		}},
+		{name: "add comment", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.AddQuestComment(ctx, sent.StatusID, otherID, "Привет")
+			return err
+		}},
+		{name: "wallet history", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.GetMasterWalletHistory(ctx, walletID, otherMasterID, 1)
+			return err
+		}},
+		{name: "adjust other player wallet", wantErr: apperr.ErrDataNotFound, call: func() error {
+			return d.AdjustWallet(ctx, otherMasterID, player.ID, otherID, 100, "")
+		}},
+		{name: "reverse transaction", wantErr: apperr.ErrDataNotFound, call: func() error {
+			return d.ReverseWalletTransaction(ctx, transactionID, otherMasterID, otherID)
+		}},
+		{name: "get reward", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.GetReward(ctx, reward.ID, otherMasterID)
+			return err
+		}},
+		{name: "edit reward", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.EditReward(ctx, &types.TReward{ID: reward.ID, Title: "Чужая", Cost: 1}, otherMasterID)
+			return err
+		}},
+		{name: "close purchase", wantErr: apperr.ErrDataNotFound, call: func() error {
+			return d.ManageRewardPurchase(ctx, purchaseID, otherMasterID, true)
+		}},
+		{name: "unknown quest", wantErr: apperr.ErrDataNotFound, call: func() error {
+			_, err := d.GetQuest(ctx, 1000, ownerMasterID)
+			return err
+		}},
+		{name: "quest without master", wantErr: apperr.ErrForbidden, call: func() error {
+			_, err := d.NewQuest(ctx, &types.TQuest{Title: "Зарядка", Price: 10}, 0, otherID)
+			return err
+		}},
+		{name: "reward without master", wantErr: apperr.ErrForbidden, call: func() error {
+			_, err := d.NewReward(ctx, &types.TReward{Title: "Кино", Cost: 10}, 0)
+			return err
+		}},
+		{name: "adjust without master", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.AdjustWallet(ctx, 0, player.ID, otherID, 100, "")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := d.GetQuest(ctx, quest.ID, ownerMasterID)
+	if err != nil || got.Title != "Зарядка" {
+		t.Errorf("GetQuest() by owner = %+v, %v, want unchanged quest", got, err)
+	}
+}
+
+// TestCoMaster проверяет, что участники группы управляют квестами владельца по своей роли,
+// а участниками и приглашениями управляет только владелец.
+func TestRewardValidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	user, err := store.NewUser(ctx, "master", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	master, err := d.ManageCreateSelfMaster(ctx, user.ID, "")
+	if err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+	created, err := d.NewReward(ctx, &types.TReward{Title: " Кино ", Cost: 10, IsUnlimited: true}, master.ID)
+	if err != nil {
+		t.Fatalf("NewReward() error = %v", err)
+	}
+	if created.Title != "Кино" {
+		t.Errorf("NewReward() title = %q, want trimmed Кино", created.Title)
+	}
+
+	tests := []struct {
+		name   string
+		reward types.TReward
+		fields []string
+	}{
+		{name: "empty title", reward: types.TReward{Title: "  ", Cost: 10}, fields: []string{"title"}},
+		{name: "long title", reward: types.TReward{Title: strings.Repeat("а", 101), Cost: 10}, fields: []string{"title"}},
+		{name: "zero cost", reward: types.TReward{Title: "Кино"}, fields: []string{"cost"}},
+		{name: "cost too big", reward: types.TReward{Title: "Кино", Cost: 100001}, fields: []string{"cost"}},
+		{name: "all fields", reward: types.TReward{Description: strings.Repeat("а", 2001)}, fields: []string{"title", "description", "cost"}},
+	}
+	for _, tt := range tests {
+		_, newErr := d.NewReward(ctx, &tt.reward, master.ID)
+		edit := tt.reward
+		edit.ID = created.ID
+		_, editErr := d.EditReward(ctx, &edit, master.ID)
+		for name, err := range map[string]error{"NewReward": newErr, "EditReward": editErr} {
+			appErr, _ := apperr.As(err)
+			if !errors.Is(err, apperr.ErrValidation) || len(appErr.Details) != len(tt.fields) {
+				t.Errorf("%s: %s() error = %v, want validation of %v", tt.name, name, err, tt.fields)
+				continue
+			}
+			for _, field := range tt.fields {
+				if appErr.Details[field] == "" {
+					t.Errorf("%s: %s() details = %v, want field %s", tt.name, name, appErr.Details, field)
+				}
+			}
+		}
+	}
+
+	reward, err := d.GetReward(ctx, created.ID, master.ID)
+	if err != nil {
+		t.Fatalf("GetReward() error = %v", err)
+	}
+	if reward.Title != "Кино" || reward.Cost != 10 {
+		t.Errorf("GetReward() = %+v, want reward unchanged by rejected edits", reward)
+	}
+}
+
+func TestQuestConfirmation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	owner, err := store.NewUser(ctx, "owner", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	master, err := d.ManageCreateSelfMaster(ctx, owner.ID, "")
+	if err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+	player, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := d.AddMaster(ctx, master.UniqueCode, player.ID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+	quest, err := d.NewQuest(ctx, &types.TQuest{Title: "Зарядка", Price: 10, Penalty: 3, IsActive: true}, master.ID, owner.ID)
+	if err != nil {
+		t.Fatalf("NewQuest() error = %v", err)
+	}
+	sent, err := d.SendQuestPlayer(ctx, quest.ID, player.ID, &types.TProof{})
+	if err != nil {
+		t.Fatalf("SendQuestPlayer() error = %v", err)
+	}
+
+	steps := []struct {
+		name    string
+		resend  bool
+		confirm bool
+		wantErr error
+	}{
+		{name: "reject", confirm: false},
+		{name: "reject twice", confirm: false, wantErr: apperr.ErrQuestStatusClosed},
+		{name: "confirm rejected", confirm: true, wantErr: apperr.ErrQuestStatusClosed},
+		{name: "confirm resent", resend: true, confirm: true},
+		{name: "reject confirmed", confirm: false, wantErr: apperr.ErrQuestStatusClosed},
+	}
+	for _, step := range steps {
+		if step.resend {
+			if _, err := d.SendQuestPlayer(ctx, quest.ID, player.ID, &types.TProof{}); err != nil {
+				t.Fatalf("%s: SendQuestPlayer() error = %v", step.name, err)
+			}
+		}
+		err := d.ManageQuestConfirmation(ctx, sent.StatusID, master.ID, step.confirm, "")
+		if !errors.Is(err, step.wantErr) {
+			t.Errorf("%s: ManageQuestConfirmation() error = %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+
+	// штраф за отклонение списан один раз, оплата подтвержденного квеста еще не начислена
+	wallets, err := d.GetMasterWallets(ctx, master.ID)
+	if err != nil || len(*wallets) != 1 || (*wallets)[0].Score != -3 {
+		t.Errorf("GetMasterWallets() = %+v, %v, want one wallet with score -3", wallets, err)
+	}
+}
+
+func TestCoMaster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	newUser := func(login string) uint {
+		t.Helper()
+		user, err := store.NewUser(ctx, login, "hash")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		return user.ID
+	}
+	ownerID := newUser("owner")
+	master, err := d.ManageCreateSelfMaster(ctx, ownerID, "")
+	if err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+	masterID := master.ID
+	coMasterID, reviewerID, playerID := newUser("co"), newUser("reviewer"), newUser("player")
+	join := func(userID uint, role string) {
+		t.Helper()
+		invite, err := d.NewInvite(ctx, masterID, ownerID, role)
+		if err != nil {
+			t.Fatalf("NewInvite(%s) error = %v", role, err)
+		}
+		if err := d.AcceptInvite(ctx, invite.Code, userID); err != nil {
+			t.Fatalf("AcceptInvite(%s) error = %v", role, err)
+		}
+	}
+	join(coMasterID, string(models.MemberCoMaster))
+	join(reviewerID, string(models.MemberReviewer))
+	if err := d.AddMaster(ctx, master.UniqueCode, playerID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+
+	quest, err := d.NewQuest(ctx, &types.TQuest{Title: "Зарядка", Price: 10, IsActive: true}, masterID, coMasterID)
+	if err != nil {
+		t.Fatalf("NewQuest() by co-master error = %v", err)
+	}
+	if quests, err := d.GetQuests(ctx, masterID); (err != nil && !errors.Is(err, apperr.ErrDataNotFound)) || len(*quests) != 1 {
+		t.Errorf("GetQuests() = %v, %v, want quest of co-master in group", quests, err)
+	}
+	sent, err := d.SendQuestPlayer(ctx, quest.ID, playerID, &types.TProof{})
+	if err != nil {
+		t.Fatalf("SendQuestPlayer() error = %v", err)
+	}
+	comment, err := d.AddQuestComment(ctx, sent.StatusID, reviewerID, "Проверяю")
+	if err != nil || !comment.IsMaster {
+		t.Errorf("AddQuestComment() by reviewer = %+v, %v, want master comment", comment, err)
+	}
+	if err := d.ManageQuestConfirmation(ctx, sent.StatusID, masterID, true, ""); err != nil {
+		t.Errorf("ManageQuestConfirmation() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr *apperr.Error
+	}{
+		{name: "reviewer invites", wantErr: apperr.ErrForbidden, call: func() error {
+			_, err := d.NewInvite(ctx, masterID, reviewerID, string(models.MemberCoMaster))
+			return err
+		}},
+		{name: "invite as owner", wantErr: apperr.ErrValidation, call: func() error {
+			_, err := d.NewInvite(ctx, masterID, ownerID, string(models.MemberOwner))
+			return err
+		}},
+		{name: "co-master changes role", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.SetMemberRole(ctx, masterID, coMasterID, reviewerID, string(models.MemberCoMaster))
+		}},
+		{name: "co-master removes reviewer", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.RemoveMember(ctx, masterID, coMasterID, reviewerID)
+		}},
+		{name: "owner removes self", wantErr: apperr.ErrValidation, call: func() error {
+			return d.RemoveMember(ctx, masterID, ownerID, ownerID)
+		}},
+		{name: "stranger reads team", wantErr: apperr.ErrForbidden, call: func() error {
+			_, err := d.GetTeam(ctx, masterID, playerID)
+			return err
+		}},
+		{name: "member joins again", wantErr: apperr.ErrAlreadyMember, call: func() error {
+			invite, err := d.NewInvite(ctx, masterID, ownerID, string(models.MemberReviewer))
+			if err != nil {
+				return err
+			}
+			return d.AcceptInvite(ctx, invite.Code, coMasterID)
+		}},
+		{name: "co-master renames group", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.RenameMaster(ctx, masterID, coMasterID, "Чужая")
+		}},
+		{name: "unknown invite", wantErr: apperr.ErrInviteNotFound, call: func() error {
+			return d.AcceptInvite(ctx, "unknown", playerID)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := d.SetMemberRole(ctx, masterID, ownerID, coMasterID, string(models.MemberReviewer)); err != nil {
+		t.Errorf("SetMemberRole() by owner error = %v", err)
+	}
+	if err := d.RemoveMember(ctx, masterID, reviewerID, reviewerID); err != nil {
+		t.Errorf("RemoveMember() self error = %v", err)
+	}
+	team, err := d.GetTeam(ctx, masterID, ownerID)
+	if err != nil || len(team.Members) != 2 || team.Members[1].Role != models.MemberReviewer {
+		t.Errorf("GetTeam() = %+v, %v, want owner and co-master turned reviewer", team, err)
+	}
+}
+
+// TestMasterGroups проверяет, что пользователь владеет несколькими группами, переключается
+// между ними и видит квесты и кошельки только выбранной группы.
+func TestMasterGroups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	newUser := func(login string) uint {
+		t.Helper()
+		user, err := store.NewUser(ctx, login, "hash")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		return user.ID
+	}
+	activeMaster := func(userID uint) uint {
+		t.Helper()
+		user, err := store.GetUserByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if user.ActiveMasterID == nil {
+			return 0
+		}
+		return *user.ActiveMasterID
+	}
+	ownerID, playerID, otherID := newUser("owner"), newUser("player"), newUser("other")
+
+	home, err := d.ManageCreateSelfMaster(ctx, ownerID, "  ")
+	if err != nil || home.Name != "Основная группа" {
+		t.Fatalf("ManageCreateSelfMaster() = %+v, %v, want default name", home, err)
+	}
+	school, err := d.ManageCreateSelfMaster(ctx, ownerID, "Школа")
+	if err != nil || school.Name != "Школа" || school.UniqueCode == home.UniqueCode {
+		t.Fatalf("ManageCreateSelfMaster() = %+v, %v, want second group with own code", school, err)
+	}
+	if got := activeMaster(ownerID); got != school.ID {
+		t.Errorf("active master after create = %d, want %d", got, school.ID)
+	}
+	if err := d.AddMaster(ctx, home.UniqueCode, playerID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+	if err := d.AddMaster(ctx, school.UniqueCode, playerID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+
+	for _, m := range []*models.UserMaster{home, school} {
+		if _, err := d.NewQuest(ctx, &types.TQuest{Title: m.Name, Price: 10, IsActive: true}, m.ID, ownerID); err != nil {
+			t.Fatalf("NewQuest(%s) error = %v", m.Name, err)
+		}
+	}
+	if err := d.AdjustWallet(ctx, school.ID, playerID, ownerID, 30, ""); err != nil {
+		t.Fatalf("AdjustWallet() error = %v", err)
+	}
+	quests, err := d.GetQuests(ctx, home.ID)
+	if (err != nil && !errors.Is(err, apperr.ErrDataNotFound)) || len(*quests) != 1 || (*quests)[0].Title != home.Name {
+		t.Errorf("GetQuests(home) = %+v, %v, want only quest of home group", quests, err)
+	}
+	if wallets, err := d.GetMasterWallets(ctx, home.ID); err != nil || len(*wallets) != 0 {
+		t.Errorf("GetMasterWallets(home) = %+v, %v, want no wallets", wallets, err)
+	}
+	if wallets, err := d.GetMasterWallets(ctx, school.ID); err != nil || len(*wallets) != 1 || (*wallets)[0].Score != 30 {
+		t.Errorf("GetMasterWallets(school) = %+v, %v, want wallet with 30", wallets, err)
+	}
+
+	if err := d.SwitchMaster(ctx, ownerID, home.ID); err != nil {
+		t.Fatalf("SwitchMaster() error = %v", err)
+	}
+	if got := activeMaster(ownerID); got != home.ID {
+		t.Errorf("active master after switch = %d, want %d", got, home.ID)
+	}
+	if err := d.RenameMaster(ctx, home.ID, ownerID, " Дом "); err != nil {
+		t.Fatalf("RenameMaster() error = %v", err)
+	}
+	if master, err := store.GetMasterByID(ctx, home.ID); err != nil || master.Name != "Дом" {
+		t.Errorf("GetMasterByID() = %+v, %v, want renamed group", master, err)
+	}
+
+	// владелец своей группы принимает приглашение в чужую и переходит в нее
+	if _, err := d.ManageCreateSelfMaster(ctx, otherID, "Кружок"); err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+	invite, err := d.NewInvite(ctx, home.ID, ownerID, string(models.MemberCoMaster))
+	if err != nil {
+		t.Fatalf("NewInvite() error = %v", err)
+	}
+	if err := d.AcceptInvite(ctx, invite.Code, otherID); err != nil {
+		t.Fatalf("AcceptInvite() by owner of other group error = %v", err)
+	}
+	if got := activeMaster(otherID); got != home.ID {
+		t.Errorf("active master after invite = %d, want %d", got, home.ID)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr *apperr.Error
+	}{
+		{name: "switch to foreign group", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.SwitchMaster(ctx, playerID, home.ID)
+		}},
+		{name: "switch to unknown group", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.SwitchMaster(ctx, ownerID, school.ID+100)
+		}},
+		{name: "empty name", wantErr: apperr.ErrValidation, call: func() error {
+			return d.RenameMaster(ctx, school.ID, ownerID, " ")
+		}},
+		{name: "long name", wantErr: apperr.ErrValidation, call: func() error {
+			_, err := d.ManageCreateSelfMaster(ctx, ownerID, strings.Repeat("я", 65))
+			return err
+		}},
+		{name: "co-master renames", wantErr: apperr.ErrForbidden, call: func() error {
+			return d.RenameMaster(ctx, home.ID, otherID, "Чужая")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// без роли мастера квестов своими группами не управляют
+	if err := d.SetUserRoles(ctx, ownerID+100, ownerID, nil); err != nil {
+		t.Fatalf("SetUserRoles() error = %v", err)
+	}
+	if err := d.SwitchMaster(ctx, ownerID, school.ID); !errors.Is(err, apperr.ErrForbidden) {
+		t.Errorf("SwitchMaster() without role error = %v, want %v", err, apperr.ErrForbidden)
+	}
+}
+
+func TestSetUserRoles(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	admin, err := store.NewUser(ctx, "admin", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := store.SetUserRoles(ctx, admin.ID, []uint{models.DefaultRoles[0].ID}); err != nil {
+		t.Fatalf("SetUserRoles() error = %v", err)
+	}
+	user, err := store.NewUser(ctx, "user", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		actorID    uint
+		userID     uint
+		roles      []string
+		wantErr    error
+		wantRoles  int
+		wantMaster bool
+	}{
+		{name: "unknown role", actorID: admin.ID, userID: user.ID, roles: []string{"owner"}, wantErr: apperr.ErrValidation},
+		{name: "unknown user", actorID: admin.ID, userID: user.ID + 100, roles: nil, wantErr: apperr.ErrDataNotFound},
+		{name: "self demotion", actorID: admin.ID, userID: admin.ID, roles: []string{models.RoleQuestMaster}, wantErr: apperr.ErrValidation},
+		{name: "grant quest master", actorID: admin.ID, userID: user.ID, roles: []string{models.RoleQuestMaster}, wantRoles: 1, wantMaster: true},
+		{name: "revoke keeps group", actorID: admin.ID, userID: user.ID, roles: nil, wantRoles: 0, wantMaster: true},
+	}
+	for _, tt := range tests {
+		err := d.SetUserRoles(ctx, tt.actorID, tt.userID, tt.roles)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: SetUserRoles() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if tt.wantErr != nil {
+			continue
+		}
+		got, err := store.GetUserByID(ctx, tt.userID)
+		if err != nil {
+			t.Fatalf("%s: GetUserByID() error = %v", tt.name, err)
+		}
+		if len(got.Roles) != tt.wantRoles || (len(got.OwnedMasters()) == 1) != tt.wantMaster {
+			t.Errorf("%s: user = {Roles: %d, OwnedMasters: %d}, want {Roles: %d, master: %v}",
+				tt.name, len(got.Roles), len(got.OwnedMasters()), tt.wantRoles, tt.wantMaster)
+		}
+	}
+}
+
+func TestLockAndResetPassword(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	user, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	adminID := user.ID + 100
+	sessionID, _, err := d.StartSession(ctx, user.ID, "browser", "10.0.0.1", time.Hour)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+
+	if err := d.LockUser(ctx, user.ID, user.ID); !errors.Is(err, apperr.ErrValidation) {
+		t.Errorf("LockUser() self error = %v, want apperr.ErrValidation", err)
+	}
+	if err := d.LockUser(ctx, adminID, user.ID+1); !errors.Is(err, apperr.ErrDataNotFound) {
+		t.Errorf("LockUser() unknown user error = %v, want apperr.ErrDataNotFound", err)
+	}
+	if err := d.LockUser(ctx, adminID, user.ID); err != nil {
+		t.Fatalf("LockUser() error = %v", err)
+	}
+	if _, err := d.CheckAccessToken(ctx, user.ID, sessionID, 1, "10.0.0.1"); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("CheckAccessToken() after lock error = %v, want apperr.ErrUnauthorized", err)
+	}
+	if _, err := d.Login(ctx, "player", "wrong123"); !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Errorf("Login() locked with wrong password error = %v, want apperr.ErrInvalidCredentials", err)
+	}
+	health, err := d.GetHealth(ctx)
+	if err != nil {
+		t.Fatalf("GetHealth() error = %v", err)
+	}
+	if health.Users != 1 || health.LockedUsers != 1 {
+		t.Errorf("GetHealth() = %+v, want 1 user, 1 locked", health)
+	}
+
+	password, err := d.ResetPassword(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, err := d.Login(ctx, "player", password); !errors.Is(err, apperr.ErrAccountLocked) {
+		t.Errorf("Login() locked error = %v, want apperr.ErrAccountLocked", err)
+	}
+	if err := d.UnlockUser(ctx, user.ID); err != nil {
+		t.Fatalf("UnlockUser() error = %v", err)
+	}
+	reset, err := d.Login(ctx, "player", password)
+	if err != nil {
+		t.Fatalf("Login() temporary password error = %v", err)
+	}
+	if !reset.MustChangePassword {
+		t.Errorf("Login() temporary password MustChangePassword = false, want true")
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := d.Registration(ctx, "player", "secret123"); err != nil {
+		t.Fatalf("Registration() error = %v", err)
+	}
+	user, err := d.Login(ctx, "player", "secret123")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	temp, err := d.ResetPassword(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		current  string
+		password string
+		field    string
+	}{
+		{name: "wrong current password", current: "secret123", password: "changed123", field: "current_password"},
+		{name: "weak password", current: temp, password: "changed", field: "password"},
+		{name: "same password", current: temp, password: temp, field: "password"},
+	}
+	for _, tt := range tests {
+		_, err := d.ChangePassword(ctx, user.ID, tt.current, tt.password)
+		appErr, _ := apperr.As(err)
+		if !errors.Is(err, apperr.ErrValidation) || appErr.Details[tt.field] == "" {
+			t.Errorf("%s: ChangePassword() error = %v, want validation of %s", tt.name, err, tt.field)
+		}
+	}
+
+	sessionID, _, err := d.StartSession(ctx, user.ID, "browser", "10.0.0.1", time.Hour)
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	changed, err := d.ChangePassword(ctx, user.ID, temp, "changed123")
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if changed.MustChangePassword {
+		t.Errorf("ChangePassword() MustChangePassword = true, want false")
+	}
+	if _, err := d.CheckAccessToken(ctx, user.ID, sessionID, user.TokenVersion+1, "10.0.0.1"); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("CheckAccessToken() after change error = %v, want apperr.ErrUnauthorized", err)
+	}
+	if _, err := d.Login(ctx, "player", temp); !errors.Is(err, apperr.ErrInvalidCredentials) {
+		t.Errorf("Login() temporary password error = %v, want apperr.ErrInvalidCredentials", err)
+	}
+	if _, err := d.Login(ctx, "player", "changed123"); err != nil {
+		t.Errorf("Login() new password error = %v", err)
+	}
+}
@@ -0,0 +1,1218 @@
+package disciplinetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/core/discipline"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// TestStore проверяет, что реализация discipline.Store ведет себя как database.Storage.
+// newStore должен возвращать пустое хранилище с ролями по умолчанию для каждого подтеста.
+func TestStore(t *testing.T, newStore func(t *testing.T) discipline.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s discipline.Store)
+	}{
+		{name: "users", fn: testUsers},
+		{name: "roles", fn: testRoles},
+		{name: "find users", fn: testFindUsers},
+		{name: "masters", fn: testMasters},
+		{name: "members", fn: testMembers},
+		{name: "player quests", fn: testPlayerQuests},
+		{name: "update quest", fn: testUpdQuest},
+		{name: "quest statuses", fn: testQuestStatuses},
+		{name: "quest streaks", fn: testQuestStreaks},
+		{name: "rewards", fn: testRewards},
+		{name: "wallet ledger", fn: testWalletLedger},
+		{name: "sessions", fn: testSessions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// fixture - мастер с двумя игроками и второй мастер, у которого игрок тоже состоит.
+type fixture struct {
+	master      *models.User
+	other       *models.User
+	player      *models.User
+	second      *models.User
+	stranger    *models.User
+	masterID    uint
+	otherMaster uint
+}
+
+func newFixture(t *testing.T, s discipline.Store) *fixture {
+	t.Helper()
+	f := &fixture{
+		master:   newUser(t, s, "master"),
+		other:    newUser(t, s, "other"),
+		player:   newUser(t, s, "player"),
+		second:   newUser(t, s, "second"),
+		stranger: newUser(t, s, "stranger"),
+	}
+	f.masterID = newMaster(t, s, f.master, "code-master")
+	f.otherMaster = newMaster(t, s, f.other, "code-other")
+	addPlayer(t, s, f.masterID, f.player.ID)
+	addPlayer(t, s, f.masterID, f.second.ID)
+	addPlayer(t, s, f.otherMaster, f.player.ID)
+	return f
+}
+
+func newUser(t *testing.T, s discipline.Store, login string) *models.User {
+	t.Helper()
+	user, err := s.NewUser(context.Background(), login, "hash")
+	if err != nil {
+		t.Fatalf("NewUser(%s) error = %v", login, err)
+	}
+	return user
+}
+
+func newMaster(t *testing.T, s discipline.Store, user *models.User, code string) uint {
+	t.Helper()
+	master, err := s.NewMaster(context.Background(), &models.UserMaster{UserID: user.ID, Name: code, UniqueCode: code})
+	if err != nil {
+		t.Fatalf("NewMaster(%s) error = %v", user.Login, err)
+	}
+	return master.ID
+}
+
+func addPlayer(t *testing.T, s discipline.Store, masterID, playerID uint) {
+	t.Helper()
+	if err := s.AddPlayerForMaster(context.Background(), masterID, playerID); err != nil {
+		t.Fatalf("AddPlayerForMaster(%d, %d) error = %v", masterID, playerID, err)
+	}
+}
+
+func newQuest(t *testing.T, s discipline.Store, quest models.Quest) *models.Quest {
+	t.Helper()
+	q, err := s.NewQuest(context.Background(), &quest)
+	if err != nil {
+		t.Fatalf("NewQuest(%s) error = %v", quest.Title, err)
+	}
+	return q
+}
+
+func testUsers(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	user := newUser(t, s, "Player")
+
+	if _, err := s.NewUser(ctx, "pLAYER", "hash"); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("NewUser() with login in other case error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+	got, err := s.GetUserByLogin(ctx, "PLAYER")
+	if err != nil {
+		t.Fatalf("GetUserByLogin() error = %v", err)
+	}
+	if got.ID != user.ID || got.Login != "Player" || got.TimeZone != "UTC" {
+		t.Errorf("GetUserByLogin() = {ID: %d, Login: %s, TimeZone: %s}, want {ID: %d, Login: Player, TimeZone: UTC}",
+			got.ID, got.Login, got.TimeZone, user.ID)
+	}
+	if _, err := s.GetUserByLogin(ctx, "nobody"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetUserByLogin() unknown login error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := s.GetUserByID(ctx, user.ID+100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetUserByID() unknown id error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	if err := s.SetUserTimeZone(ctx, user.ID, "Europe/Moscow"); err != nil {
+		t.Fatalf("SetUserTimeZone() error = %v", err)
+	}
+	got, err = s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if got.TimeZone != "Europe/Moscow" || len(got.Memberships) != 0 || len(got.Roles) != 0 || got.ActiveMasterID != nil {
+		t.Errorf("GetUserByID() = {TimeZone: %s, Memberships: %d, Roles: %d, ActiveMasterID: %v}, want Europe/Moscow without masters and roles",
+			got.TimeZone, len(got.Memberships), len(got.Roles), got.ActiveMasterID)
+	}
+
+	lockedAt := time.Now().UTC().Truncate(time.Second)
+	if err := s.SetUserLocked(ctx, user.ID, &lockedAt); err != nil {
+		t.Fatalf("SetUserLocked() error = %v", err)
+	}
+	if err := s.SetUserPassword(ctx, user.ID, "new-hash", true); err != nil {
+		t.Fatalf("SetUserPassword() error = %v", err)
+	}
+	got, err = s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if got.LockedAt == nil || !got.LockedAt.Equal(lockedAt) || got.PasswordHash != "new-hash" || !got.MustChangePassword {
+		t.Errorf("GetUserByID() = {LockedAt: %v, PasswordHash: %s, MustChangePassword: %v}, want {LockedAt: %v, PasswordHash: new-hash, MustChangePassword: true}",
+			got.LockedAt, got.PasswordHash, got.MustChangePassword, lockedAt)
+	}
+	if err := s.SetUserPassword(ctx, user.ID, "own-hash", false); err != nil {
+		t.Fatalf("SetUserPassword() error = %v", err)
+	}
+	if got, err = s.GetUserByID(ctx, user.ID); err != nil || got.PasswordHash != "own-hash" || got.MustChangePassword {
+		t.Errorf("GetUserByID() after change = {PasswordHash: %s, MustChangePassword: %v}, %v, want own-hash without change required",
+			got.PasswordHash, got.MustChangePassword, err)
+	}
+	if err := s.SetUserLocked(ctx, user.ID, nil); err != nil {
+		t.Fatalf("SetUserLocked(nil) error = %v", err)
+	}
+	if got, err = s.GetUserByID(ctx, user.ID); err != nil || got.LockedAt != nil {
+		t.Errorf("GetUserByID() after unlock = %v, %v, want not locked", got.LockedAt, err)
+	}
+	if err := s.SetUserLocked(ctx, user.ID+100, nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("SetUserLocked() unknown user error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := s.SetUserPassword(ctx, user.ID+100, "hash", false); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("SetUserPassword() unknown user error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+// catalogActions возвращает действия роли по умолчанию с именами из models.Permissions.
+func catalogActions(role models.Role) []models.Action {
+	actions := []models.Action{}
+	for _, a := range role.Actions {
+		for _, p := range models.Permissions {
+			if p.ID == a.ID {
+				actions = append(actions, p)
+			}
+		}
+	}
+	return actions
+}
+
+func actionNames(actions []models.Action) []string {
+	names := []string{}
+	for _, a := range actions {
+		names = append(names, a.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func testRoles(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+
+	roles, err := s.GetRoles(ctx)
+	if err != nil {
+		t.Fatalf("GetRoles() error = %v", err)
+	}
+	if len(*roles) != len(models.DefaultRoles) {
+		t.Fatalf("GetRoles() returned %d roles, want %d", len(*roles), len(models.DefaultRoles))
+	}
+	for i, want := range models.DefaultRoles {
+		got := (*roles)[i]
+		if got.ID != want.ID || got.Name != want.Name ||
+			!slices.Equal(actionNames(got.Actions), actionNames(catalogActions(want))) {
+			t.Errorf("GetRoles()[%d] = {ID: %d, Name: %s, Actions: %v}, want {ID: %d, Name: %s, Actions: %v}",
+				i, got.ID, got.Name, actionNames(got.Actions), want.ID, want.Name, actionNames(catalogActions(want)))
+		}
+	}
+
+	user := newUser(t, s, "user")
+	tests := []struct {
+		name    string
+		roleIDs []uint
+		want    []string
+	}{
+		{name: "grant", roleIDs: []uint{1, 2}, want: []string{models.RoleAdmin, models.RoleQuestMaster}},
+		{name: "replace", roleIDs: []uint{2}, want: []string{models.RoleQuestMaster}},
+		{name: "unknown role skipped", roleIDs: []uint{1, 100}, want: []string{models.RoleAdmin}},
+		{name: "revoke all", roleIDs: nil, want: []string{}},
+	}
+	for _, tt := range tests {
+		if err := s.SetUserRoles(ctx, user.ID, tt.roleIDs); err != nil {
+			t.Fatalf("%s: SetUserRoles() error = %v", tt.name, err)
+		}
+		got, err := s.GetUserByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("%s: GetUserByID() error = %v", tt.name, err)
+		}
+		names := []string{}
+		for _, role := range got.Roles {
+			names = append(names, role.Name)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: roles = %v, want %v", tt.name, names, tt.want)
+		}
+	}
+	if err := s.SetUserRoles(ctx, user.ID+100, []uint{1}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("SetUserRoles() unknown user error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	if err := s.SetUserRoles(ctx, user.ID, []uint{1}); err != nil {
+		t.Fatalf("SetUserRoles() error = %v", err)
+	}
+	users, _, err := s.FindUsers(ctx, "", 0, 10)
+	if err != nil {
+		t.Fatalf("FindUsers() error = %v", err)
+	}
+	if len(*users) != 1 || len((*users)[0].Roles) != 1 || (*users)[0].Roles[0].Name != models.RoleAdmin {
+		t.Errorf("FindUsers() = %+v, want user with admin role", *users)
+	}
+}
+
+func testFindUsers(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	newUser(t, s, "Master_2")
+	newUser(t, s, "master2x")
+
+	tests := []struct {
+		name   string
+		query  string
+		offset int
+		limit  int
+		want   []string
+		total  int64
+	}{
+		{name: "all", limit: 10, want: []string{"master", "other", "player", "second", "stranger", "Master_2", "master2x"}, total: 7},
+		{name: "page", offset: 2, limit: 2, want: []string{"player", "second"}, total: 7},
+		{name: "case insensitive", query: "MASTER", limit: 10, want: []string{"master", "Master_2", "master2x"}, total: 3},
+		{name: "search page", query: "master", offset: 1, limit: 1, want: []string{"Master_2"}, total: 3},
+		{name: "underscore is literal", query: "master_", limit: 10, want: []string{"Master_2"}, total: 1},
+		{name: "percent is literal", query: "%", limit: 10, want: []string{}, total: 0},
+		{name: "past the end", offset: 10, limit: 10, want: []string{}, total: 7},
+	}
+	for _, tt := range tests {
+		users, total, err := s.FindUsers(ctx, tt.query, tt.offset, tt.limit)
+		if err != nil {
+			t.Fatalf("%s: FindUsers() error = %v", tt.name, err)
+		}
+		logins := []string{}
+		for _, u := range *users {
+			logins = append(logins, u.Login)
+		}
+		if !slices.Equal(logins, tt.want) || total != tt.total {
+			t.Errorf("%s: FindUsers(%q, %d, %d) = %v, %d, want %v, %d", tt.name, tt.query, tt.offset, tt.limit, logins, total, tt.want, tt.total)
+		}
+	}
+
+	lockedAt := time.Now().UTC()
+	if err := s.SetUserLocked(ctx, f.stranger.ID, &lockedAt); err != nil {
+		t.Fatalf("SetUserLocked() error = %v", err)
+	}
+	stats, err := s.GetUserStats(ctx)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+	if want := (models.UserStats{Users: 7, Locked: 1, Masters: 2}); *stats != want {
+		t.Errorf("GetUserStats() = %+v, want %+v", *stats, want)
+	}
+}
+
+func testMasters(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+
+	user, err := s.GetUserByID(ctx, f.master.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if owned := user.OwnedMasters(); len(owned) != 1 || owned[0].ID != f.masterID || owned[0].Name != "code-master" {
+		t.Fatalf("GetUserByID().OwnedMasters() = %+v, want master %d", owned, f.masterID)
+	}
+	if len(user.Roles) != 1 || user.Roles[0].Name != models.RoleQuestMaster ||
+		!slices.Equal(actionNames(user.Roles[0].Actions), actionNames(catalogActions(models.RoleQuestMasterObject))) {
+		t.Errorf("GetUserByID().Roles = %+v, want quest_master with its default actions", user.Roles)
+	}
+
+	if _, err := s.NewMaster(ctx, &models.UserMaster{UserID: f.player.ID, UniqueCode: "code-master"}); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("NewMaster() with used code error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+
+	// вторая группа владельца получает свой код, роль мастера не дублируется
+	secondID := newMaster(t, s, f.master, "code-second")
+	if err := s.UpdMasterName(ctx, secondID, "Вторая"); err != nil {
+		t.Fatalf("UpdMasterName() error = %v", err)
+	}
+	if err := s.UpdMasterName(ctx, secondID+100, "Нет"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdMasterName() unknown master error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := s.SetUserActiveMaster(ctx, f.master.ID, &secondID); err != nil {
+		t.Fatalf("SetUserActiveMaster() error = %v", err)
+	}
+	user, err = s.GetUserByID(ctx, f.master.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	owned := user.OwnedMasters()
+	if len(owned) != 2 || owned[1].ID != secondID || owned[1].Name != "Вторая" || len(user.Roles) != 1 {
+		t.Errorf("GetUserByID() = {OwnedMasters: %+v, Roles: %d}, want two masters and one role", owned, len(user.Roles))
+	}
+	if user.ActiveMasterID == nil || *user.ActiveMasterID != secondID {
+		t.Errorf("GetUserByID().ActiveMasterID = %v, want %d", user.ActiveMasterID, secondID)
+	}
+
+	master, err := s.GetMasterByCode(ctx, "code-master")
+	if err != nil {
+		t.Fatalf("GetMasterByCode() error = %v", err)
+	}
+	if master.ID != f.masterID || master.UserID != f.master.ID {
+		t.Errorf("GetMasterByCode() = {ID: %d, UserID: %d}, want {ID: %d, UserID: %d}", master.ID, master.UserID, f.masterID, f.master.ID)
+	}
+	if _, err := s.GetMasterByCode(ctx, "unknown"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetMasterByCode() unknown code error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	joined, err := s.GetMasterPlayerJoinedAt(ctx, f.masterID, f.player.ID)
+	if err != nil || joined == nil || time.Since(*joined) > time.Minute {
+		t.Fatalf("GetMasterPlayerJoinedAt() = %v, %v, want time of AddPlayerForMaster", joined, err)
+	}
+	if _, err := s.GetMasterPlayerJoinedAt(ctx, secondID, f.player.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetMasterPlayerJoinedAt() not a player error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	// повторное добавление игрока ничего не меняет
+	addPlayer(t, s, f.masterID, f.player.ID)
+	if again, err := s.GetMasterPlayerJoinedAt(ctx, f.masterID, f.player.ID); err != nil || again == nil || !again.Equal(*joined) {
+		t.Errorf("GetMasterPlayerJoinedAt() after repeated add = %v, %v, want %v", again, err, *joined)
+	}
+	master, err = s.GetMasterByID(ctx, f.masterID)
+	if err != nil {
+		t.Fatalf("GetMasterByID() error = %v", err)
+	}
+	if len(master.Players) != 2 {
+		t.Errorf("GetMasterByID() has %d players, want 2", len(master.Players))
+	}
+	if err := s.AddPlayerForMaster(ctx, f.masterID+100, f.player.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("AddPlayerForMaster() unknown master error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	masters, err := s.GetMastersByPlayerID(ctx, f.player.ID)
+	if err != nil {
+		t.Fatalf("GetMastersByPlayerID() error = %v", err)
+	}
+	if len(*masters) != 2 {
+		t.Errorf("GetMastersByPlayerID() returned %d masters, want 2", len(*masters))
+	}
+}
+
+// testMembers проверяет участников группы мастера и приглашения в нее.
+func testMembers(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	now := time.Now().UTC()
+
+	owner, err := s.GetMasterMember(ctx, f.masterID, f.master.ID)
+	if err != nil || owner.Role != models.MemberOwner {
+		t.Fatalf("GetMasterMember() owner = %+v, %v, want owner role", owner, err)
+	}
+	if _, err := s.GetMasterMember(ctx, f.masterID, f.other.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetMasterMember() of other group error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	newInvite := func(code string, role models.MemberRole, expires time.Time) *models.MasterInvite {
+		t.Helper()
+		invite, err := s.NewMasterInvite(ctx, &models.MasterInvite{UserMasterID: f.masterID, Code: code,
+			Role: role, AuthorID: f.master.ID, ExpiresAt: expires})
+		if err != nil {
+			t.Fatalf("NewMasterInvite(%s) error = %v", code, err)
+		}
+		return invite
+	}
+	reviewer := newInvite("invite-reviewer", models.MemberReviewer, now.Add(time.Hour))
+	newInvite("invite-expired", models.MemberCoMaster, now.Add(-time.Hour))
+	revoked := newInvite("invite-revoked", models.MemberCoMaster, now.Add(time.Hour))
+	if _, err := s.NewMasterInvite(ctx, &models.MasterInvite{UserMasterID: f.masterID, Code: "invite-reviewer",
+		Role: models.MemberCoMaster, ExpiresAt: now.Add(time.Hour)}); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("NewMasterInvite() with used code error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+
+	invites, err := s.GetMasterInvites(ctx, f.masterID, now)
+	if err != nil {
+		t.Fatalf("GetMasterInvites() error = %v", err)
+	}
+	if len(*invites) != 2 || (*invites)[0].ID != revoked.ID || (*invites)[1].ID != reviewer.ID {
+		t.Errorf("GetMasterInvites() = %+v, want active invites, newest first", *invites)
+	}
+	if _, err := s.GetMasterInvite(ctx, "invite-expired", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetMasterInvite() expired error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := s.DeleteMasterInvite(ctx, f.otherMaster, revoked.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteMasterInvite() of other group error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := s.DeleteMasterInvite(ctx, f.masterID, revoked.ID); err != nil {
+		t.Errorf("DeleteMasterInvite() error = %v", err)
+	}
+
+	member, err := s.AcceptMasterInvite(ctx, "invite-reviewer", f.second.ID, now)
+	if err != nil || member.UserMasterID != f.masterID || member.Role != models.MemberReviewer {
+		t.Fatalf("AcceptMasterInvite() = %+v, %v, want reviewer of master %d", member, err, f.masterID)
+	}
+	if _, err := s.AcceptMasterInvite(ctx, "invite-reviewer", f.stranger.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("AcceptMasterInvite() twice error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	newInvite("invite-again", models.MemberCoMaster, now.Add(time.Hour))
+	if _, err := s.AcceptMasterInvite(ctx, "invite-again", f.second.ID, now); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("AcceptMasterInvite() by member error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+	if _, err := s.GetMasterInvite(ctx, "invite-again", now); err != nil {
+		t.Errorf("GetMasterInvite() after failed accept error = %v, want active invite", err)
+	}
+
+	if err := s.SetMemberRole(ctx, f.masterID, f.second.ID, models.MemberCoMaster); err != nil {
+		t.Fatalf("SetMemberRole() error = %v", err)
+	}
+	if err := s.SetMemberRole(ctx, f.masterID, f.stranger.ID, models.MemberCoMaster); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("SetMemberRole() of stranger error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	user, err := s.GetUserByID(ctx, f.second.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if len(user.Memberships) != 1 || user.Memberships[0].Role != models.MemberCoMaster ||
+		user.Memberships[0].UserMaster.UniqueCode != "code-master" {
+		t.Errorf("GetUserByID().Memberships = %+v, want co-master of code-master", user.Memberships)
+	}
+
+	members, err := s.GetMasterMembers(ctx, f.masterID)
+	if err != nil {
+		t.Fatalf("GetMasterMembers() error = %v", err)
+	}
+	if len(*members) != 2 || (*members)[0].User.Login != "master" || (*members)[1].User.Login != "second" {
+		t.Errorf("GetMasterMembers() = %+v, want master and second", *members)
+	}
+	if err := s.DeleteMasterMember(ctx, f.masterID, f.second.ID); err != nil {
+		t.Errorf("DeleteMasterMember() error = %v", err)
+	}
+	if err := s.DeleteMasterMember(ctx, f.masterID, f.second.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteMasterMember() twice error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+// testPlayerQuests проверяет правила видимости квестов игрока.
+func testPlayerQuests(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	past := time.Now().UTC().Add(-time.Hour)
+	future := time.Now().UTC().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		quest   models.Quest
+		paid    bool
+		visible bool
+	}{
+		{
+			name:    "quest for all players",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true},
+			visible: true,
+		},
+		{
+			name:    "quest of other master of player",
+			quest:   models.Quest{UserMasterID: f.otherMaster, UserID: f.other.ID, Type: models.Daily, IsActive: true},
+			visible: true,
+		},
+		{
+			name:    "quest of master without player",
+			quest:   models.Quest{UserID: f.stranger.ID, Type: models.Daily, IsActive: true},
+			visible: false,
+		},
+		{
+			name:    "quest for player",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, Players: []models.User{{ID: f.player.ID}}},
+			visible: true,
+		},
+		{
+			name:    "quest for other player",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, Players: []models.User{{ID: f.second.ID}}},
+			visible: false,
+		},
+		{
+			name:    "inactive quest",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily},
+			visible: false,
+		},
+		{
+			name:    "quest inside dates",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, StartTime: &past, EndTime: &future},
+			visible: true,
+		},
+		{
+			name:    "quest not started",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, StartTime: &future},
+			visible: false,
+		},
+		{
+			name:    "quest finished",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, EndTime: &past},
+			visible: false,
+		},
+		{
+			name:    "one time quest not paid",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.OneTime, IsActive: true},
+			visible: true,
+		},
+		{
+			name:    "one time quest paid",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.OneTime, IsActive: true},
+			paid:    true,
+			visible: false,
+		},
+		{
+			name:    "daily quest paid",
+			quest:   models.Quest{UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true},
+			paid:    true,
+			visible: true,
+		},
+	}
+
+	want := map[uint]bool{}
+	for i, tt := range tests {
+		tt.quest.Title = fmt.Sprintf("quest %d", i)
+		q := newQuest(t, s, tt.quest)
+		if tt.paid {
+			payQuest(t, s, q.ID, f.player.ID, "2024-01-01", nil)
+		}
+		want[q.ID] = tt.visible
+
+		_, err := s.GetPlayerQuest(ctx, q.ID, f.player.ID)
+		switch {
+		case tt.visible && err != nil:
+			t.Errorf("%s: GetPlayerQuest() error = %v, want quest", tt.name, err)
+		case !tt.visible && !errors.Is(err, gorm.ErrRecordNotFound):
+			t.Errorf("%s: GetPlayerQuest() error = %v, want gorm.ErrRecordNotFound", tt.name, err)
+		}
+	}
+
+	quests, err := s.GetPlayerQuests(ctx, f.player.ID)
+	if err != nil {
+		t.Fatalf("GetPlayerQuests() error = %v", err)
+	}
+	got := map[uint]bool{}
+	for _, q := range *quests {
+		if got[q.ID] {
+			t.Errorf("GetPlayerQuests() returned quest %d twice", q.ID)
+		}
+		got[q.ID] = true
+	}
+	for id, visible := range want {
+		if got[id] != visible {
+			t.Errorf("GetPlayerQuests() contains quest %d = %t, want %t", id, got[id], visible)
+		}
+	}
+	for i := 1; i < len(*quests); i++ {
+		if (*quests)[i].UpdatedAt.After((*quests)[i-1].UpdatedAt) {
+			t.Errorf("GetPlayerQuests() is not ordered by updated_at desc")
+		}
+	}
+}
+
+// payQuest отправляет квест, подтверждает и оплачивает его.
+func payQuest(t *testing.T, s discipline.Store, questID, playerID uint, period string, onStreak discipline.StreakFunc) {
+	t.Helper()
+	ctx := context.Background()
+	status, err := s.SendPlayerQuest(ctx, questID, playerID, period, "", nil)
+	if err != nil {
+		t.Fatalf("SendPlayerQuest() error = %v", err)
+	}
+	confirmed := time.Now().UTC()
+	status.ConfirmationDate = &confirmed
+	if err := s.UpdAwaitQuest(ctx, status, nil, ""); err != nil {
+		t.Fatalf("UpdAwaitQuest() error = %v", err)
+	}
+	notPayed, err := s.GetQuestNotPayed(ctx)
+	if err != nil {
+		t.Fatalf("GetQuestNotPayed() error = %v", err)
+	}
+	for _, q := range *notPayed {
+		if q.ID == status.ID {
+			// вторая оплата того же выполнения, как у второй реплики, ничего не начисляет
+			repeat := q
+			if err := s.PayQuest(ctx, &q, onStreak); err != nil {
+				t.Fatalf("PayQuest() error = %v", err)
+			}
+			if err := s.PayQuest(ctx, &repeat, onStreak); err != nil {
+				t.Fatalf("PayQuest() repeat error = %v", err)
+			}
+			return
+		}
+	}
+	t.Fatalf("GetQuestNotPayed() has no status %d", status.ID)
+}
+
+func testUpdQuest(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	quest := newQuest(t, s, models.Quest{
+		Title:         "quest",
+		UserMasterID:  f.masterID,
+		UserID:        f.master.ID,
+		Type:          models.Daily,
+		IsActive:      true,
+		Players:       []models.User{{ID: f.player.ID}},
+		StreakBonuses: []models.QuestStreakBonus{{Days: 7, Bonus: 70}, {Days: 3, Bonus: 30}},
+	})
+
+	got, err := s.GetQuest(ctx, quest.ID)
+	if err != nil {
+		t.Fatalf("GetQuest() error = %v", err)
+	}
+	if len(got.Players) != 1 || got.Players[0].ID != f.player.ID || len(got.StreakBonuses) != 2 {
+		t.Errorf("GetQuest() = {Players: %d, StreakBonuses: %d}, want player %d and 2 bonuses",
+			len(got.Players), len(got.StreakBonuses), f.player.ID)
+	}
+	if got.User.ID != f.master.ID || got.UserMaster.ID != f.masterID {
+		t.Errorf("GetQuest() = {User: %d, UserMaster: %d}, want {User: %d, UserMaster: %d}", got.User.ID, got.UserMaster.ID, f.master.ID, f.masterID)
+	}
+	bonuses, err := s.GetStreakBonuses(ctx, quest.ID)
+	if err != nil {
+		t.Fatalf("GetStreakBonuses() error = %v", err)
+	}
+	if len(*bonuses) != 2 || (*bonuses)[0].Days != 3 || (*bonuses)[1].Days != 7 {
+		t.Errorf("GetStreakBonuses() = %+v, want ordered by days", *bonuses)
+	}
+
+	// квест из формы приходит без даты создания, она должна остаться прежней
+	created := got.CreatedAt
+	update := &models.Quest{
+		Model:         gorm.Model{ID: quest.ID},
+		Title:         "updated",
+		UserMasterID:  f.masterID,
+		UserID:        f.master.ID,
+		Type:          models.Weekly,
+		IsActive:      true,
+		Players:       []models.User{{ID: f.second.ID}},
+		StreakBonuses: []models.QuestStreakBonus{{Days: 2, Bonus: 20}},
+	}
+	if _, err := s.UpdQuest(ctx, update); err != nil {
+		t.Fatalf("UpdQuest() error = %v", err)
+	}
+	got, err = s.GetQuest(ctx, quest.ID)
+	if err != nil {
+		t.Fatalf("GetQuest() error = %v", err)
+	}
+	if got.Title != "updated" || got.Type != models.Weekly {
+		t.Errorf("GetQuest() = {Title: %s, Type: %s}, want {updated, weekly}", got.Title, got.Type)
+	}
+	if !got.CreatedAt.Equal(created) {
+		t.Errorf("GetQuest().CreatedAt = %v after update, want %v", got.CreatedAt, created)
+	}
+	if len(got.Players) != 1 || got.Players[0].ID != f.second.ID {
+		t.Errorf("GetQuest().Players = %+v, want only player %d", got.Players, f.second.ID)
+	}
+	if len(got.StreakBonuses) != 1 || got.StreakBonuses[0].Days != 2 {
+		t.Errorf("GetQuest().StreakBonuses = %+v, want only 2 days bonus", got.StreakBonuses)
+	}
+
+	quests, err := s.GetQuests(ctx, f.masterID)
+	if err != nil {
+		t.Fatalf("GetQuests() error = %v", err)
+	}
+	if len(*quests) != 1 || len((*quests)[0].Players) != 1 {
+		t.Errorf("GetQuests() = %+v, want one quest with one player", *quests)
+	}
+	if _, err := s.GetQuest(ctx, quest.ID+100); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetQuest() unknown id error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func testQuestStatuses(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	quest := newQuest(t, s, models.Quest{Title: "quest", UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, Price: 10})
+
+	status, err := s.SendPlayerQuest(ctx, quest.ID, f.player.ID, "2024-01-01", "done",
+		[]models.ProofAttachment{{FileName: "a.png", ContentType: "image/png", Size: 1, BlobKey: "a"}})
+	if err != nil {
+		t.Fatalf("SendPlayerQuest() error = %v", err)
+	}
+	if status.Quest.ID != quest.ID || status.ProofComment != "done" || len(status.Attachments) != 1 {
+		t.Errorf("SendPlayerQuest() = {Quest: %d, ProofComment: %s, Attachments: %d}, want quest %d with one attachment",
+			status.Quest.ID, status.ProofComment, len(status.Attachments), quest.ID)
+	}
+	if _, err := s.GetPlayerQuestStatus(ctx, quest.ID, f.player.ID, "2024-01-02"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetPlayerQuestStatus() other period error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := s.SendPlayerQuest(ctx, quest.ID, f.player.ID, "2024-01-01", "twice", nil); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("SendPlayerQuest() same period error = %v, want gorm.ErrDuplicatedKey", err)
+	}
+
+	status.ProofComment = "again"
+	status.Attachments = []models.ProofAttachment{{FileName: "b.png", BlobKey: "b"}, {FileName: "c.png", BlobKey: "c"}}
+	if _, err := s.UpdSendStatusPlayerQuest(ctx, status); err != nil {
+		t.Fatalf("UpdSendStatusPlayerQuest() error = %v", err)
+	}
+	status, err = s.GetPlayerQuestStatus(ctx, quest.ID, f.player.ID, "2024-01-01")
+	if err != nil {
+		t.Fatalf("GetPlayerQuestStatus() error = %v", err)
+	}
+	if status.ProofComment != "again" || len(status.Attachments) != 2 {
+		t.Errorf("GetPlayerQuestStatus() = {ProofComment: %s, Attachments: %d}, want {again, 2}",
+			status.ProofComment, len(status.Attachments))
+	}
+	attachment, err := s.GetProofAttachment(ctx, status.Attachments[0].ID)
+	if err != nil {
+		t.Fatalf("GetProofAttachment() error = %v", err)
+	}
+	if attachment.QuestPlayerStatus.ID != status.ID || attachment.QuestPlayerStatus.Quest.UserID != f.master.ID {
+		t.Errorf("GetProofAttachment() status = %d, quest owner = %d, want %d and %d",
+			attachment.QuestPlayerStatus.ID, attachment.QuestPlayerStatus.Quest.UserID, status.ID, f.master.ID)
+	}
+
+	comment, err := s.NewQuestComment(ctx, &models.QuestComment{QuestPlayerStatusID: status.ID, AuthorID: f.master.ID, Text: "ok"})
+	if err != nil {
+		t.Fatalf("NewQuestComment() error = %v", err)
+	}
+	if comment.ID == 0 || comment.Author.Login != f.master.Login {
+		t.Errorf("NewQuestComment() = {ID: %d, Author: %s}, want saved comment by %s", comment.ID, comment.Author.Login, f.master.Login)
+	}
+	comments, err := s.GetQuestComments(ctx, status.ID)
+	if err != nil {
+		t.Fatalf("GetQuestComments() error = %v", err)
+	}
+	if len(*comments) != 1 || (*comments)[0].Author.ID != f.master.ID {
+		t.Errorf("GetQuestComments() = %+v, want one comment by master", *comments)
+	}
+
+	awaits, err := s.GetAwaitQuests(ctx, f.master.ID)
+	if err != nil {
+		t.Fatalf("GetAwaitQuests() error = %v", err)
+	}
+	if len(*awaits) != 1 || (*awaits)[0].Player.ID != f.player.ID || len((*awaits)[0].Comments) != 1 {
+		t.Fatalf("GetAwaitQuests() = %+v, want status of player with comment", *awaits)
+	}
+	if awaits, _ := s.GetAwaitQuests(ctx, f.other.ID); len(*awaits) != 0 {
+		t.Errorf("GetAwaitQuests() of other master returned %d statuses, want 0", len(*awaits))
+	}
+
+	await, err := s.GetAwaitQUest(ctx, status.ID)
+	if err != nil {
+		t.Fatalf("GetAwaitQUest() error = %v", err)
+	}
+	rejected := time.Now().UTC()
+	await.RejectExecuteDate = &rejected
+	await.RejectReason = "no"
+	statusID := await.ID
+	penalty := models.QuestPenalty{QuestID: quest.ID, PlayerID: f.player.ID, UserMasterID: f.masterID, PeriodKey: "2024-01-01",
+		Reason: models.PenaltyRejected, Amount: 2, QuestPlayerStatusID: &statusID}
+	repeat := penalty
+	if err := s.UpdAwaitQuest(ctx, await, &penalty, "rejected"); err != nil {
+		t.Fatalf("UpdAwaitQuest() error = %v", err)
+	}
+	// отклоненную отправку нельзя ни отклонить повторно, ни подтвердить без новой отправки
+	if err := s.UpdAwaitQuest(ctx, await, &repeat, "rejected"); !errors.Is(err, apperr.ErrQuestStatusClosed) {
+		t.Errorf("UpdAwaitQuest() reject twice error = %v, want apperr.ErrQuestStatusClosed", err)
+	}
+	confirmed := time.Now().UTC()
+	closed := *await
+	closed.ConfirmationDate = &confirmed
+	if err := s.UpdAwaitQuest(ctx, &closed, nil, ""); !errors.Is(err, apperr.ErrQuestStatusClosed) {
+		t.Errorf("UpdAwaitQuest() confirm after reject error = %v, want apperr.ErrQuestStatusClosed", err)
+	}
+	assertBalance(t, s, f.masterID, f.player.ID, -2)
+	if awaits, _ := s.GetAwaitQuests(ctx, f.master.ID); len(*awaits) != 0 {
+		t.Errorf("GetAwaitQuests() after reject returned %d statuses, want 0", len(*awaits))
+	}
+
+	statuses, err := s.FindQuestStatuses(ctx, quest.ID, f.player.ID, "2024-01-%")
+	if err != nil || len(*statuses) != 1 || (*statuses)[0].RejectExecuteDate == nil {
+		t.Errorf("FindQuestStatuses() = %+v, %v, want one rejected status", statuses, err)
+	}
+
+	payQuest(t, s, quest.ID, f.player.ID, "2024-01-02", nil)
+	statuses, err = s.FindQuestStatuses(ctx, quest.ID, f.player.ID, "2024-01-%")
+	if err != nil || len(*statuses) != 2 || (*statuses)[1].PeriodKey != "2024-01-02" || (*statuses)[1].AccrualDate == nil {
+		t.Errorf("FindQuestStatuses() after pay = %+v, %v, want rejected and paid statuses by period", statuses, err)
+	}
+	if statuses, err := s.FindQuestStatuses(ctx, quest.ID, f.player.ID, "2024-02-%"); err != nil || len(*statuses) != 0 {
+		t.Errorf("FindQuestStatuses() other month = %+v, %v, want none", statuses, err)
+	}
+	if notPayed, _ := s.GetQuestNotPayed(ctx); len(*notPayed) != 0 {
+		t.Errorf("GetQuestNotPayed() after pay returned %d statuses, want 0", len(*notPayed))
+	}
+	wallets, err := s.GetWallets(ctx, f.player.ID)
+	if err != nil {
+		t.Fatalf("GetWallets() error = %v", err)
+	}
+	if len(*wallets) != 1 || (*wallets)[0].Balance != 8 || (*wallets)[0].UserMasterID != f.masterID {
+		t.Errorf("GetWallets() = %+v, want wallet of master with balance 8 after penalty and pay", *wallets)
+	}
+	if len(*wallets) == 1 {
+		if _, total, err := s.GetWalletTransactions(ctx, (*wallets)[0].ID, 0, 10); err != nil || total != 2 {
+			t.Errorf("GetWalletTransactions() total = %d, %v, want penalty and one accrual", total, err)
+		}
+	}
+}
+
+// testQuestStreaks проверяет, что PayQuest пересчитывает сохраненную серию по всем оплаченным
+// периодам и начисляет возвращенные бонусы, а повторная оплата серию не трогает.
+func testQuestStreaks(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	quest := newQuest(t, s, models.Quest{Title: "daily", UserMasterID: f.masterID, UserID: f.master.ID,
+		Type: models.Daily, Price: 10, IsActive: true})
+
+	if _, err := s.GetQuestStreak(ctx, quest.ID, f.player.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetQuestStreak() before pay error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	steps := []struct {
+		period   string
+		wantPrev uint // серия, сохраненная прошлой оплатой
+		wantPaid []string
+		bonus    uint
+		balance  int
+	}{
+		{period: "2024-01-03", wantPrev: 0, wantPaid: []string{"2024-01-03"}, balance: 10},
+		{period: "2024-01-01", wantPrev: 1, wantPaid: []string{"2024-01-01", "2024-01-03"}, bonus: 5, balance: 25},
+	}
+	for _, step := range steps {
+		calls := 0
+		payQuest(t, s, quest.ID, f.player.ID, step.period, func(streak *models.QuestStreak, paid []string) []models.QuestStreakBonus {
+			calls++
+			slices.Sort(paid)
+			if streak.QuestID != quest.ID || streak.PlayerID != f.player.ID || streak.Current != step.wantPrev || !slices.Equal(paid, step.wantPaid) {
+				t.Errorf("%s: StreakFunc(%+v, %v), want streak %d and paid %v", step.period, streak, paid, step.wantPrev, step.wantPaid)
+			}
+			streak.Current = uint(len(paid))
+			streak.LastPeriod = paid[len(paid)-1]
+			if step.bonus == 0 {
+				return nil
+			}
+			return []models.QuestStreakBonus{{Days: 2, Bonus: step.bonus}}
+		})
+		if calls != 1 {
+			t.Errorf("%s: StreakFunc called %d times, want once", step.period, calls)
+		}
+		assertBalance(t, s, f.masterID, f.player.ID, step.balance)
+	}
+
+	streak, err := s.GetQuestStreak(ctx, quest.ID, f.player.ID)
+	if err != nil || streak.Current != 2 || streak.LastPeriod != "2024-01-03" {
+		t.Errorf("GetQuestStreak() = %+v, %v, want {Current: 2, LastPeriod: 2024-01-03}", streak, err)
+	}
+}
+
+func testRewards(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+	stock := uint(1)
+	reward, err := s.NewReward(ctx, &models.Reward{UserMasterID: f.masterID, Title: "reward", Cost: 10, Stock: &stock, IsActive: true})
+	if err != nil {
+		t.Fatalf("NewReward() error = %v", err)
+	}
+	if _, err := s.NewReward(ctx, &models.Reward{UserMasterID: f.masterID, Title: "hidden", Cost: 1}); err != nil {
+		t.Fatalf("NewReward() error = %v", err)
+	}
+
+	rewards, err := s.GetPlayerRewards(ctx, f.player.ID)
+	if err != nil {
+		t.Fatalf("GetPlayerRewards() error = %v", err)
+	}
+	if len(*rewards) != 1 || (*rewards)[0].ID != reward.ID {
+		t.Errorf("GetPlayerRewards() = %+v, want only active reward", *rewards)
+	}
+	if rewards, _ := s.GetPlayerRewards(ctx, f.stranger.ID); len(*rewards) != 0 {
+		t.Errorf("GetPlayerRewards() of stranger returned %d rewards, want 0", len(*rewards))
+	}
+	if _, err := s.BuyReward(ctx, reward.ID, f.stranger.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("BuyReward() by stranger error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	if _, err := s.BuyReward(ctx, reward.ID, f.player.ID); !errors.Is(err, apperr.ErrInsufficientFunds) {
+		t.Fatalf("BuyReward() without funds error = %v, want apperr.ErrInsufficientFunds", err)
+	}
+	assertStock(t, s, reward.ID, 1)
+
+	adjustWallet(t, s, f.masterID, f.player.ID, 15)
+	purchase, err := s.BuyReward(ctx, reward.ID, f.player.ID)
+	if err != nil {
+		t.Fatalf("BuyReward() error = %v", err)
+	}
+	if purchase.Status != models.PurchasePending || purchase.Cost != 10 || purchase.Reward.Title != "reward" {
+		t.Errorf("BuyReward() = %+v, want pending purchase of reward", purchase)
+	}
+	assertStock(t, s, reward.ID, 0)
+	assertBalance(t, s, f.masterID, f.player.ID, 5)
+	if _, err := s.BuyReward(ctx, reward.ID, f.player.ID); !errors.Is(err, apperr.ErrRewardOutOfStock) {
+		t.Errorf("BuyReward() out of stock error = %v, want apperr.ErrRewardOutOfStock", err)
+	}
+
+	purchases, err := s.GetRewardPurchases(ctx, f.masterID)
+	if err != nil {
+		t.Fatalf("GetRewardPurchases() error = %v", err)
+	}
+	if len(*purchases) != 1 || (*purchases)[0].Player.ID != f.player.ID {
+		t.Errorf("GetRewardPurchases() = %+v, want pending purchase of player", *purchases)
+	}
+
+	purchase, err = s.GetRewardPurchase(ctx, purchase.ID)
+	if err != nil {
+		t.Fatalf("GetRewardPurchase() error = %v", err)
+	}
+	if err := s.RefundRewardPurchase(ctx, purchase); err != nil {
+		t.Fatalf("RefundRewardPurchase() error = %v", err)
+	}
+	assertStock(t, s, reward.ID, 1)
+	assertBalance(t, s, f.masterID, f.player.ID, 15)
+	if err := s.RefundRewardPurchase(ctx, purchase); !errors.Is(err, apperr.ErrPurchaseAlreadyClosed) {
+		t.Errorf("RefundRewardPurchase() twice error = %v, want apperr.ErrPurchaseAlreadyClosed", err)
+	}
+
+	purchase, err = s.BuyReward(ctx, reward.ID, f.player.ID)
+	if err != nil {
+		t.Fatalf("BuyReward() after refund error = %v", err)
+	}
+	if err := s.FulfilRewardPurchase(ctx, purchase); err != nil {
+		t.Fatalf("FulfilRewardPurchase() error = %v", err)
+	}
+	if err := s.FulfilRewardPurchase(ctx, purchase); !errors.Is(err, apperr.ErrPurchaseAlreadyClosed) {
+		t.Errorf("FulfilRewardPurchase() twice error = %v, want apperr.ErrPurchaseAlreadyClosed", err)
+	}
+	if purchases, _ := s.GetRewardPurchases(ctx, f.masterID); len(*purchases) != 0 {
+		t.Errorf("GetRewardPurchases() after close returned %d purchases, want 0", len(*purchases))
+	}
+	history, err := s.GetPlayerPurchases(ctx, f.player.ID)
+	if err != nil {
+		t.Fatalf("GetPlayerPurchases() error = %v", err)
+	}
+	if len(*history) != 2 || (*history)[0].Status != models.PurchaseFulfilled {
+		t.Errorf("GetPlayerPurchases() = %+v, want 2 purchases, newest fulfilled first", *history)
+	}
+
+	reward.Title = "renamed"
+	reward.IsActive = false
+	if _, err := s.UpdReward(ctx, reward); err != nil {
+		t.Fatalf("UpdReward() error = %v", err)
+	}
+	got, err := s.GetReward(ctx, reward.ID)
+	if err != nil {
+		t.Fatalf("GetReward() error = %v", err)
+	}
+	if got.Title != "renamed" || got.IsActive {
+		t.Errorf("GetReward() = {Title: %s, IsActive: %t}, want {renamed, false}", got.Title, got.IsActive)
+	}
+	if rewards, _ := s.GetRewards(ctx, f.masterID); len(*rewards) != 2 {
+		t.Errorf("GetRewards() returned %d rewards, want 2", len(*rewards))
+	}
+}
+
+func testWalletLedger(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	f := newFixture(t, s)
+
+	txn := adjustWallet(t, s, f.masterID, f.player.ID, 20)
+	adjustWallet(t, s, f.masterID, f.player.ID, -5)
+	reversal, err := s.ReverseWalletTransaction(ctx, txn, f.master.ID)
+	if err != nil {
+		t.Fatalf("ReverseWalletTransaction() error = %v", err)
+	}
+	if reversal.Amount != -20 || reversal.Balance != -5 || reversal.Type != models.TransactionReversal {
+		t.Errorf("ReverseWalletTransaction() = {Amount: %d, Balance: %d, Type: %s}, want {-20, -5, reversal}",
+			reversal.Amount, reversal.Balance, reversal.Type)
+	}
+	if _, err := s.ReverseWalletTransaction(ctx, txn, f.master.ID); !errors.Is(err, apperr.ErrTransactionReversed) {
+		t.Errorf("ReverseWalletTransaction() twice error = %v, want apperr.ErrTransactionReversed", err)
+	}
+
+	penalty := &models.QuestPenalty{PlayerID: f.player.ID, UserMasterID: f.masterID, PeriodKey: "2024-01-01",
+		Reason: models.PenaltyMissed, Amount: 3}
+	penalty.QuestID = newQuest(t, s, models.Quest{Title: "quest", UserMasterID: f.masterID, UserID: f.master.ID, Type: models.Daily, IsActive: true, Penalty: 3}).ID
+	if charged, err := s.ChargePenalty(ctx, penalty, "missed"); err != nil || !charged {
+		t.Fatalf("ChargePenalty() = %t, %v, want charged", charged, err)
+	}
+	repeat := *penalty
+	repeat.ID = 0
+	if charged, err := s.ChargePenalty(ctx, &repeat, "missed"); err != nil || charged {
+		t.Errorf("ChargePenalty() twice = %t, %v, want not charged", charged, err)
+	}
+	quests, err := s.GetPenaltyQuests(ctx)
+	if err != nil {
+		t.Fatalf("GetPenaltyQuests() error = %v", err)
+	}
+	if len(*quests) != 1 || len((*quests)[0].UserMaster.Players) != 2 {
+		t.Errorf("GetPenaltyQuests() = %+v, want quest with master players", *quests)
+	}
+	assertBalance(t, s, f.masterID, f.player.ID, -8)
+
+	wallet, err := s.GetWallet(ctx, txn.WalletID)
+	if err != nil {
+		t.Fatalf("GetWallet() error = %v", err)
+	}
+	if wallet.Player.ID != f.player.ID {
+		t.Errorf("GetWallet().Player = %d, want %d", wallet.Player.ID, f.player.ID)
+	}
+	page, total, err := s.GetWalletTransactions(ctx, wallet.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("GetWalletTransactions() error = %v", err)
+	}
+	if total != 4 || len(*page) != 2 || (*page)[0].ID != reversal.ID || (*page)[1].ID <= txn.ID {
+		t.Errorf("GetWalletTransactions(1, 2) = %d of %d, want 2 of 4 after the newest", len(*page), total)
+	}
+	all, total, err := s.GetPlayerTransactions(ctx, f.player.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("GetPlayerTransactions() error = %v", err)
+	}
+	if total != 4 || len(*all) != 4 || !(*all)[3].IsReversed || (*all)[2].IsReversed {
+		t.Errorf("GetPlayerTransactions() = %+v, want 4 with only the first reversed", *all)
+	}
+	stored, err := s.GetWalletTransaction(ctx, txn.ID)
+	if err != nil {
+		t.Fatalf("GetWalletTransaction() error = %v", err)
+	}
+	if stored.Amount != 20 || stored.Balance != 20 {
+		t.Errorf("GetWalletTransaction() = {Amount: %d, Balance: %d}, want {20, 20}", stored.Amount, stored.Balance)
+	}
+
+	if count, err := s.CountUnbalancedWallets(ctx); err != nil || count != 0 {
+		t.Errorf("CountUnbalancedWallets() = %d, %v, want 0", count, err)
+	}
+	if wallets, _ := s.GetMasterWallets(ctx, f.masterID); len(*wallets) != 1 || (*wallets)[0].Player.ID != f.player.ID {
+		t.Errorf("GetMasterWallets() = %+v, want wallet of player", *wallets)
+	}
+}
+
+func adjustWallet(t *testing.T, s discipline.Store, masterID, playerID uint, amount int) *models.WalletTransaction {
+	t.Helper()
+	txn, err := s.AdjustWallet(context.Background(), &models.WalletTransaction{UserMasterID: masterID, PlayerID: playerID, Amount: amount})
+	if err != nil {
+		t.Fatalf("AdjustWallet(%d) error = %v", amount, err)
+	}
+	return txn
+}
+
+func assertBalance(t *testing.T, s discipline.Store, masterID, playerID uint, want int) {
+	t.Helper()
+	wallets, err := s.GetMasterWallets(context.Background(), masterID)
+	if err != nil {
+		t.Fatalf("GetMasterWallets() error = %v", err)
+	}
+	for _, w := range *wallets {
+		if w.PlayerID == playerID && w.Balance != want {
+			t.Errorf("wallet balance = %d, want %d", w.Balance, want)
+		}
+	}
+}
+
+func assertStock(t *testing.T, s discipline.Store, rewardID uint, want uint) {
+	t.Helper()
+	reward, err := s.GetReward(context.Background(), rewardID)
+	if err != nil {
+		t.Fatalf("GetReward() error = %v", err)
+	}
+	if reward.Stock == nil || *reward.Stock != want {
+		t.Errorf("reward stock = %v, want %d", reward.Stock, want)
+	}
+}
+
+func testSessions(t *testing.T, s discipline.Store) {
+	ctx := context.Background()
+	user := newUser(t, s, "player")
+	other := newUser(t, s, "other")
+	now := time.Now().UTC().Truncate(time.Second)
+	expires := now.Add(time.Hour)
+
+	newSession := func(id string, userID uint, lastSeen time.Time) *models.RefreshToken {
+		t.Helper()
+		token := &models.RefreshToken{UserID: userID, TokenHash: "token-" + id, ExpiresAt: expires}
+		session := &models.Session{ID: id, UserID: userID, UserAgent: "agent " + id, IP: "127.0.0.1",
+			CreatedAt: now, LastSeenAt: lastSeen, ExpiresAt: expires}
+		if err := s.NewSession(ctx, session, token); err != nil {
+			t.Fatalf("NewSession(%s) error = %v", id, err)
+		}
+		return token
+	}
+	first := newSession("first", user.ID, now)
+	newSession("second", user.ID, now.Add(time.Minute))
+	otherToken := newSession("other", other.ID, now)
+
+	if first.SessionID != "first" {
+		t.Errorf("NewSession() token SessionID = %s, want first", first.SessionID)
+	}
+	if _, err := s.GetSession(ctx, "unknown"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetSession() unknown id error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := s.GetRefreshToken(ctx, "unknown"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetRefreshToken() unknown hash error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	assertSessions := func(want ...string) {
+		t.Helper()
+		sessions, err := s.GetUserSessions(ctx, user.ID, now)
+		if err != nil {
+			t.Fatalf("GetUserSessions() error = %v", err)
+		}
+		got := []string{}
+		for _, session := range *sessions {
+			got = append(got, session.ID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("GetUserSessions() = %v, want %v", got, want)
+		}
+	}
+	assertSessions("second", "first")
+
+	err := s.TouchSession(ctx, &models.Session{ID: "first", LastSeenAt: now.Add(2 * time.Minute), IP: "10.0.0.1", ExpiresAt: expires})
+	if err != nil {
+		t.Fatalf("TouchSession() error = %v", err)
+	}
+	got, err := s.GetSession(ctx, "first")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if got.IP != "10.0.0.1" || got.UserAgent != "agent first" || !got.LastSeenAt.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("GetSession() after touch = {IP: %s, UserAgent: %s, LastSeenAt: %v}, want 10.0.0.1, agent first, %v",
+			got.IP, got.UserAgent, got.LastSeenAt, now.Add(2*time.Minute))
+	}
+	assertSessions("first", "second")
+
+	next := &models.RefreshToken{UserID: user.ID, SessionID: "first", TokenHash: "next", ExpiresAt: expires}
+	rotated, err := s.RotateRefreshToken(ctx, first, next)
+	if err != nil || !rotated {
+		t.Fatalf("RotateRefreshToken() = %v, %v, want true", rotated, err)
+	}
+	if old, err := s.GetRefreshToken(ctx, first.TokenHash); err != nil || old.RevokedAt == nil {
+		t.Errorf("GetRefreshToken() rotated token = %v, %v, want revoked token", old, err)
+	}
+	rotated, err = s.RotateRefreshToken(ctx, first, &models.RefreshToken{UserID: user.ID, SessionID: "first", TokenHash: "third", ExpiresAt: expires})
+	if err != nil || rotated {
+		t.Errorf("RotateRefreshToken() of revoked token = %v, %v, want false", rotated, err)
+	}
+	if _, err := s.GetRefreshToken(ctx, "third"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetRefreshToken() of token from failed rotation error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	if err := s.RevokeSession(ctx, "first"); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if got, err := s.GetSession(ctx, "first"); err != nil || got.RevokedAt == nil {
+		t.Errorf("GetSession() after RevokeSession = %v, %v, want revoked session", got, err)
+	}
+	if token, err := s.GetRefreshToken(ctx, "next"); err != nil || token.RevokedAt == nil {
+		t.Errorf("GetRefreshToken() of revoked session = %v, %v, want revoked token", token, err)
+	}
+	assertSessions("second")
+
+	if err := s.RevokeUserTokens(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeUserTokens() error = %v", err)
+	}
+	assertSessions()
+	if token, err := s.GetRefreshToken(ctx, "token-second"); err != nil || token.RevokedAt == nil {
+		t.Errorf("GetRefreshToken() after RevokeUserTokens = %v, %v, want revoked token", token, err)
+	}
+	if token, err := s.GetRefreshToken(ctx, otherToken.TokenHash); err != nil || token.RevokedAt != nil {
+		t.Errorf("GetRefreshToken() of other user = %v, %v, want active token", token, err)
+	}
+	if got, err := s.GetUserByID(ctx, user.ID); err != nil || got.TokenVersion != 1 {
+		t.Errorf("GetUserByID() after RevokeUserTokens = %v, %v, want TokenVersion 1", got, err)
+	}
+
+	// 3 сессии и 4 токена: first, next, token-second и token-other
+	deleted, err := s.DeleteExpiredTokens(ctx, expires.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteExpiredTokens() error = %v", err)
+	}
+	if deleted != 7 {
+		t.Errorf("DeleteExpiredTokens() = %d, want 7", deleted)
+	}
+	if _, err := s.GetSession(ctx, "other"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetSession() after cleanup error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,188 @@
+package disciplinetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Store) GetMasterMember(ctx context.Context, masterID, userID uint) (*models.MasterMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.memberID(masterID, userID)
+	if !ok {
+		return nil, notFound("failed get master member")
+	}
+	member := s.members[id]
+	return &member, nil
+}
+
+func (s *Store) GetMasterMembers(ctx context.Context, masterID uint) (*[]models.MasterMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members := []models.MasterMember{}
+	for _, id := range sortedIDs(s.members) {
+		if s.members[id].UserMasterID == masterID {
+			member := s.members[id]
+			member.User = s.users[member.UserID]
+			members = append(members, member)
+		}
+	}
+	return &members, nil
+}
+
+func (s *Store) SetMemberRole(ctx context.Context, masterID, userID uint, role models.MemberRole) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.memberID(masterID, userID)
+	if !ok {
+		return notFound("failed update member role")
+	}
+	member := s.members[id]
+	member.Role = role
+	member.UpdatedAt = time.Now()
+	s.members[id] = member
+	return nil
+}
+
+func (s *Store) DeleteMasterMember(ctx context.Context, masterID, userID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.memberID(masterID, userID)
+	if !ok {
+		return notFound("failed delete master member")
+	}
+	delete(s.members, id)
+	return nil
+}
+
+func (s *Store) NewMasterInvite(ctx context.Context, invite *models.MasterInvite) (*models.MasterInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.invites {
+		if stored.Code == invite.Code {
+			return nil, fmt.Errorf("failed create master invite: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+	invite.ID = s.nextID("master_invites")
+	invite.CreatedAt = time.Now()
+	s.invites[invite.ID] = *invite
+	return invite, nil
+}
+
+func (s *Store) GetMasterInvite(ctx context.Context, code string, now time.Time) (*models.MasterInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.activeInvite(code, now)
+	if !ok {
+		return nil, notFound("failed get master invite")
+	}
+	return &invite, nil
+}
+
+func (s *Store) GetMasterInvites(ctx context.Context, masterID uint, now time.Time) (*[]models.MasterInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invites := []models.MasterInvite{}
+	for _, id := range sortedIDs(s.invites) {
+		invite := s.invites[id]
+		if invite.UserMasterID == masterID && inviteActive(invite, now) {
+			invites = append(invites, invite)
+		}
+	}
+	sort.SliceStable(invites, func(i, j int) bool { return invites[i].ID > invites[j].ID })
+	return &invites, nil
+}
+
+func (s *Store) DeleteMasterInvite(ctx context.Context, masterID, inviteID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.invites[inviteID]
+	if !ok || invite.UserMasterID != masterID || invite.AcceptedAt != nil {
+		return notFound("failed delete master invite")
+	}
+	delete(s.invites, inviteID)
+	return nil
+}
+
+func (s *Store) AcceptMasterInvite(ctx context.Context, code string, userID uint, now time.Time) (*models.MasterMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invite, ok := s.activeInvite(code, now)
+	if !ok {
+		return nil, notFound("failed accept master invite: failed get master invite")
+	}
+	if _, ok := s.memberID(invite.UserMasterID, userID); ok {
+		return nil, fmt.Errorf("failed accept master invite: %w", gorm.ErrDuplicatedKey)
+	}
+
+	invite.AcceptedByID = &userID
+	invite.AcceptedAt = &now
+	s.invites[invite.ID] = invite
+	member := s.addMember(invite.UserMasterID, userID, invite.Role)
+	return &member, nil
+}
+
+// addMember добавляет пользователя в группу мастера.
+func (s *Store) addMember(masterID, userID uint, role models.MemberRole) models.MasterMember {
+	now := time.Now()
+	member := models.MasterMember{
+		ID:           s.nextID("master_members"),
+		UserMasterID: masterID,
+		UserID:       userID,
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.members[member.ID] = member
+	return member
+}
+
+func (s *Store) memberID(masterID, userID uint) (uint, bool) {
+	for _, id := range sortedIDs(s.members) {
+		if s.members[id].UserMasterID == masterID && s.members[id].UserID == userID {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// userMemberships - участие пользователя в группах вместе с группами, в порядке добавления.
+func (s *Store) userMemberships(userID uint) []models.MasterMember {
+	memberships := []models.MasterMember{}
+	for _, id := range sortedIDs(s.members) {
+		if s.members[id].UserID == userID {
+			member := s.members[id]
+			member.UserMaster = s.masters[member.UserMasterID]
+			memberships = append(memberships, member)
+		}
+	}
+	return memberships
+}
+
+func (s *Store) activeInvite(code string, now time.Time) (models.MasterInvite, bool) {
+	for _, id := range sortedIDs(s.invites) {
+		if s.invites[id].Code == code && inviteActive(s.invites[id], now) {
+			return s.invites[id], true
+		}
+	}
+	return models.MasterInvite{}, false
+}
+
+func inviteActive(invite models.MasterInvite, now time.Time) bool {
+	return invite.AcceptedAt == nil && invite.ExpiresAt.After(now)
+}
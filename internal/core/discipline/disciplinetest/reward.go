@@ -0,0 +1,263 @@
+package disciplinetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Store) NewReward(ctx context.Context, reward *models.Reward) (*models.Reward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if reward.ID == 0 {
+		reward.ID = s.nextID("rewards")
+		reward.CreatedAt = now
+	}
+	reward.UpdatedAt = now
+	s.rewards[reward.ID] = plainReward(*reward)
+	return reward, nil
+}
+
+func (s *Store) GetReward(ctx context.Context, rewardID uint) (*models.Reward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.rewards[rewardID]
+	if !ok {
+		return nil, notFound("failed get reward")
+	}
+	reward := plainReward(stored)
+	return &reward, nil
+}
+
+func (s *Store) GetRewards(ctx context.Context, masterID uint) (*[]models.Reward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rewards := []models.Reward{}
+	for _, id := range sortedIDs(s.rewards) {
+		if s.rewards[id].UserMasterID == masterID {
+			rewards = append(rewards, plainReward(s.rewards[id]))
+		}
+	}
+	sortByUpdatedDesc(rewards, func(r models.Reward) (time.Time, uint) { return r.UpdatedAt, r.ID })
+	return &rewards, nil
+}
+
+func (s *Store) UpdReward(ctx context.Context, reward *models.Reward) (*models.Reward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.rewards[reward.ID]; ok {
+		stored.Title = reward.Title
+		stored.Description = reward.Description
+		stored.Cost = reward.Cost
+		stored.Stock = clone(reward.Stock)
+		stored.IsActive = reward.IsActive
+		stored.UpdatedAt = time.Now()
+		s.rewards[reward.ID] = stored
+	}
+	return reward, nil
+}
+
+func (s *Store) GetPlayerRewards(ctx context.Context, playerID uint) (*[]models.Reward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rewards := []models.Reward{}
+	for _, id := range sortedIDs(s.rewards) {
+		if reward := s.rewards[id]; reward.IsActive && contains(s.masterPlayers[reward.UserMasterID], playerID) {
+			rewards = append(rewards, plainReward(reward))
+		}
+	}
+	sortBy(rewards, func(r models.Reward) uint { return r.Cost })
+	return &rewards, nil
+}
+
+// BuyReward списывает стоимость награды с кошелька игрока и создает покупку.
+// Все проверки выполняются до изменений, поэтому неудачная покупка ничего не меняет.
+func (s *Store) BuyReward(ctx context.Context, rewardID, playerID uint) (*models.RewardPurchase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reward, ok := s.rewards[rewardID]
+	if !ok || !reward.IsActive || !contains(s.masterPlayers[reward.UserMasterID], playerID) {
+		return nil, notFound("failed buy reward: failed get reward")
+	}
+	if reward.Stock != nil && *reward.Stock == 0 {
+		return nil, fmt.Errorf("failed buy reward: %w", apperr.ErrRewardOutOfStock)
+	}
+	if s.walletBalance(reward.UserMasterID, playerID, -int(reward.Cost)) < 0 && reward.Cost > 0 {
+		return nil, fmt.Errorf("failed buy reward: failed debit wallet: %w", apperr.ErrInsufficientFunds)
+	}
+
+	now := time.Now()
+	if reward.Stock != nil {
+		stock := *reward.Stock - 1
+		reward.Stock = &stock
+		s.rewards[reward.ID] = reward
+	}
+
+	purchase := &models.RewardPurchase{
+		RewardID:     reward.ID,
+		PlayerID:     playerID,
+		UserMasterID: reward.UserMasterID,
+		Cost:         reward.Cost,
+		Status:       models.PurchasePending,
+	}
+	purchase.ID = s.nextID("reward_purchases")
+	purchase.CreatedAt = now
+	purchase.UpdatedAt = now
+	s.purchases[purchase.ID] = *purchase
+
+	purchaseID := purchase.ID
+	s.postTransaction(&models.WalletTransaction{
+		UserMasterID:     reward.UserMasterID,
+		PlayerID:         playerID,
+		Type:             models.TransactionPurchase,
+		Amount:           -int(reward.Cost),
+		Comment:          reward.Title,
+		RewardPurchaseID: &purchaseID,
+	})
+	purchase.Reward = plainReward(reward)
+
+	return purchase, nil
+}
+
+func (s *Store) GetRewardPurchases(ctx context.Context, masterID uint) (*[]models.RewardPurchase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purchases := []models.RewardPurchase{}
+	for _, id := range sortedIDs(s.purchases) {
+		purchase := s.purchases[id]
+		if purchase.UserMasterID != masterID || purchase.Status != models.PurchasePending {
+			continue
+		}
+		purchase = s.loadPurchase(purchase)
+		purchase.Player = s.users[purchase.PlayerID]
+		purchases = append(purchases, purchase)
+	}
+	sort.SliceStable(purchases, func(i, j int) bool {
+		return purchases[i].CreatedAt.Before(purchases[j].CreatedAt)
+	})
+	return &purchases, nil
+}
+
+func (s *Store) GetPlayerPurchases(ctx context.Context, playerID uint) (*[]models.RewardPurchase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purchases := []models.RewardPurchase{}
+	for _, id := range sortedIDs(s.purchases) {
+		if purchase := s.purchases[id]; purchase.PlayerID == playerID {
+			purchases = append(purchases, s.loadPurchase(purchase))
+		}
+	}
+	sortByCreatedDesc(purchases)
+	if len(purchases) > 50 {
+		purchases = purchases[:50]
+	}
+	return &purchases, nil
+}
+
+func (s *Store) GetRewardPurchase(ctx context.Context, purchaseID uint) (*models.RewardPurchase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.purchases[purchaseID]
+	if !ok {
+		return nil, notFound("failed get reward purchase")
+	}
+	purchase := s.loadPurchase(stored)
+	return &purchase, nil
+}
+
+func (s *Store) FulfilRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currentTime := time.Now().UTC()
+	if !s.closePurchase(purchase.ID, models.PurchaseFulfilled, currentTime) {
+		return apperr.ErrPurchaseAlreadyClosed
+	}
+	purchase.Status = models.PurchaseFulfilled
+	purchase.ClosedDate = &currentTime
+	return nil
+}
+
+// RefundRewardPurchase возвращает баллы на кошелек игрока и награду на склад.
+func (s *Store) RefundRewardPurchase(ctx context.Context, purchase *models.RewardPurchase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currentTime := time.Now().UTC()
+	if !s.closePurchase(purchase.ID, models.PurchaseRefunded, currentTime) {
+		return fmt.Errorf("failed refund reward purchase: %w", apperr.ErrPurchaseAlreadyClosed)
+	}
+
+	purchaseID := purchase.ID
+	s.postTransaction(&models.WalletTransaction{
+		UserMasterID:     purchase.UserMasterID,
+		PlayerID:         purchase.PlayerID,
+		Type:             models.TransactionRefund,
+		Amount:           int(purchase.Cost),
+		Comment:          purchase.Reward.Title,
+		RewardPurchaseID: &purchaseID,
+	})
+
+	if reward, ok := s.rewards[purchase.RewardID]; ok && reward.Stock != nil {
+		stock := *reward.Stock + 1
+		reward.Stock = &stock
+		s.rewards[reward.ID] = reward
+	}
+
+	purchase.Status = models.PurchaseRefunded
+	purchase.ClosedDate = &currentTime
+	return nil
+}
+
+// closePurchase закрывает покупку, если она еще ожидает обработки.
+func (s *Store) closePurchase(purchaseID uint, status models.RewardPurchaseStatus, closed time.Time) bool {
+	purchase, ok := s.purchases[purchaseID]
+	if !ok || purchase.Status != models.PurchasePending {
+		return false
+	}
+	purchase.Status = status
+	purchase.ClosedDate = &closed
+	purchase.UpdatedAt = time.Now()
+	s.purchases[purchaseID] = purchase
+	return true
+}
+
+func (s *Store) loadPurchase(purchase models.RewardPurchase) models.RewardPurchase {
+	purchase.ClosedDate = clone(purchase.ClosedDate)
+	purchase.Reward = plainReward(s.rewards[purchase.RewardID])
+	return purchase
+}
+
+func plainReward(reward models.Reward) models.Reward {
+	reward.UserMaster = models.UserMaster{}
+	reward.Stock = clone(reward.Stock)
+	return reward
+}
+
+// sortBy сортирует записи по возрастанию ключа, сохраняя порядок равных.
+func sortBy[T any](items []T, key func(T) uint) {
+	sort.SliceStable(items, func(i, j int) bool { return key(items[i]) < key(items[j]) })
+}
+
+func sortByCreatedDesc(purchases []models.RewardPurchase) {
+	sort.SliceStable(purchases, func(i, j int) bool {
+		if purchases[i].CreatedAt.Equal(purchases[j].CreatedAt) {
+			return purchases[i].ID > purchases[j].ID
+		}
+		return purchases[i].CreatedAt.After(purchases[j].CreatedAt)
+	})
+}
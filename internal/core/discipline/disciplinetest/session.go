@@ -0,0 +1,191 @@
+package disciplinetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func (s *Store) NewSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[session.ID]; ok {
+		return fmt.Errorf("failed start session: failed create session: %w", gorm.ErrDuplicatedKey)
+	}
+	if _, ok := s.refreshTokenByHash(token.TokenHash); ok {
+		return fmt.Errorf("failed start session: failed create refresh token: %w", gorm.ErrDuplicatedKey)
+	}
+	s.sessions[session.ID] = plainSession(*session)
+	token.SessionID = session.ID
+	s.saveRefreshToken(token)
+	return nil
+}
+
+func (s *Store) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, notFound("failed get session")
+	}
+	session := plainSession(stored)
+	return &session, nil
+}
+
+func (s *Store) GetUserSessions(ctx context.Context, userID uint, now time.Time) (*[]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []models.Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, plainSession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+		}
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return &sessions, nil
+}
+
+func (s *Store) TouchSession(ctx context.Context, session *models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.sessions[session.ID]; ok {
+		stored.LastSeenAt = session.LastSeenAt
+		stored.IP = session.IP
+		stored.ExpiresAt = session.ExpiresAt
+		s.sessions[session.ID] = stored
+	}
+	return nil
+}
+
+func (s *Store) RevokeSession(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if session, ok := s.sessions[sessionID]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &now
+		s.sessions[sessionID] = session
+	}
+	for _, token := range s.refreshTokens {
+		if token.SessionID == sessionID && token.RevokedAt == nil {
+			s.revokeRefreshToken(token, now)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.refreshTokenByHash(tokenHash)
+	if !ok {
+		return nil, notFound("failed get refresh token")
+	}
+	stored.RevokedAt = clone(stored.RevokedAt)
+	return &stored, nil
+}
+
+func (s *Store) RotateRefreshToken(ctx context.Context, old *models.RefreshToken, next *models.RefreshToken) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.refreshTokens[old.ID]
+	if !ok || stored.RevokedAt != nil {
+		return false, nil
+	}
+	if _, ok := s.refreshTokenByHash(next.TokenHash); ok {
+		return false, fmt.Errorf("failed rotate refresh token: %w", gorm.ErrDuplicatedKey)
+	}
+	s.revokeRefreshToken(stored, time.Now().UTC())
+	s.saveRefreshToken(next)
+	return true, nil
+}
+
+func (s *Store) RevokeUserTokens(ctx context.Context, userID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[userID]; ok {
+		user.TokenVersion++
+		s.users[userID] = user
+	}
+	now := time.Now().UTC()
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			s.sessions[id] = session
+		}
+	}
+	for _, token := range s.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			s.revokeRefreshToken(token, now)
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, token := range s.refreshTokens {
+		session, ok := s.sessions[token.SessionID]
+		if token.ExpiresAt.Before(before) || (ok && session.ExpiresAt.Before(before)) {
+			delete(s.refreshTokens, id)
+			deleted++
+		}
+	}
+	for id, session := range s.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(s.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *Store) refreshTokenByHash(tokenHash string) (models.RefreshToken, bool) {
+	for _, token := range s.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return token, true
+		}
+	}
+	return models.RefreshToken{}, false
+}
+
+func (s *Store) saveRefreshToken(token *models.RefreshToken) {
+	now := time.Now()
+	token.ID = s.nextID("refresh_tokens")
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	stored := *token
+	stored.RevokedAt = clone(token.RevokedAt)
+	s.refreshTokens[token.ID] = stored
+}
+
+func (s *Store) revokeRefreshToken(token models.RefreshToken, revoked time.Time) {
+	token.RevokedAt = &revoked
+	token.UpdatedAt = time.Now()
+	s.refreshTokens[token.ID] = token
+}
+
+func plainSession(session models.Session) models.Session {
+	session.RevokedAt = clone(session.RevokedAt)
+	return session
+}
@@ -0,0 +1,960 @@
+// Package disciplinetest содержит хранилище discipline.Store в памяти для тестов
+// и общий набор тестов, которому должна соответствовать любая реализация хранилища.
+package disciplinetest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/core/discipline"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var _ discipline.Store = (*Store)(nil)
+
+// Store - хранилище в памяти. Повторяет поведение database.Storage: те же выборки,
+// сортировки и ошибки (gorm.ErrRecordNotFound, gorm.ErrDuplicatedKey и ошибки apperr).
+// Методы безопасны для вызова из нескольких горутин.
+type Store struct {
+	mu  sync.Mutex
+	ids map[string]uint
+
+	users         map[uint]models.User
+	userRoles     map[uint][]uint
+	roles         map[uint]models.Role
+	roleActions   map[uint][]uint
+	actions       map[uint]models.Action
+	masters       map[uint]models.UserMaster
+	masterPlayers map[uint][]uint
+	playerJoined  map[[2]uint]time.Time
+	members       map[uint]models.MasterMember
+	invites       map[uint]models.MasterInvite
+	quests        map[uint]models.Quest
+	questPlayers  map[uint][]uint
+	bonuses       map[uint]models.QuestStreakBonus
+	statuses      map[uint]models.QuestPlayerStatus
+	attachments   map[uint]models.ProofAttachment
+	comments      map[uint]models.QuestComment
+	streaks       map[uint]models.QuestStreak
+	penalties     map[uint]models.QuestPenalty
+	rewards       map[uint]models.Reward
+	purchases     map[uint]models.RewardPurchase
+	wallets       map[uint]models.PlayerWallet
+	transactions  map[uint]models.WalletTransaction
+	sessions      map[string]models.Session
+	refreshTokens map[uint]models.RefreshToken
+}
+
+// NewStore создает пустое хранилище с ролями и действиями по умолчанию,
+// как после миграций 0010_default_roles и 0016_permissions.
+func NewStore() *Store {
+	s := &Store{
+		ids:           map[string]uint{},
+		users:         map[uint]models.User{},
+		userRoles:     map[uint][]uint{},
+		roles:         map[uint]models.Role{},
+		roleActions:   map[uint][]uint{},
+		actions:       map[uint]models.Action{},
+		masters:       map[uint]models.UserMaster{},
+		masterPlayers: map[uint][]uint{},
+		playerJoined:  map[[2]uint]time.Time{},
+		members:       map[uint]models.MasterMember{},
+		invites:       map[uint]models.MasterInvite{},
+		quests:        map[uint]models.Quest{},
+		questPlayers:  map[uint][]uint{},
+		bonuses:       map[uint]models.QuestStreakBonus{},
+		statuses:      map[uint]models.QuestPlayerStatus{},
+		attachments:   map[uint]models.ProofAttachment{},
+		comments:      map[uint]models.QuestComment{},
+		streaks:       map[uint]models.QuestStreak{},
+		penalties:     map[uint]models.QuestPenalty{},
+		rewards:       map[uint]models.Reward{},
+		purchases:     map[uint]models.RewardPurchase{},
+		wallets:       map[uint]models.PlayerWallet{},
+		transactions:  map[uint]models.WalletTransaction{},
+		sessions:      map[string]models.Session{},
+		refreshTokens: map[uint]models.RefreshToken{},
+	}
+
+	now := time.Now()
+	for _, p := range models.Permissions {
+		action := models.Action{Model: gorm.Model{ID: s.nextID("actions"), CreatedAt: now, UpdatedAt: now}, Name: p.Name}
+		s.actions[action.ID] = action
+	}
+	for _, r := range models.DefaultRoles {
+		role := models.Role{Model: gorm.Model{ID: s.nextID("roles"), CreatedAt: now, UpdatedAt: now}, Name: r.Name}
+		s.roles[role.ID] = role
+		for _, action := range r.Actions {
+			s.roleActions[role.ID] = append(s.roleActions[role.ID], action.ID)
+		}
+	}
+
+	return s
+}
+
+// nextID выдает следующий идентификатор таблицы, как последовательность в базе.
+func (s *Store) nextID(table string) uint {
+	s.ids[table]++
+	return s.ids[table]
+}
+
+// notFound повторяет ошибку gorm для First без найденной записи.
+func notFound(msg string) error {
+	return fmt.Errorf("%s: %w", msg, gorm.ErrRecordNotFound)
+}
+
+func (s *Store) NewUser(ctx context.Context, login, passwordHash string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userByLogin(login); ok {
+		return nil, fmt.Errorf("failed create user: %w", gorm.ErrDuplicatedKey)
+	}
+	now := time.Now()
+	user := models.User{
+		ID:           s.nextID("users"),
+		Login:        login,
+		PasswordHash: passwordHash,
+		TimeZone:     "UTC",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	s.users[user.ID] = user
+	return &user, nil
+}
+
+func (s *Store) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.userByLogin(login)
+	if !ok {
+		return nil, notFound("failed find user")
+	}
+	return s.loadUser(user), nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, userID uint) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, notFound("failed find user")
+	}
+	return s.loadUser(user), nil
+}
+
+func (s *Store) FindUsers(ctx context.Context, query string, offset, limit int) (*[]models.User, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := []models.User{}
+	for _, id := range sortedIDs(s.users) {
+		if strings.Contains(strings.ToLower(s.users[id].Login), strings.ToLower(query)) {
+			found = append(found, *s.loadUser(s.users[id]))
+		}
+	}
+	total := int64(len(found))
+	found = found[min(offset, len(found)):]
+	found = found[:min(limit, len(found))]
+	return &found, total, nil
+}
+
+func (s *Store) GetUserStats(ctx context.Context) (*models.UserStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := &models.UserStats{Users: int64(len(s.users))}
+	for id, user := range s.users {
+		if user.LockedAt != nil {
+			stats.Locked++
+		}
+		for _, member := range s.userMemberships(id) {
+			if member.Role == models.MemberOwner {
+				stats.Masters++
+				break
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (s *Store) SetUserLocked(ctx context.Context, userID uint, lockedAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return notFound("failed update user lock")
+	}
+	user.LockedAt = lockedAt
+	user.UpdatedAt = time.Now()
+	s.users[userID] = user
+	return nil
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, userID uint, passwordHash string, mustChange bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return notFound("failed update user password")
+	}
+	user.PasswordHash = passwordHash
+	user.MustChangePassword = mustChange
+	user.UpdatedAt = time.Now()
+	s.users[userID] = user
+	return nil
+}
+
+func (s *Store) GetRoles(ctx context.Context) (*[]models.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	roles := []models.Role{}
+	for _, id := range sortedIDs(s.roles) {
+		role := s.roles[id]
+		role.Actions = []models.Action{}
+		for _, actionID := range s.roleActions[id] {
+			role.Actions = append(role.Actions, s.actions[actionID])
+		}
+		roles = append(roles, role)
+	}
+	return &roles, nil
+}
+
+func (s *Store) SetUserRoles(ctx context.Context, userID uint, roleIDs []uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return notFound("failed set user roles")
+	}
+	ids := []uint{}
+	for _, id := range roleIDs {
+		if _, ok := s.roles[id]; ok && !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	s.userRoles[userID] = ids
+	return nil
+}
+
+func (s *Store) SetUserTimeZone(ctx context.Context, userID uint, timeZone string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[userID]; ok {
+		user.TimeZone = timeZone
+		user.UpdatedAt = time.Now()
+		s.users[userID] = user
+	}
+	return nil
+}
+
+func (s *Store) SetUserActiveMaster(ctx context.Context, userID uint, masterID *uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, ok := s.users[userID]; ok {
+		user.ActiveMasterID = masterID
+		user.UpdatedAt = time.Now()
+		s.users[userID] = user
+	}
+	return nil
+}
+
+// userByLogin ищет пользователя без учета регистра логина.
+func (s *Store) userByLogin(login string) (models.User, bool) {
+	for _, id := range sortedIDs(s.users) {
+		if strings.EqualFold(s.users[id].Login, login) {
+			return s.users[id], true
+		}
+	}
+	return models.User{}, false
+}
+
+// loadUser дополняет пользователя ролями с действиями и участием в группах.
+func (s *Store) loadUser(user models.User) *models.User {
+	user.Roles = []models.Role{}
+	for _, roleID := range s.userRoles[user.ID] {
+		role := s.roles[roleID]
+		role.Actions = []models.Action{}
+		for _, actionID := range s.roleActions[roleID] {
+			role.Actions = append(role.Actions, s.actions[actionID])
+		}
+		user.Roles = append(user.Roles, role)
+	}
+	user.Memberships = s.userMemberships(user.ID)
+	return &user
+}
+
+func (s *Store) masterPlayersList(masterID uint) []models.User {
+	players := []models.User{}
+	for _, id := range s.masterPlayers[masterID] {
+		players = append(players, s.users[id])
+	}
+	return players
+}
+
+func (s *Store) NewMaster(ctx context.Context, master *models.UserMaster) (*models.UserMaster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range s.masters {
+		if m.UniqueCode == master.UniqueCode {
+			return nil, fmt.Errorf("failed create quest master: failed create master: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+	if _, ok := s.users[master.UserID]; !ok {
+		return nil, notFound("failed create quest master: failed get master owner")
+	}
+
+	now := time.Now()
+	master.ID = s.nextID("user_masters")
+	master.CreatedAt = now
+	master.UpdatedAt = now
+	stored := *master
+	stored.Players = nil
+	s.masters[master.ID] = stored
+	s.addMember(master.ID, master.UserID, models.MemberOwner)
+	if !contains(s.userRoles[master.UserID], models.RoleQuestMasterObject.ID) {
+		s.userRoles[master.UserID] = append(s.userRoles[master.UserID], models.RoleQuestMasterObject.ID)
+	}
+
+	return master, nil
+}
+
+func (s *Store) UpdMasterName(ctx context.Context, masterID uint, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	master, ok := s.masters[masterID]
+	if !ok {
+		return notFound("failed update master name")
+	}
+	master.Name = name
+	master.UpdatedAt = time.Now()
+	s.masters[masterID] = master
+	return nil
+}
+
+func (s *Store) GetMasterByID(ctx context.Context, masterID uint) (*models.UserMaster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	master, ok := s.masters[masterID]
+	if !ok {
+		return nil, notFound("failed get quest master")
+	}
+	master.Players = s.masterPlayersList(master.ID)
+	return &master, nil
+}
+
+func (s *Store) GetMasterByCode(ctx context.Context, code string) (*models.UserMaster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range sortedIDs(s.masters) {
+		if s.masters[id].UniqueCode == code {
+			master := s.masters[id]
+			return &master, nil
+		}
+	}
+	return nil, notFound("failed find master by code")
+}
+
+func (s *Store) GetMastersByPlayerID(ctx context.Context, playerID uint) (*[]models.UserMaster, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	masters := []models.UserMaster{}
+	for _, id := range sortedIDs(s.masters) {
+		if contains(s.masterPlayers[id], playerID) {
+			masters = append(masters, s.masters[id])
+		}
+	}
+	return &masters, nil
+}
+
+func (s *Store) AddPlayerForMaster(ctx context.Context, masterID, playerID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.masters[masterID]; !ok {
+		return notFound("failed adding player for master: failed get master")
+	}
+	if _, ok := s.users[playerID]; !ok {
+		return notFound("failed adding player for master: failed get player")
+	}
+	if !contains(s.masterPlayers[masterID], playerID) {
+		s.masterPlayers[masterID] = append(s.masterPlayers[masterID], playerID)
+		s.playerJoined[[2]uint{masterID, playerID}] = time.Now()
+	}
+	return nil
+}
+
+func (s *Store) GetMasterPlayerJoinedAt(ctx context.Context, masterID, playerID uint) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !contains(s.masterPlayers[masterID], playerID) {
+		return nil, notFound("failed get master player")
+	}
+	joined, ok := s.playerJoined[[2]uint{masterID, playerID}]
+	if !ok {
+		return nil, nil
+	}
+	return &joined, nil
+}
+
+func (s *Store) NewQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveQuest(quest)
+	return quest, nil
+}
+
+func (s *Store) UpdQuest(ctx context.Context, quest *models.Quest) (*models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range sortedIDs(s.bonuses) {
+		if s.bonuses[id].QuestID == quest.ID {
+			delete(s.bonuses, id)
+		}
+	}
+	s.questPlayers[quest.ID] = nil
+	s.saveQuest(quest)
+	return quest, nil
+}
+
+// saveQuest сохраняет квест вместе с игроками и бонусами за серию.
+func (s *Store) saveQuest(quest *models.Quest) {
+	now := time.Now()
+	if quest.ID == 0 {
+		quest.ID = s.nextID("quests")
+	}
+	if stored, ok := s.quests[quest.ID]; ok && quest.CreatedAt.IsZero() {
+		quest.CreatedAt = stored.CreatedAt
+	}
+	if quest.CreatedAt.IsZero() {
+		quest.CreatedAt = now
+	}
+	quest.UpdatedAt = now
+	s.quests[quest.ID] = plainQuest(*quest)
+
+	for _, p := range quest.Players {
+		if !contains(s.questPlayers[quest.ID], p.ID) {
+			s.questPlayers[quest.ID] = append(s.questPlayers[quest.ID], p.ID)
+		}
+	}
+	for i := range quest.StreakBonuses {
+		bonus := &quest.StreakBonuses[i]
+		if bonus.ID == 0 {
+			bonus.ID = s.nextID("quest_streak_bonus")
+		}
+		bonus.QuestID = quest.ID
+		bonus.CreatedAt = now
+		bonus.UpdatedAt = now
+		s.bonuses[bonus.ID] = *bonus
+	}
+}
+
+func (s *Store) GetQuest(ctx context.Context, questID uint) (*models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.quests[questID]
+	if !ok {
+		return nil, notFound("failed get quest")
+	}
+	quest := plainQuest(stored)
+	quest.Players = s.questPlayersList(quest.ID)
+	quest.StreakBonuses = s.streakBonuses(quest.ID)
+	quest.User = s.users[quest.UserID]
+	quest.UserMaster = s.masters[quest.UserMasterID]
+	return &quest, nil
+}
+
+func (s *Store) GetQuests(ctx context.Context, masterID uint) (*[]models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quests := []models.Quest{}
+	for _, id := range sortedIDs(s.quests) {
+		if s.quests[id].UserMasterID != masterID {
+			continue
+		}
+		quest := plainQuest(s.quests[id])
+		quest.Players = s.questPlayersList(id)
+		quests = append(quests, quest)
+	}
+	sortByUpdatedDesc(quests, func(q models.Quest) (time.Time, uint) { return q.UpdatedAt, q.ID })
+	return &quests, nil
+}
+
+func (s *Store) questPlayersList(questID uint) []models.User {
+	players := []models.User{}
+	for _, id := range s.questPlayers[questID] {
+		players = append(players, s.users[id])
+	}
+	return players
+}
+
+func (s *Store) streakBonuses(questID uint) []models.QuestStreakBonus {
+	bonuses := []models.QuestStreakBonus{}
+	for _, id := range sortedIDs(s.bonuses) {
+		if s.bonuses[id].QuestID == questID {
+			bonuses = append(bonuses, s.bonuses[id])
+		}
+	}
+	return bonuses
+}
+
+func (s *Store) GetPlayerQuests(ctx context.Context, playerID uint) (*[]models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	quests := []models.Quest{}
+	for _, id := range sortedIDs(s.quests) {
+		if s.playerCanSee(s.quests[id], playerID, now) {
+			quests = append(quests, plainQuest(s.quests[id]))
+		}
+	}
+	sortByUpdatedDesc(quests, func(q models.Quest) (time.Time, uint) { return q.UpdatedAt, q.ID })
+	return &quests, nil
+}
+
+func (s *Store) GetPlayerQuest(ctx context.Context, questID, playerID uint) (*models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.quests[questID]
+	if !ok || !s.playerCanSee(stored, playerID, time.Now()) {
+		return nil, notFound("failed find quest by player")
+	}
+	quest := plainQuest(stored)
+	return &quest, nil
+}
+
+// playerCanSee повторяет отбор квестов игрока в database.Storage: активный квест мастера игрока
+// в пределах дат, без списка игроков или с игроком в списке, разовый квест - пока не оплачен.
+func (s *Store) playerCanSee(quest models.Quest, playerID uint, now time.Time) bool {
+	if !quest.IsActive {
+		return false
+	}
+	if quest.StartTime != nil && quest.StartTime.After(now) {
+		return false
+	}
+	if quest.EndTime != nil && quest.EndTime.Before(now) {
+		return false
+	}
+	if !contains(s.masterPlayers[quest.UserMasterID], playerID) {
+		return false
+	}
+	if players := s.questPlayers[quest.ID]; len(players) > 0 && !contains(players, playerID) {
+		return false
+	}
+	if quest.Type == models.OneTime {
+		for _, status := range s.statuses {
+			if status.QuestID == quest.ID && status.PlayerID == playerID && status.AccrualDate != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Store) GetAwaitQuests(ctx context.Context, masterID uint) (*[]models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	awaits := []models.QuestPlayerStatus{}
+	for _, id := range sortedIDs(s.statuses) {
+		status := s.statuses[id]
+		quest, ok := s.quests[status.QuestID]
+		if !ok || quest.UserMasterID != masterID || status.ConfirmationDate != nil {
+			continue
+		}
+		if status.RejectExecuteDate != nil &&
+			(status.RequestExecuteDate == nil || !status.RequestExecuteDate.After(*status.RejectExecuteDate)) {
+			continue
+		}
+		status = plainStatus(status)
+		status.Player = s.users[status.PlayerID]
+		status.Quest = plainQuest(quest)
+		status.Attachments = s.statusAttachments(id)
+		status.Comments = s.statusComments(id)
+		awaits = append(awaits, status)
+	}
+	sort.SliceStable(awaits, func(i, j int) bool {
+		a, b := awaits[i].RequestExecuteDate, awaits[j].RequestExecuteDate
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+	return &awaits, nil
+}
+
+func (s *Store) GetAwaitQUest(ctx context.Context, awaitID uint) (*models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.statuses[awaitID]
+	if !ok {
+		return nil, notFound("failed get quest status")
+	}
+	status := plainStatus(stored)
+	status.Quest = plainQuest(s.quests[status.QuestID])
+	return &status, nil
+}
+
+// UpdAwaitQuest записывает решение мастера по отправке, которая еще ждет проверки.
+// Штраф penalty, если он задан, списывается вместе с решением.
+func (s *Store) UpdAwaitQuest(ctx context.Context, status *models.QuestPlayerStatus, penalty *models.QuestPenalty, comment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.statuses[status.ID]
+	if !ok || stored.ConfirmationDate != nil || (stored.RejectExecuteDate != nil &&
+		(stored.RequestExecuteDate == nil || !stored.RejectExecuteDate.Before(*stored.RequestExecuteDate))) {
+		return fmt.Errorf("failed update await quest: %w", apperr.ErrQuestStatusClosed)
+	}
+	stored.ConfirmationDate = clone(status.ConfirmationDate)
+	stored.RejectExecuteDate = clone(status.RejectExecuteDate)
+	stored.RejectReason = status.RejectReason
+	stored.UpdatedAt = time.Now()
+	s.statuses[status.ID] = stored
+
+	if penalty != nil {
+		s.chargePenalty(penalty, comment)
+	}
+	return nil
+}
+
+func (s *Store) GetPlayerQuestStatus(ctx context.Context, questID, playerID uint, period string) (*models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.playerQuestStatus(questID, playerID, period)
+}
+
+func (s *Store) playerQuestStatus(questID, playerID uint, period string) (*models.QuestPlayerStatus, error) {
+	for _, id := range sortedIDs(s.statuses) {
+		status := s.statuses[id]
+		if status.QuestID != questID || status.PlayerID != playerID || status.PeriodKey != period {
+			continue
+		}
+		status = plainStatus(status)
+		status.Quest = plainQuest(s.quests[questID])
+		status.Attachments = s.statusAttachments(id)
+		status.Comments = s.statusComments(id)
+		return &status, nil
+	}
+	return nil, notFound("failed find quest player status")
+}
+
+func (s *Store) SendPlayerQuest(ctx context.Context, questID, playerID uint, period, comment string, attachments []models.ProofAttachment) (*models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.playerQuestStatus(questID, playerID, period); err == nil {
+		return nil, fmt.Errorf("failed send quest status: %w", gorm.ErrDuplicatedKey)
+	}
+	now := time.Now()
+	current := now.UTC()
+	status := models.QuestPlayerStatus{
+		Model:              gorm.Model{ID: s.nextID("quest_player_statuses"), CreatedAt: now, UpdatedAt: now},
+		QuestID:            questID,
+		PlayerID:           playerID,
+		PeriodKey:          period,
+		RequestExecuteDate: &current,
+		ProofComment:       comment,
+	}
+	s.statuses[status.ID] = status
+	s.addAttachments(status.ID, attachments)
+
+	return s.playerQuestStatus(questID, playerID, period)
+}
+
+// UpdSendStatusPlayerQuest повторно отправляет квест на проверку.
+// Подтверждение прошлой отправки заменяется новым.
+func (s *Store) UpdSendStatusPlayerQuest(ctx context.Context, status *models.QuestPlayerStatus) (*models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	currentTime := time.Now().UTC()
+	status.RequestExecuteDate = &currentTime
+	for _, id := range sortedIDs(s.attachments) {
+		if s.attachments[id].QuestPlayerStatusID == status.ID {
+			delete(s.attachments, id)
+		}
+	}
+	if stored, ok := s.statuses[status.ID]; ok {
+		stored.RequestExecuteDate = clone(status.RequestExecuteDate)
+		stored.ProofComment = status.ProofComment
+		stored.UpdatedAt = time.Now()
+		s.statuses[status.ID] = stored
+	}
+	for i := range status.Attachments {
+		status.Attachments[i].ID = 0
+	}
+	s.addAttachments(status.ID, status.Attachments)
+	return status, nil
+}
+
+func (s *Store) addAttachments(statusID uint, attachments []models.ProofAttachment) {
+	now := time.Now()
+	for i := range attachments {
+		attachment := &attachments[i]
+		attachment.ID = s.nextID("proof_attachments")
+		attachment.QuestPlayerStatusID = statusID
+		attachment.CreatedAt = now
+		attachment.UpdatedAt = now
+		stored := *attachment
+		stored.QuestPlayerStatus = models.QuestPlayerStatus{}
+		s.attachments[attachment.ID] = stored
+	}
+}
+
+func (s *Store) statusAttachments(statusID uint) []models.ProofAttachment {
+	attachments := []models.ProofAttachment{}
+	for _, id := range sortedIDs(s.attachments) {
+		if s.attachments[id].QuestPlayerStatusID == statusID {
+			attachments = append(attachments, s.attachments[id])
+		}
+	}
+	return attachments
+}
+
+func (s *Store) GetProofAttachment(ctx context.Context, attachmentID uint) (*models.ProofAttachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, ok := s.attachments[attachmentID]
+	if !ok {
+		return nil, notFound("failed get proof attachment")
+	}
+	attachment.QuestPlayerStatus = plainStatus(s.statuses[attachment.QuestPlayerStatusID])
+	attachment.QuestPlayerStatus.Quest = plainQuest(s.quests[attachment.QuestPlayerStatus.QuestID])
+	return &attachment, nil
+}
+
+func (s *Store) GetQuestComments(ctx context.Context, statusID uint) (*[]models.QuestComment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comments := s.statusComments(statusID)
+	return &comments, nil
+}
+
+func (s *Store) NewQuestComment(ctx context.Context, comment *models.QuestComment) (*models.QuestComment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	comment.ID = s.nextID("quest_comments")
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	stored := *comment
+	stored.QuestPlayerStatus = models.QuestPlayerStatus{}
+	stored.Author = models.User{}
+	s.comments[comment.ID] = stored
+	comment.Author = s.users[comment.AuthorID]
+	return comment, nil
+}
+
+// statusComments - обсуждение отправки по времени создания вместе с авторами.
+func (s *Store) statusComments(statusID uint) []models.QuestComment {
+	comments := []models.QuestComment{}
+	for _, id := range sortedIDs(s.comments) {
+		if s.comments[id].QuestPlayerStatusID == statusID {
+			comment := s.comments[id]
+			comment.Author = s.users[comment.AuthorID]
+			comments = append(comments, comment)
+		}
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+	return comments
+}
+
+func (s *Store) GetQuestNotPayed(ctx context.Context) (*[]models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := []models.QuestPlayerStatus{}
+	for _, id := range sortedIDs(s.statuses) {
+		status := s.statuses[id]
+		if status.AccrualDate != nil || status.ConfirmationDate == nil {
+			continue
+		}
+		status = plainStatus(status)
+		status.Quest = plainQuest(s.quests[status.QuestID])
+		status.Quest.StreakBonuses = s.streakBonuses(status.QuestID)
+		statuses = append(statuses, status)
+		if len(statuses) == 100 {
+			break
+		}
+	}
+	return &statuses, nil
+}
+
+// PayQuest начисляет награду за подтвержденный квест, пересчитывает серию игрока
+// и начисляет бонусы за серию отдельными записями журнала.
+func (s *Store) PayQuest(ctx context.Context, quest *models.QuestPlayerStatus, onStreak discipline.StreakFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.statuses[quest.ID]
+	if !ok || stored.AccrualDate != nil {
+		return nil
+	}
+	now := time.Now()
+	currentTime := now.UTC()
+	stored.AccrualDate = &currentTime
+	stored.UpdatedAt = now
+	s.statuses[quest.ID] = stored
+	quest.AccrualDate = &currentTime
+
+	statusID := quest.ID
+	masterID := quest.Quest.UserMasterID
+	s.postTransaction(&models.WalletTransaction{
+		UserMasterID:        masterID,
+		PlayerID:            quest.PlayerID,
+		Type:                models.TransactionAccrual,
+		Amount:              int(quest.Quest.Price),
+		Comment:             quest.Quest.Title,
+		QuestPlayerStatusID: &statusID,
+	})
+	if onStreak == nil {
+		return nil
+	}
+
+	streak := models.QuestStreak{QuestID: stored.QuestID, PlayerID: stored.PlayerID}
+	for _, id := range sortedIDs(s.streaks) {
+		if s.streaks[id].QuestID == stored.QuestID && s.streaks[id].PlayerID == stored.PlayerID {
+			streak = s.streaks[id]
+		}
+	}
+	paid := []string{}
+	for _, id := range sortedIDs(s.statuses) {
+		status := s.statuses[id]
+		if status.QuestID == stored.QuestID && status.PlayerID == stored.PlayerID && status.AccrualDate != nil {
+			paid = append(paid, status.PeriodKey)
+		}
+	}
+	bonuses := onStreak(&streak, paid)
+	if streak.ID == 0 {
+		streak.ID = s.nextID("quest_streaks")
+		streak.CreatedAt = now
+	}
+	streak.UpdatedAt = now
+	s.streaks[streak.ID] = streak
+	for _, bonus := range bonuses {
+		s.postTransaction(&models.WalletTransaction{
+			UserMasterID:        masterID,
+			PlayerID:            quest.PlayerID,
+			Type:                models.TransactionStreak,
+			Amount:              int(bonus.Bonus),
+			Comment:             fmt.Sprintf("%s: серия %d", quest.Quest.Title, bonus.Days),
+			QuestPlayerStatusID: &statusID,
+		})
+	}
+	return nil
+}
+
+// like проверяет значение по шаблону SQL LIKE с символами % и _.
+func like(value, pattern string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expr.WriteString(".*")
+		case '_':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(value)
+}
+
+// plainQuest копирует квест без связей, чтобы вызывающий не менял данные хранилища.
+func plainQuest(q models.Quest) models.Quest {
+	q.User = models.User{}
+	q.UserMaster = models.UserMaster{}
+	q.Players = nil
+	q.StreakBonuses = nil
+	q.StartTime = clone(q.StartTime)
+	q.EndTime = clone(q.EndTime)
+	return q
+}
+
+func plainStatus(status models.QuestPlayerStatus) models.QuestPlayerStatus {
+	status.Player = models.User{}
+	status.Quest = models.Quest{}
+	status.Attachments = nil
+	status.Comments = nil
+	status.RequestExecuteDate = clone(status.RequestExecuteDate)
+	status.RejectExecuteDate = clone(status.RejectExecuteDate)
+	status.ConfirmationDate = clone(status.ConfirmationDate)
+	status.AccrualDate = clone(status.AccrualDate)
+	return status
+}
+
+func clone[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	v := *value
+	return &v
+}
+
+func contains(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedIDs возвращает ключи таблицы по возрастанию, чтобы обход не зависел от порядка map.
+func sortedIDs[T any](table map[uint]T) []uint {
+	ids := make([]uint, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// sortByUpdatedDesc сортирует записи по времени изменения, более новые первыми.
+func sortByUpdatedDesc[T any](items []T, key func(T) (time.Time, uint)) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, idi := key(items[i])
+		tj, idj := key(items[j])
+		if ti.Equal(tj) {
+			return idi > idj
+		}
+		return ti.After(tj)
+	})
+}
@@ -0,0 +1,13 @@
+package disciplinetest
+
+import (
+	"testing"
+
+	"github.com/mod-develop/backend/internal/core/discipline"
+)
+
+func TestMemoryStore(t *testing.T) {
+	TestStore(t, func(t *testing.T) discipline.Store {
+		return NewStore()
+	})
+}
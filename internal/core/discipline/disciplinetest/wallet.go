@@ -0,0 +1,321 @@
+package disciplinetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// walletBalance - баланс кошелька игрока у мастера после операции на amount.
+func (s *Store) walletBalance(masterID, playerID uint, amount int) int {
+	if wallet, ok := s.walletOf(masterID, playerID); ok {
+		return wallet.Balance + amount
+	}
+	return amount
+}
+
+func (s *Store) walletOf(masterID, playerID uint) (models.PlayerWallet, bool) {
+	for _, id := range sortedIDs(s.wallets) {
+		if s.wallets[id].UserMasterID == masterID && s.wallets[id].PlayerID == playerID {
+			return s.wallets[id], true
+		}
+	}
+	return models.PlayerWallet{}, false
+}
+
+// postTransaction добавляет запись в журнал кошелька и обновляет его баланс,
+// при отсутствии кошелек создается. Проверка овердрафта остается за вызывающим.
+func (s *Store) postTransaction(txn *models.WalletTransaction) {
+	now := time.Now()
+	wallet, ok := s.walletOf(txn.UserMasterID, txn.PlayerID)
+	if !ok {
+		wallet = models.PlayerWallet{
+			Model:        gorm.Model{ID: s.nextID("player_wallets"), CreatedAt: now},
+			UserMasterID: txn.UserMasterID,
+			PlayerID:     txn.PlayerID,
+		}
+	}
+	wallet.Balance += txn.Amount
+	wallet.UpdatedAt = now
+	s.wallets[wallet.ID] = wallet
+
+	txn.ID = s.nextID("wallet_transactions")
+	txn.CreatedAt = now
+	txn.UpdatedAt = now
+	txn.WalletID = wallet.ID
+	txn.Balance = wallet.Balance
+	s.transactions[txn.ID] = plainTransaction(*txn)
+}
+
+func (s *Store) CountUnbalancedWallets(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, wallet := range s.wallets {
+		total := 0
+		for _, txn := range s.transactions {
+			if txn.WalletID == wallet.ID && !txn.DeletedAt.Valid {
+				total += txn.Amount
+			}
+		}
+		if !wallet.DeletedAt.Valid && wallet.Balance != total {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) GetWallets(ctx context.Context, playerID uint) (*[]models.PlayerWallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallets := []models.PlayerWallet{}
+	for _, id := range sortedIDs(s.wallets) {
+		if s.wallets[id].PlayerID == playerID {
+			wallets = append(wallets, s.wallets[id])
+		}
+	}
+	return &wallets, nil
+}
+
+func (s *Store) GetWallet(ctx context.Context, walletID uint) (*models.PlayerWallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.wallets[walletID]
+	if !ok {
+		return nil, notFound("failed get wallet")
+	}
+	wallet.Player = s.users[wallet.PlayerID]
+	return &wallet, nil
+}
+
+func (s *Store) GetMasterWallets(ctx context.Context, masterID uint) (*[]models.PlayerWallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallets := []models.PlayerWallet{}
+	for _, id := range sortedIDs(s.wallets) {
+		if wallet := s.wallets[id]; wallet.UserMasterID == masterID {
+			wallet.Player = s.users[wallet.PlayerID]
+			wallets = append(wallets, wallet)
+		}
+	}
+	sortBy(wallets, func(w models.PlayerWallet) uint { return w.PlayerID })
+	return &wallets, nil
+}
+
+func (s *Store) AdjustWallet(ctx context.Context, txn *models.WalletTransaction) (*models.WalletTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txn.Type = models.TransactionAdjustment
+	s.postTransaction(txn)
+	return txn, nil
+}
+
+func (s *Store) GetWalletTransaction(ctx context.Context, transactionID uint) (*models.WalletTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txn, ok := s.transactions[transactionID]
+	if !ok {
+		return nil, notFound("failed get wallet transaction")
+	}
+	txn = plainTransaction(txn)
+	return &txn, nil
+}
+
+// ReverseWalletTransaction добавляет в журнал запись, отменяющую исходную.
+// Повторная отмена одной записи возвращает apperr.ErrTransactionReversed.
+func (s *Store) ReverseWalletTransaction(ctx context.Context, original *models.WalletTransaction, authorID uint) (*models.WalletTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.transactions[original.ID]; !ok {
+		return nil, notFound("failed reverse wallet transaction: failed lock wallet transaction")
+	}
+	if s.isReversed(original.ID) {
+		return nil, fmt.Errorf("failed reverse wallet transaction: %w", apperr.ErrTransactionReversed)
+	}
+
+	reversal := &models.WalletTransaction{
+		UserMasterID: original.UserMasterID,
+		PlayerID:     original.PlayerID,
+		Type:         models.TransactionReversal,
+		Amount:       -original.Amount,
+		Comment:      fmt.Sprintf("reversal of #%d", original.ID),
+		AuthorID:     &authorID,
+		ReversalOfID: &original.ID,
+	}
+	s.postTransaction(reversal)
+	return reversal, nil
+}
+
+func (s *Store) isReversed(transactionID uint) bool {
+	for _, txn := range s.transactions {
+		if txn.ReversalOfID != nil && *txn.ReversalOfID == transactionID && !txn.DeletedAt.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) GetWalletTransactions(ctx context.Context, walletID uint, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	return s.findTransactions(func(txn models.WalletTransaction) bool { return txn.WalletID == walletID }, offset, limit)
+}
+
+func (s *Store) GetPlayerTransactions(ctx context.Context, playerID uint, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	return s.findTransactions(func(txn models.WalletTransaction) bool { return txn.PlayerID == playerID }, offset, limit)
+}
+
+// findTransactions - страница журнала от новых записей к старым и общее число записей.
+// Отрицательный limit, как и в gorm, снимает ограничение.
+func (s *Store) findTransactions(match func(models.WalletTransaction) bool, offset, limit int) (*[]models.WalletTransaction, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := sortedIDs(s.transactions)
+	all := []models.WalletTransaction{}
+	for i := len(ids) - 1; i >= 0; i-- {
+		if txn := s.transactions[ids[i]]; match(txn) {
+			all = append(all, txn)
+		}
+	}
+
+	txns := []models.WalletTransaction{}
+	for i, txn := range all {
+		if i < offset || (limit >= 0 && len(txns) >= limit) {
+			continue
+		}
+		txn = plainTransaction(txn)
+		txn.IsReversed = s.isReversed(txn.ID)
+		txns = append(txns, txn)
+	}
+	return &txns, int64(len(all)), nil
+}
+
+// GetPenaltyQuests - активные повторяющиеся квесты со штрафом за пропуск.
+func (s *Store) GetPenaltyQuests(ctx context.Context) (*[]models.Quest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quests := []models.Quest{}
+	for _, id := range sortedIDs(s.quests) {
+		stored := s.quests[id]
+		if !stored.IsActive || stored.Penalty == 0 || stored.Type == models.OneTime {
+			continue
+		}
+		quest := plainQuest(stored)
+		quest.Players = s.questPlayersList(id)
+		quest.UserMaster = s.masters[quest.UserMasterID]
+		quest.UserMaster.Players = s.masterPlayersList(quest.UserMasterID)
+		quests = append(quests, quest)
+	}
+	return &quests, nil
+}
+
+// FindQuestStatuses - отправки квеста игроком в периодах, ключ которых подходит под шаблон LIKE.
+func (s *Store) FindQuestStatuses(ctx context.Context, questID, playerID uint, periodPattern string) (*[]models.QuestPlayerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := []models.QuestPlayerStatus{}
+	for _, id := range sortedIDs(s.statuses) {
+		status := s.statuses[id]
+		if status.QuestID == questID && status.PlayerID == playerID && like(status.PeriodKey, periodPattern) {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].PeriodKey < statuses[j].PeriodKey })
+	return &statuses, nil
+}
+
+// ChargePenalty записывает штраф и списывает его с кошелька игрока, баланс может уйти в минус.
+// Возвращает false, если штраф за этот пропуск уже был списан.
+func (s *Store) ChargePenalty(ctx context.Context, penalty *models.QuestPenalty, comment string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.chargePenalty(penalty, comment), nil
+}
+
+// chargePenalty повторяет уникальные индексы uq_penalty_missed и uq_penalty_rejected.
+func (s *Store) chargePenalty(penalty *models.QuestPenalty, comment string) bool {
+	for _, stored := range s.penalties {
+		if stored.Reason != penalty.Reason {
+			continue
+		}
+		switch penalty.Reason {
+		case models.PenaltyMissed:
+			if stored.QuestID == penalty.QuestID && stored.PlayerID == penalty.PlayerID && stored.PeriodKey == penalty.PeriodKey {
+				return false
+			}
+		case models.PenaltyRejected:
+			if stored.QuestPlayerStatusID != nil && penalty.QuestPlayerStatusID != nil &&
+				*stored.QuestPlayerStatusID == *penalty.QuestPlayerStatusID {
+				return false
+			}
+		}
+	}
+
+	now := time.Now()
+	penalty.ID = s.nextID("quest_penalties")
+	penalty.CreatedAt = now
+	penalty.UpdatedAt = now
+	stored := *penalty
+	stored.Quest = models.Quest{}
+	stored.QuestPlayerStatusID = clone(penalty.QuestPlayerStatusID)
+	s.penalties[penalty.ID] = stored
+
+	penaltyID := penalty.ID
+	s.postTransaction(&models.WalletTransaction{
+		UserMasterID:        penalty.UserMasterID,
+		PlayerID:            penalty.PlayerID,
+		Type:                models.TransactionPenalty,
+		Amount:              -int(penalty.Amount),
+		Comment:             comment,
+		QuestPlayerStatusID: clone(penalty.QuestPlayerStatusID),
+		QuestPenaltyID:      &penaltyID,
+	})
+	return true
+}
+
+func (s *Store) GetQuestStreak(ctx context.Context, questID, playerID uint) (*models.QuestStreak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range sortedIDs(s.streaks) {
+		if streak := s.streaks[id]; streak.QuestID == questID && streak.PlayerID == playerID {
+			return &streak, nil
+		}
+	}
+	return nil, notFound("failed get quest streak")
+}
+
+func (s *Store) GetStreakBonuses(ctx context.Context, questID uint) (*[]models.QuestStreakBonus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bonuses := s.streakBonuses(questID)
+	sortBy(bonuses, func(b models.QuestStreakBonus) uint { return b.Days })
+	return &bonuses, nil
+}
+
+func plainTransaction(txn models.WalletTransaction) models.WalletTransaction {
+	txn.Wallet = models.PlayerWallet{}
+	txn.IsReversed = false
+	txn.AuthorID = clone(txn.AuthorID)
+	txn.QuestPlayerStatusID = clone(txn.QuestPlayerStatusID)
+	txn.RewardPurchaseID = clone(txn.RewardPurchaseID)
+	txn.QuestPenaltyID = clone(txn.QuestPenaltyID)
+	txn.ReversalOfID = clone(txn.ReversalOfID)
+	return txn
+}
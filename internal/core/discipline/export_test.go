@@ -0,0 +1,11 @@
+package discipline
+
+import (
+	"context"
+	"time"
+)
+
+// ChargeMissedPenalties дает внешним тестам запустить проверку штрафов без ожидания воркера.
+func (s *Discipline) ChargeMissedPenalties(ctx context.Context, now time.Time) error {
+	return s.chargeMissedPenalties(ctx, now)
+}
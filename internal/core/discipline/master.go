@@ -0,0 +1,93 @@
+package discipline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+	"github.com/mod-develop/backend/pkg/tools"
+)
+
+var (
+	defaultMasterName = "Основная группа"
+	maxMasterName     = 64
+)
+
+// validateMasterName проверяет название группы, пустое заменяется fallback.
+func validateMasterName(name, fallback string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fallback
+	}
+	if name == "" {
+		return "", apperr.ErrValidation.WithField("name", "Введите название группы")
+	}
+	if utf8.RuneCountInString(name) > maxMasterName {
+		return "", apperr.ErrValidation.
+			WithField("name", fmt.Sprintf("Название должно быть не длиннее %d символов", maxMasterName))
+	}
+	return name, nil
+}
+
+// newMaster создает группу name, владелец userID получает роль мастера квестов.
+func (s *Discipline) newMaster(ctx context.Context, userID uint, name string) (*models.UserMaster, error) {
+	master, err := s.store.NewMaster(ctx, &models.UserMaster{
+		UserID:     userID,
+		Name:       name,
+		UniqueCode: tools.RandomString(lengthMasterCode),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed create quest master: %w", err)
+	}
+	return master, nil
+}
+
+// RenameMaster меняет название группы masterID, переименовывает владелец userID.
+func (s *Discipline) RenameMaster(ctx context.Context, masterID, userID uint, name string) error {
+	if err := s.checkOwner(ctx, masterID, userID); err != nil {
+		return err
+	}
+	name, err := validateMasterName(name, "")
+	if err != nil {
+		return err
+	}
+	if err := s.store.UpdMasterName(ctx, masterID, name); err != nil {
+		return notFound(err, "failed rename master")
+	}
+	return nil
+}
+
+// SwitchMaster делает группу masterID активной для пользователя userID: квесты, проверки
+// и кошельки мастерской части показываются по ней. Своей группой управляет только
+// мастер квестов, поэтому без этой роли переключиться на нее нельзя.
+func (s *Discipline) SwitchMaster(ctx context.Context, userID, masterID uint) error {
+	member, err := s.masterMember(ctx, masterID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.MemberOwner {
+		user, err := s.store.GetUserByID(ctx, userID)
+		if err != nil {
+			return notFound(err, "failed get user")
+		}
+		if !hasRole(user, models.RoleQuestMaster) {
+			return apperr.ErrForbidden
+		}
+	}
+	if err := s.store.SetUserActiveMaster(ctx, userID, &masterID); err != nil {
+		return fmt.Errorf("failed set active master: %w", err)
+	}
+	return nil
+}
+
+func hasRole(user *models.User, name string) bool {
+	for _, role := range user.Roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+	"github.com/mod-develop/backend/pkg/tools"
+)
+
+var (
+	inviteTTL             = time.Hour * 24 * 7
+	inviteCodeBytes       = 12
+	errOwnerRoleImmutable = apperr.ErrValidation.WithField("role", "Роль владельца группы не меняется")
+)
+
+// masterMember возвращает участие пользователя userID в группе masterID.
+// Пользователь вне группы получает apperr.ErrForbidden.
+func (s *Discipline) masterMember(ctx context.Context, masterID, userID uint) (*models.MasterMember, error) {
+	if err := checkMaster(masterID); err != nil {
+		return nil, err
+	}
+	member, err := s.store.GetMasterMember(ctx, masterID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Join(err, apperr.ErrForbidden)
+		}
+		return nil, fmt.Errorf("failed get master member: %w", err)
+	}
+	return member, nil
+}
+
+// checkOwner проверяет, что userID - владелец группы masterID.
+func (s *Discipline) checkOwner(ctx context.Context, masterID, userID uint) error {
+	member, err := s.masterMember(ctx, masterID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.MemberOwner {
+		return apperr.ErrForbidden
+	}
+	return nil
+}
+
+func validateMemberRole(role string) (models.MemberRole, error) {
+	for _, r := range types.MemberRoles {
+		if string(r.Value) == role {
+			return r.Value, nil
+		}
+	}
+	return "", apperr.ErrValidation.WithField("role", "Выберите роль участника")
+}
+
+func inviteToView(invite *models.MasterInvite) types.TMasterInvite {
+	return types.TMasterInvite{
+		ID:        invite.ID,
+		Code:      invite.Code,
+		Role:      invite.Role,
+		RoleTitle: types.MemberRoleTitle(invite.Role),
+		ExpiresAt: invite.ExpiresAt,
+	}
+}
+
+// GetTeam возвращает участников группы masterID, которые видит ее участник userID.
+// Приглашения получает только владелец.
+func (s *Discipline) GetTeam(ctx context.Context, masterID, userID uint) (*types.TMasterTeam, error) {
+	current, err := s.masterMember(ctx, masterID, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.store.GetMasterMembers(ctx, masterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get master members: %w", err)
+	}
+
+	team := &types.TMasterTeam{Members: []types.TMasterMember{}, Invites: []types.TMasterInvite{}}
+	for _, m := range *members {
+		team.Members = append(team.Members, types.TMasterMember{
+			UserID:    m.UserID,
+			Login:     m.User.Login,
+			Role:      m.Role,
+			RoleTitle: types.MemberRoleTitle(m.Role),
+			IsSelf:    m.UserID == userID,
+		})
+	}
+	if current.Role != models.MemberOwner {
+		return team, nil
+	}
+
+	invites, err := s.store.GetMasterInvites(ctx, masterID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed get master invites: %w", err)
+	}
+	for _, invite := range *invites {
+		team.Invites = append(team.Invites, inviteToView(&invite))
+	}
+	return team, nil
+}
+
+// NewInvite создает приглашение в группу masterID с ролью role, приглашает владелец userID.
+func (s *Discipline) NewInvite(ctx context.Context, masterID, userID uint, role string) (*types.TMasterInvite, error) {
+	if err := s.checkOwner(ctx, masterID, userID); err != nil {
+		return nil, err
+	}
+	memberRole, err := validateMemberRole(role)
+	if err != nil {
+		return nil, err
+	}
+	code, err := tools.RandomToken(inviteCodeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed generate invite code: %w", err)
+	}
+
+	invite, err := s.store.NewMasterInvite(ctx, &models.MasterInvite{
+		UserMasterID: masterID,
+		Code:         code,
+		Role:         memberRole,
+		AuthorID:     userID,
+		ExpiresAt:    time.Now().UTC().Add(inviteTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed create master invite: %w", err)
+	}
+	result := inviteToView(invite)
+	return &result, nil
+}
+
+// RevokeInvite отзывает непринятое приглашение группы masterID, отзывает владелец userID.
+func (s *Discipline) RevokeInvite(ctx context.Context, masterID, userID, inviteID uint) error {
+	if err := s.checkOwner(ctx, masterID, userID); err != nil {
+		return err
+	}
+	if err := s.store.DeleteMasterInvite(ctx, masterID, inviteID); err != nil {
+		return notFound(err, "failed revoke master invite")
+	}
+	return nil
+}
+
+// SetMemberRole меняет роль участника memberID группы masterID, меняет владелец userID.
+func (s *Discipline) SetMemberRole(ctx context.Context, masterID, userID, memberID uint, role string) error {
+	if err := s.checkOwner(ctx, masterID, userID); err != nil {
+		return err
+	}
+	memberRole, err := validateMemberRole(role)
+	if err != nil {
+		return err
+	}
+	member, err := s.store.GetMasterMember(ctx, masterID, memberID)
+	if err != nil {
+		return notFound(err, "failed get master member")
+	}
+	if member.Role == models.MemberOwner {
+		return errOwnerRoleImmutable
+	}
+	if err := s.store.SetMemberRole(ctx, masterID, memberID, memberRole); err != nil {
+		return notFound(err, "failed set member role")
+	}
+	return nil
+}
+
+// RemoveMember удаляет участника memberID из группы masterID. Владелец userID удаляет
+// любого участника, остальные - только себя. Владельца удалить нельзя.
+func (s *Discipline) RemoveMember(ctx context.Context, masterID, userID, memberID uint) error {
+	current, err := s.masterMember(ctx, masterID, userID)
+	if err != nil {
+		return err
+	}
+	if userID != memberID && current.Role != models.MemberOwner {
+		return apperr.ErrForbidden
+	}
+	member, err := s.store.GetMasterMember(ctx, masterID, memberID)
+	if err != nil {
+		return notFound(err, "failed get master member")
+	}
+	if member.Role == models.MemberOwner {
+		return apperr.ErrValidation.WithField("user", "Владельца нельзя удалить из группы")
+	}
+	if err := s.store.DeleteMasterMember(ctx, masterID, memberID); err != nil {
+		return notFound(err, "failed remove master member")
+	}
+	return nil
+}
+
+// GetInvite возвращает действующее приглашение по коду вместе с логином владельца группы.
+func (s *Discipline) GetInvite(ctx context.Context, code string) (*types.TMasterInvite, error) {
+	invite, err := s.store.GetMasterInvite(ctx, code, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrInviteNotFound.Wrap(err)
+		}
+		return nil, fmt.Errorf("failed get master invite: %w", err)
+	}
+	master, err := s.store.GetMasterByID(ctx, invite.UserMasterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get master: %w", err)
+	}
+	owner, err := s.store.GetUserByID(ctx, master.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get master owner: %w", err)
+	}
+
+	result := inviteToView(invite)
+	result.Master = owner.Login
+	return &result, nil
+}
+
+// AcceptInvite добавляет пользователя userID в группу по приглашению и делает ее активной.
+// Участник группы повторно вступить в нее не может.
+func (s *Discipline) AcceptInvite(ctx context.Context, code string, userID uint) error {
+	if _, err := s.store.GetUserByID(ctx, userID); err != nil {
+		return notFound(err, "failed get user")
+	}
+
+	member, err := s.store.AcceptMasterInvite(ctx, code, userID, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return apperr.ErrInviteNotFound.Wrap(err)
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return apperr.ErrAlreadyMember.Wrap(err)
+		}
+		return fmt.Errorf("failed accept master invite: %w", err)
+	}
+	if err := s.store.SetUserActiveMaster(ctx, userID, &member.UserMasterID); err != nil {
+		return fmt.Errorf("failed set active master: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	penaltyCheckInterval = time.Minute * 5
+)
+
+func (s *Discipline) workerQuestPenalty(ctx context.Context) {
+	ticker := time.NewTicker(penaltyCheckInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.chargeMissedPenalties(ctx, time.Now()); err != nil {
+				s.log.Error("failed charge missed quest penalties", zap.Error(err))
+			}
+		}
+	}
+}
+
+// chargeMissedPenalties списывает штраф за последний завершившийся период квеста,
+// который игрок не выполнил (см. periodMissed). Более ранние периоды не проверяются,
+// поэтому пропуски за время простоя сервера штрафом не облагаются.
+func (s *Discipline) chargeMissedPenalties(ctx context.Context, now time.Time) error {
+	quests, err := s.store.GetPenaltyQuests(ctx)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed get penalty quests: %w", err)
+	}
+
+	for _, quest := range *quests {
+		if quest.UserMasterID == 0 {
+			continue
+		}
+		players := quest.Players
+		if len(players) == 0 {
+			players = quest.UserMaster.Players
+		}
+		for _, player := range players {
+			err := s.chargeMissedPenalty(ctx, &quest, player.ID, now)
+			if err != nil {
+				s.log.Error("failed charge missed penalty", zap.Error(err),
+					zap.Uint("quest_id", quest.ID), zap.Uint("player_id", player.ID))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Discipline) chargeMissedPenalty(ctx context.Context, quest *models.Quest, playerID uint, now time.Time) error {
+	loc := s.playerLocation(ctx, playerID)
+	period := previousPeriod(quest, streakPeriodAt(quest, now, loc))
+	start, end, ok := periodBounds(quest, period, loc)
+	if !ok {
+		return nil
+	}
+	// штрафуем только за периоды, целиком попавшие во время действия квеста
+	if start.Before(quest.CreatedAt) ||
+		(quest.StartTime != nil && start.Before(*quest.StartTime)) ||
+		(quest.EndTime != nil && end.After(*quest.EndTime)) {
+		return nil
+	}
+	// и только за периоды, начавшиеся после вступления игрока в группу
+	joined, err := s.store.GetMasterPlayerJoinedAt(ctx, quest.UserMasterID, playerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed get player joined time: %w", err)
+	}
+	if joined != nil && start.Before(*joined) {
+		return nil
+	}
+
+	pattern := period
+	if quest.Type == models.TimesPerWeek {
+		pattern = period + "#%"
+	}
+	statuses, err := s.store.FindQuestStatuses(ctx, quest.ID, playerID, pattern)
+	if err != nil {
+		return fmt.Errorf("failed find quest statuses: %w", err)
+	}
+	if !periodMissed(quest, *statuses) {
+		return nil
+	}
+
+	charged, err := s.store.ChargePenalty(ctx, &models.QuestPenalty{
+		QuestID:      quest.ID,
+		PlayerID:     playerID,
+		UserMasterID: quest.UserMasterID,
+		PeriodKey:    period,
+		Reason:       models.PenaltyMissed,
+		Amount:       quest.Penalty,
+	}, fmt.Sprintf("%s: пропущен период %s", quest.Title, period))
+	if err != nil {
+		return fmt.Errorf("failed charge penalty: %w", err)
+	}
+	if charged {
+		s.log.Info("missed quest penalty charged", zap.Uint("quest_id", quest.ID),
+			zap.Uint("player_id", playerID), zap.String("period", period))
+	}
+	return nil
+}
+
+// periodMissed - игрок не выполнил квест за период с отправками statuses. Квест
+// "несколько раз в неделю" выполнен, когда подтверждено нужное число отправок; пока
+// ожидающие проверки могут его добрать, штраф не списывается и проверяется снова при
+// следующем запуске. За остальные квесты штрафуем, только если за период ничего
+// не отправлено: отклоненную отправку уже оштрафовал мастер.
+func periodMissed(quest *models.Quest, statuses []models.QuestPlayerStatus) bool {
+	if quest.Type != models.TimesPerWeek {
+		return len(statuses) == 0
+	}
+	confirmed, awaiting := 0, 0
+	for _, status := range statuses {
+		switch {
+		case status.ConfirmationDate != nil:
+			confirmed++
+		case !isRejected(&status):
+			awaiting++
+		}
+	}
+	return confirmed+awaiting < requiredPerPeriod(quest)
+}
+
+// rejectPenalty - штраф за отклоненное мастером выполнение квеста, nil у квеста без штрафа.
+func rejectPenalty(status *models.QuestPlayerStatus) *models.QuestPenalty {
+	if status.Quest.Penalty == 0 {
+		return nil
+	}
+	statusID := status.ID
+	return &models.QuestPenalty{
+		QuestID:             status.QuestID,
+		PlayerID:            status.PlayerID,
+		UserMasterID:        status.Quest.UserMasterID,
+		PeriodKey:           status.PeriodKey,
+		Reason:              models.PenaltyRejected,
+		Amount:              status.Quest.Penalty,
+		QuestPlayerStatusID: &statusID,
+	}
+}
@@ -0,0 +1,264 @@
+package discipline_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/storage/database"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/core/discipline"
+	"github.com/mod-develop/backend/internal/core/discipline/disciplinetest"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func TestChargeMissedPenalties(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store := disciplinetest.NewStore()
+	d, err := discipline.New(ctx, store)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	owner, err := store.NewUser(ctx, "owner", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	master, err := d.ManageCreateSelfMaster(ctx, owner.ID, "")
+	if err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+
+	// квест создан задолго до вступления игрока, второй квест начинается позже
+	base := time.Now().UTC()
+	daily, err := store.NewQuest(ctx, &models.Quest{
+		Model: gorm.Model{CreatedAt: base.AddDate(0, 0, -10)}, Title: "Зарядка", Type: models.Daily,
+		UserMasterID: master.ID, UserID: owner.ID, Penalty: 5, IsActive: true,
+	})
+	if err != nil {
+		t.Fatalf("NewQuest() error = %v", err)
+	}
+	start := base.AddDate(0, 0, 3)
+	if _, err := store.NewQuest(ctx, &models.Quest{
+		Model: gorm.Model{CreatedAt: base.AddDate(0, 0, -10)}, Title: "Чтение", Type: models.Daily,
+		UserMasterID: master.ID, UserID: owner.ID, Penalty: 7, StartTime: &start, IsActive: true,
+	}); err != nil {
+		t.Fatalf("NewQuest() error = %v", err)
+	}
+	player, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := d.AddMaster(ctx, master.UniqueCode, player.ID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+
+	steps := []struct {
+		name  string
+		days  int  // сдвиг момента проверки от base, штрафуется предыдущий день
+		sent  bool // игрок отправил выполнение за проверяемый день
+		score int
+	}{
+		{name: "before player joined", days: 0, score: 0},
+		{name: "missed day", days: 2, score: -5},
+		{name: "worker rerun", days: 2, score: -5},
+		{name: "sent in period", days: 3, sent: true, score: -5},
+		{name: "period before quest start", days: 4, score: -10},
+		{name: "both quests missed", days: 5, score: -22},
+	}
+	for _, step := range steps {
+		now := base.AddDate(0, 0, step.days)
+		if step.sent {
+			period := now.AddDate(0, 0, -1).Format("2006-01-02")
+			if _, err := store.SendPlayerQuest(ctx, daily.ID, player.ID, period, "", nil); err != nil {
+				t.Fatalf("%s: SendPlayerQuest() error = %v", step.name, err)
+			}
+		}
+		if err := d.ChargeMissedPenalties(ctx, now); err != nil {
+			t.Fatalf("%s: ChargeMissedPenalties() error = %v", step.name, err)
+		}
+		wallets, err := d.GetMasterWallets(ctx, master.ID)
+		if err != nil {
+			t.Fatalf("%s: GetMasterWallets() error = %v", step.name, err)
+		}
+		score := 0
+		for _, wallet := range *wallets {
+			score += wallet.Score
+		}
+		if score != step.score {
+			t.Errorf("%s: wallet score = %d, want %d", step.name, score, step.score)
+		}
+	}
+}
+
+func TestMissedPenaltyTimesPerWeek(t *testing.T) {
+	base := time.Now().UTC()
+	year, week := base.AddDate(0, 0, -7).ISOWeek()
+	lastWeek := fmt.Sprintf("%04d-W%02d", year, week)
+
+	tests := []struct {
+		name      string
+		confirmed int // подтвержденные отправки за прошлую неделю
+		awaiting  int // ожидающие проверки
+		rejected  int
+		score     int
+	}{
+		{name: "nothing sent", score: -5},
+		{name: "partial", confirmed: 1, score: -5},
+		{name: "partial with rejected", confirmed: 2, rejected: 1, score: -5},
+		{name: "all confirmed", confirmed: 3, score: 0},
+		{name: "extra confirmed", confirmed: 4, score: 0},
+		{name: "awaiting review can complete", confirmed: 1, awaiting: 2, score: 0},
+		{name: "awaiting review cannot complete", confirmed: 1, awaiting: 1, score: -5},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		store := legacyMembers{disciplinetest.NewStore()}
+		d, err := discipline.New(ctx, store)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		owner, err := store.NewUser(ctx, "owner", "hash")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		master, err := d.ManageCreateSelfMaster(ctx, owner.ID, "")
+		if err != nil {
+			t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+		}
+		player, err := store.NewUser(ctx, "player", "hash")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		if err := d.AddMaster(ctx, master.UniqueCode, player.ID); err != nil {
+			t.Fatalf("AddMaster() error = %v", err)
+		}
+		quest, err := store.NewQuest(ctx, &models.Quest{
+			Model: gorm.Model{CreatedAt: base.AddDate(0, 0, -30)}, Title: "Бег", Type: models.TimesPerWeek,
+			Schedule: models.QuestSchedule{Times: 3}, UserMasterID: master.ID, UserID: owner.ID, Penalty: 5, IsActive: true,
+		})
+		if err != nil {
+			t.Fatalf("NewQuest() error = %v", err)
+		}
+
+		sent := 0
+		send := func(count int, review func(status *models.QuestPlayerStatus)) {
+			for range count {
+				sent++
+				status, err := store.SendPlayerQuest(ctx, quest.ID, player.ID, fmt.Sprintf("%s#%d", lastWeek, sent), "", nil)
+				if err != nil {
+					t.Fatalf("%s: SendPlayerQuest() error = %v", tt.name, err)
+				}
+				if review == nil {
+					continue
+				}
+				review(status)
+				if err := store.UpdAwaitQuest(ctx, status, nil, ""); err != nil {
+					t.Fatalf("%s: UpdAwaitQuest() error = %v", tt.name, err)
+				}
+			}
+		}
+		reviewed := time.Now().UTC().Add(time.Second)
+		send(tt.confirmed, func(status *models.QuestPlayerStatus) { status.ConfirmationDate = &reviewed })
+		send(tt.rejected, func(status *models.QuestPlayerStatus) { status.RejectExecuteDate = &reviewed })
+		send(tt.awaiting, nil)
+
+		if err := d.ChargeMissedPenalties(ctx, base); err != nil {
+			t.Fatalf("%s: ChargeMissedPenalties() error = %v", tt.name, err)
+		}
+		wallets, err := d.GetMasterWallets(ctx, master.ID)
+		if err != nil {
+			t.Fatalf("%s: GetMasterWallets() error = %v", tt.name, err)
+		}
+		score := 0
+		for _, wallet := range *wallets {
+			score += wallet.Score
+		}
+		if score != tt.score {
+			t.Errorf("%s: wallet score = %d, want %d", tt.name, score, tt.score)
+		}
+	}
+}
+
+// legacyMembers - хранилище, где игроки вступили в группу до того, как стало известно время вступления.
+type legacyMembers struct {
+	discipline.Store
+}
+
+func (legacyMembers) GetMasterPlayerJoinedAt(ctx context.Context, masterID, playerID uint) (*time.Time, error) {
+	return nil, nil
+}
+
+// TestMissedPenaltyAfterQuestEdit проверяет, что правка квеста в базе не сдвигает
+// дату его создания и за дни до создания квеста штраф по-прежнему не списывается.
+func TestMissedPenaltyAfterQuestEdit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	store, err := database.New(ctx, "sqlite://:memory:")
+	if err != nil {
+		t.Fatalf("database.New() error = %v", err)
+	}
+	d, err := discipline.New(ctx, legacyMembers{store})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	owner, err := store.NewUser(ctx, "owner", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	master, err := d.ManageCreateSelfMaster(ctx, owner.ID, "")
+	if err != nil {
+		t.Fatalf("ManageCreateSelfMaster() error = %v", err)
+	}
+	player, err := store.NewUser(ctx, "player", "hash")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := d.AddMaster(ctx, master.UniqueCode, player.ID); err != nil {
+		t.Fatalf("AddMaster() error = %v", err)
+	}
+
+	form := &types.TQuest{
+		Title: "Зарядка", Penalty: 5, IsActive: true,
+		Types: []types.TQuestType{{Value: models.Daily, Selected: true}},
+	}
+	quest, err := d.NewQuest(ctx, form, master.ID, owner.ID)
+	if err != nil {
+		t.Fatalf("NewQuest() error = %v", err)
+	}
+	form.ID = quest.ID
+	form.Title = "Утренняя зарядка"
+	if _, err := d.EditQuest(ctx, form, master.ID); err != nil {
+		t.Fatalf("EditQuest() error = %v", err)
+	}
+
+	base := time.Now().UTC()
+	steps := []struct {
+		name  string
+		days  int
+		score int
+	}{
+		{name: "day before quest created", days: 0, score: 0},
+		{name: "missed day after edit", days: 2, score: -5},
+	}
+	for _, step := range steps {
+		if err := d.ChargeMissedPenalties(ctx, base.AddDate(0, 0, step.days)); err != nil {
+			t.Fatalf("%s: ChargeMissedPenalties() error = %v", step.name, err)
+		}
+		wallets, err := d.GetMasterWallets(ctx, master.ID)
+		if err != nil {
+			t.Fatalf("%s: GetMasterWallets() error = %v", step.name, err)
+		}
+		score := 0
+		for _, wallet := range *wallets {
+			score += wallet.Score
+		}
+		if score != step.score {
+			t.Errorf("%s: wallet score = %d, want %d", step.name, score, step.score)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	dailyPeriodFormat   = "2006-01-02"
+	monthlyPeriodFormat = "2006-01"
+
+	maxTimesPerWeek uint = 7
+
+	weekDayTitles = map[int]string{
+		0: "Вс", 1: "Пн", 2: "Вт", 3: "Ср", 4: "Чт", 5: "Пт", 6: "Сб",
+	}
+)
+
+// periodState - состояние выполнения квеста игроком в текущем периоде.
+type periodState struct {
+	Period    string
+	Status    *models.QuestPlayerStatus
+	Scheduled bool
+	Done      int
+	Total     int
+}
+
+// CanSend - игрок может отправить квест на проверку в текущем периоде.
+func (p *periodState) CanSend() bool {
+	return p.Scheduled && (p.Status == nil || isRejected(p.Status))
+}
+
+func isRejected(status *models.QuestPlayerStatus) bool {
+	if status.ConfirmationDate != nil || status.RejectExecuteDate == nil {
+		return false
+	}
+	return status.RequestExecuteDate == nil || status.RejectExecuteDate.After(*status.RequestExecuteDate)
+}
+
+func weekPeriod(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// parseWeekPeriod возвращает начало понедельника недели, заданной ключом вида 2006-W01.
+func parseWeekPeriod(period string, loc *time.Location) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(period, "%04d-W%02d", &year, &week); err != nil {
+		return time.Time{}, fmt.Errorf("invalid week period `%s`: %w", period, err)
+	}
+	// 4 января всегда попадает в первую неделю года по ISO 8601
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7), nil
+}
+
+// parseDays разбирает список чисел через запятую, проверяя что каждое лежит в [from, to].
+// Результат отсортирован и не содержит повторов.
+func parseDays(value string, from, to int) ([]int, error) {
+	days := []int{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		day, err := strconv.Atoi(part)
+		if err != nil || day < from || day > to {
+			return nil, fmt.Errorf("invalid day `%s`: %w", part, apperr.ErrInvalidSchedule)
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	slices.Sort(days)
+	return days, nil
+}
+
+func formatDays(days []int) string {
+	parts := make([]string, 0, len(days))
+	for _, d := range days {
+		parts = append(parts, strconv.Itoa(d))
+	}
+	return strings.Join(parts, ",")
+}
+
+func hasDay(value string, day int) bool {
+	days, err := parseDays(value, 0, 31)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(days, day)
+}
+
+// questPeriods возвращает ключи периодов, в которых игрок может выполнить квест в момент now.
+// Пустой результат означает, что по расписанию квест сейчас недоступен.
+// Для разовых квестов период один на все время и его ключ пустой.
+func questPeriods(quest *models.Quest, now time.Time, loc *time.Location) []string {
+	local := now.In(loc)
+	day := local.Format(dailyPeriodFormat)
+	switch quest.Type {
+	case models.Daily:
+		return []string{day}
+	case models.Weekly:
+		return []string{weekPeriod(local)}
+	case models.WeekDays:
+		if hasDay(quest.Schedule.WeekDays, int(local.Weekday())) {
+			return []string{day}
+		}
+		return nil
+	case models.Monthly:
+		if quest.Schedule.MonthDays == "" {
+			return []string{local.Format(monthlyPeriodFormat)}
+		}
+		if hasDay(quest.Schedule.MonthDays, local.Day()) {
+			return []string{day}
+		}
+		return nil
+	case models.TimesPerWeek:
+		week := weekPeriod(local)
+		times := max(quest.Schedule.Times, 1)
+		periods := make([]string, 0, times)
+		for i := uint(1); i <= times; i++ {
+			periods = append(periods, fmt.Sprintf("%s#%d", week, i))
+		}
+		return periods
+	default:
+		return []string{""}
+	}
+}
+
+// questState определяет текущий период квеста и состояние его выполнения игроком.
+// Для квестов с несколькими выполнениями за период выбирается первый свободный
+// или отклоненный слот, а если все заняты - последний из них.
+func (s *Discipline) questState(ctx context.Context, quest *models.Quest, playerID uint, now time.Time, loc *time.Location) (*periodState, error) {
+	periods := questPeriods(quest, now, loc)
+	state := &periodState{
+		Total: len(periods),
+	}
+	if len(periods) == 0 {
+		return state, nil
+	}
+	state.Scheduled = true
+
+	var current *periodState
+	for _, period := range periods {
+		status, err := s.store.GetPlayerQuestStatus(ctx, quest.ID, playerID, period)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed get quest player status: %w", err)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) || (status != nil && status.ID == 0) {
+			status = nil
+		}
+		if status != nil && !isRejected(status) {
+			state.Done++
+		}
+		if current == nil && (status == nil || isRejected(status)) {
+			current = &periodState{Period: period, Status: status}
+		}
+		if current == nil && period == periods[len(periods)-1] {
+			current = &periodState{Period: period, Status: status}
+		}
+	}
+	state.Period = current.Period
+	state.Status = current.Status
+
+	return state, nil
+}
+
+// playerLocation возвращает часовой пояс игрока, при ошибке - UTC.
+func (s *Discipline) playerLocation(ctx context.Context, playerID uint) *time.Location {
+	user, err := s.store.GetUserByID(ctx, playerID)
+	if err != nil {
+		s.log.Error("failed get player for time zone", zap.Error(err), zap.Uint("player_id", playerID))
+		return time.UTC
+	}
+	if user.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		s.log.Error("failed load player time zone", zap.Error(err), zap.String("time_zone", user.TimeZone))
+		return time.UTC
+	}
+	return loc
+}
+
+// scheduleFromView собирает расписание квеста из формы, проверяя его полноту для типа квеста.
+func scheduleFromView(quest *types.TQuest, questType models.QuestType) (models.QuestSchedule, error) {
+	schedule := models.QuestSchedule{}
+	switch questType {
+	case models.WeekDays:
+		days := []int{}
+		for _, d := range quest.WeekDays {
+			if d.Selected {
+				days = append(days, d.Value)
+			}
+		}
+		days, err := parseDays(formatDays(days), 0, 6)
+		if err != nil || len(days) == 0 {
+			return schedule, apperr.ErrInvalidSchedule.WithField("weekDays", "Выберите хотя бы один день недели").Wrap(err)
+		}
+		schedule.WeekDays = formatDays(days)
+	case models.Monthly:
+		days, err := parseDays(quest.MonthDays, 1, 31)
+		if err != nil {
+			return schedule, apperr.ErrInvalidSchedule.WithField("monthDays", "Укажите числа месяца от 1 до 31 через запятую").Wrap(err)
+		}
+		schedule.MonthDays = formatDays(days)
+	case models.TimesPerWeek:
+		if quest.Times == 0 || quest.Times > maxTimesPerWeek {
+			return schedule, apperr.ErrInvalidSchedule.
+				WithField("times", fmt.Sprintf("Укажите от 1 до %d раз в неделю", maxTimesPerWeek))
+		}
+		schedule.Times = quest.Times
+	}
+	return schedule, nil
+}
+
+// scheduleToView заполняет поля расписания в представлении квеста.
+func scheduleToView(quest *types.TQuest, schedule models.QuestSchedule) {
+	quest.WeekDays = types.NewWeekDays()
+	for i := range quest.WeekDays {
+		quest.WeekDays[i].Selected = hasDay(schedule.WeekDays, quest.WeekDays[i].Value)
+	}
+	quest.MonthDays = strings.ReplaceAll(schedule.MonthDays, ",", ", ")
+	quest.Times = schedule.Times
+}
+
+// scheduleTitle - описание расписания квеста для игрока.
+func scheduleTitle(quest *models.Quest) string {
+	switch quest.Type {
+	case models.Daily:
+		return "каждый день"
+	case models.Weekly:
+		return "раз в неделю"
+	case models.WeekDays:
+		days, _ := parseDays(quest.Schedule.WeekDays, 0, 6)
+		// неделя начинается с понедельника
+		slices.SortFunc(days, func(a, b int) int { return (a+6)%7 - (b+6)%7 })
+		titles := []string{}
+		for _, d := range days {
+			titles = append(titles, weekDayTitles[d])
+		}
+		return strings.Join(titles, ", ")
+	case models.Monthly:
+		if quest.Schedule.MonthDays == "" {
+			return "раз в месяц"
+		}
+		return strings.ReplaceAll(quest.Schedule.MonthDays, ",", ", ") + " числа"
+	case models.TimesPerWeek:
+		return fmt.Sprintf("%d раз(а) в неделю", quest.Schedule.Times)
+	default:
+		return ""
+	}
+}
+
+// streakPeriod - ключ периода, по которому считается серия. Для квестов
+// "несколько раз в неделю" это неделя без номера выполнения.
+func streakPeriod(period string) string {
+	base, _, _ := strings.Cut(period, "#")
+	return base
+}
+
+// streakPeriodAt - ключ периода серии, в котором находится момент now.
+// Для квестов по дням недели и числам месяца это текущий день, даже если он не по расписанию.
+func streakPeriodAt(quest *models.Quest, now time.Time, loc *time.Location) string {
+	local := now.In(loc)
+	switch quest.Type {
+	case models.Weekly, models.TimesPerWeek:
+		return weekPeriod(local)
+	case models.Monthly:
+		if quest.Schedule.MonthDays == "" {
+			return local.Format(monthlyPeriodFormat)
+		}
+	}
+	return local.Format(dailyPeriodFormat)
+}
+
+// previousPeriod возвращает ключ предыдущего по расписанию периода серии квеста.
+func previousPeriod(quest *models.Quest, period string) string {
+	switch quest.Type {
+	case models.Weekly, models.TimesPerWeek:
+		monday, err := parseWeekPeriod(period, time.UTC)
+		if err != nil {
+			return ""
+		}
+		return weekPeriod(monday.AddDate(0, 0, -7))
+	case models.Monthly:
+		if quest.Schedule.MonthDays == "" {
+			month, err := time.Parse(monthlyPeriodFormat, period)
+			if err != nil {
+				return ""
+			}
+			return month.AddDate(0, -1, 0).Format(monthlyPeriodFormat)
+		}
+	}
+
+	day, err := time.Parse(dailyPeriodFormat, period)
+	if err != nil {
+		return ""
+	}
+	switch quest.Type {
+	case models.WeekDays:
+		for i := 1; i <= 7; i++ {
+			prev := day.AddDate(0, 0, -i)
+			if hasDay(quest.Schedule.WeekDays, int(prev.Weekday())) {
+				return prev.Format(dailyPeriodFormat)
+			}
+		}
+		return ""
+	case models.Monthly:
+		// число может встречаться не в каждом месяце, например 31-е
+		for i := 1; i <= 366; i++ {
+			prev := day.AddDate(0, 0, -i)
+			if hasDay(quest.Schedule.MonthDays, prev.Day()) {
+				return prev.Format(dailyPeriodFormat)
+			}
+		}
+		return ""
+	default:
+		return day.AddDate(0, 0, -1).Format(dailyPeriodFormat)
+	}
+}
+
+// periodBounds возвращает начало и конец периода серии квеста в часовом поясе игрока.
+func periodBounds(quest *models.Quest, period string, loc *time.Location) (time.Time, time.Time, bool) {
+	switch quest.Type {
+	case models.Weekly, models.TimesPerWeek:
+		monday, err := parseWeekPeriod(period, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		return monday, monday.AddDate(0, 0, 7), true
+	case models.Monthly:
+		if quest.Schedule.MonthDays == "" {
+			month, err := time.ParseInLocation(monthlyPeriodFormat, period, loc)
+			if err != nil {
+				return time.Time{}, time.Time{}, false
+			}
+			return month, month.AddDate(0, 1, 0), true
+		}
+	}
+	day, err := time.ParseInLocation(dailyPeriodFormat, period, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return day, day.AddDate(0, 0, 1), true
+}
@@ -0,0 +1,219 @@
+package discipline
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	tzMoscow   = time.FixedZone("UTC+3", 3*60*60)
+	tzNewYork  = time.FixedZone("UTC-5", -5*60*60)
+	monthEnd   = models.QuestSchedule{MonthDays: "29,30,31"}
+	mondayWeds = models.QuestSchedule{WeekDays: "1,3"}
+)
+
+func TestQuestPeriods(t *testing.T) {
+	tests := []struct {
+		name     string
+		quest    models.Quest
+		now      time.Time
+		loc      *time.Location
+		expected []string
+	}{
+		{name: "one time", quest: models.Quest{Type: models.OneTime},
+			now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{""}},
+		{name: "daily", quest: models.Quest{Type: models.Daily},
+			now: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2024-02-29"}},
+		{name: "daily ahead of utc after midnight", quest: models.Quest{Type: models.Daily},
+			now: time.Date(2023, 12, 31, 22, 30, 0, 0, time.UTC), loc: tzMoscow, expected: []string{"2024-01-01"}},
+		{name: "daily behind utc before midnight", quest: models.Quest{Type: models.Daily},
+			now: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), loc: tzNewYork, expected: []string{"2023-12-31"}},
+		{name: "weekly in iso week 53", quest: models.Quest{Type: models.Weekly},
+			now: time.Date(2021, 1, 3, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2020-W53"}},
+		{name: "weekly first iso week in previous year", quest: models.Quest{Type: models.Weekly},
+			now: time.Date(2024, 12, 30, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2025-W01"}},
+		{name: "weekly week changes in player zone", quest: models.Quest{Type: models.Weekly},
+			now: time.Date(2023, 12, 31, 22, 30, 0, 0, time.UTC), loc: tzMoscow, expected: []string{"2024-W01"}},
+		{name: "week days scheduled", quest: models.Quest{Type: models.WeekDays, Schedule: mondayWeds},
+			now: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2024-01-03"}},
+		{name: "week days not scheduled", quest: models.Quest{Type: models.WeekDays, Schedule: mondayWeds},
+			now: time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: nil},
+		{name: "week days scheduled in player zone only", quest: models.Quest{Type: models.WeekDays, Schedule: mondayWeds},
+			now: time.Date(2024, 1, 7, 22, 0, 0, 0, time.UTC), loc: tzMoscow, expected: []string{"2024-01-08"}},
+		{name: "monthly", quest: models.Quest{Type: models.Monthly},
+			now: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2024-02"}},
+		{name: "month end in leap february", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd},
+			now: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2024-02-29"}},
+		{name: "month end missing in february", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd},
+			now: time.Date(2023, 2, 28, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: nil},
+		{name: "month end 31st", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd},
+			now: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2024-01-31"}},
+		{name: "times per week", quest: models.Quest{Type: models.TimesPerWeek, Schedule: models.QuestSchedule{Times: 3}},
+			now: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), loc: time.UTC,
+			expected: []string{"2024-W01#1", "2024-W01#2", "2024-W01#3"}},
+		{name: "times per week without times", quest: models.Quest{Type: models.TimesPerWeek},
+			now: time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC), loc: time.UTC, expected: []string{"2020-W53#1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := questPeriods(&tt.quest, tt.now, tt.loc); !slices.Equal(got, tt.expected) {
+				t.Errorf("questPeriods() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		from, to int
+		expected []int
+		wantErr  bool
+	}{
+		{name: "empty", value: "", from: 1, to: 31, expected: []int{}},
+		{name: "sorted without repeats", value: "31, 1,29,,1", from: 1, to: 31, expected: []int{1, 29, 31}},
+		{name: "sunday is zero", value: "0,6", from: 0, to: 6, expected: []int{0, 6}},
+		{name: "below range", value: "0", from: 1, to: 31, wantErr: true},
+		{name: "above range", value: "32", from: 1, to: 31, wantErr: true},
+		{name: "not a number", value: "1,x", from: 1, to: 31, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDays(tt.value, tt.from, tt.to)
+			if tt.wantErr {
+				if !errors.Is(err, apperr.ErrInvalidSchedule) {
+					t.Errorf("parseDays() error = %v, want apperr.ErrInvalidSchedule", err)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tt.expected) {
+				t.Errorf("parseDays() = %v, %v, want %v", got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseWeekPeriod(t *testing.T) {
+	tests := []struct {
+		period   string
+		loc      *time.Location
+		expected time.Time
+		wantErr  bool
+	}{
+		{period: "2024-W01", loc: time.UTC, expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{period: "2020-W53", loc: time.UTC, expected: time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC)},
+		{period: "2025-W01", loc: time.UTC, expected: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{period: "2021-W01", loc: tzMoscow, expected: time.Date(2021, 1, 4, 0, 0, 0, 0, tzMoscow)},
+		{period: "2024-01-01", loc: time.UTC, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			got, err := parseWeekPeriod(tt.period, tt.loc)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseWeekPeriod() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil || !got.Equal(tt.expected) || got.Location() != tt.loc {
+				t.Errorf("parseWeekPeriod() = %v, %v, want %v", got, err, tt.expected)
+			}
+			if weekPeriod(got) != tt.period {
+				t.Errorf("weekPeriod(%v) = %s, want %s", got, weekPeriod(got), tt.period)
+			}
+		})
+	}
+}
+
+func TestPreviousPeriod(t *testing.T) {
+	tests := []struct {
+		name     string
+		quest    models.Quest
+		period   string
+		expected string
+	}{
+		{name: "daily year boundary", quest: models.Quest{Type: models.Daily}, period: "2024-01-01", expected: "2023-12-31"},
+		{name: "daily leap day", quest: models.Quest{Type: models.Daily}, period: "2024-03-01", expected: "2024-02-29"},
+		{name: "weekly after week 53", quest: models.Quest{Type: models.Weekly}, period: "2021-W01", expected: "2020-W53"},
+		{name: "weekly year boundary", quest: models.Quest{Type: models.Weekly}, period: "2020-W01", expected: "2019-W52"},
+		{name: "times per week", quest: models.Quest{Type: models.TimesPerWeek}, period: "2025-W01", expected: "2024-W52"},
+		{name: "monthly year boundary", quest: models.Quest{Type: models.Monthly}, period: "2024-01", expected: "2023-12"},
+		{name: "31st skips short months", quest: models.Quest{Type: models.Monthly, Schedule: models.QuestSchedule{MonthDays: "31"}},
+			period: "2024-03-31", expected: "2024-01-31"},
+		{name: "month end without 29 february", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd},
+			period: "2023-03-29", expected: "2023-01-31"},
+		{name: "month end with 29 february", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd},
+			period: "2024-03-29", expected: "2024-02-29"},
+		{name: "week days", quest: models.Quest{Type: models.WeekDays, Schedule: mondayWeds},
+			period: "2024-01-08", expected: "2024-01-03"},
+		{name: "week days year boundary", quest: models.Quest{Type: models.WeekDays, Schedule: mondayWeds},
+			period: "2024-01-01", expected: "2023-12-27"},
+		{name: "invalid daily", quest: models.Quest{Type: models.Daily}, period: "2024-W01", expected: ""},
+		{name: "invalid weekly", quest: models.Quest{Type: models.Weekly}, period: "2024-01-01", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previousPeriod(&tt.quest, tt.period); got != tt.expected {
+				t.Errorf("previousPeriod(%s) = %q, want %q", tt.period, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPeriodBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		quest      models.Quest
+		period     string
+		loc        *time.Location
+		start, end time.Time
+		wantOK     bool
+	}{
+		{name: "daily in player zone", quest: models.Quest{Type: models.Daily}, period: "2024-01-01", loc: tzMoscow,
+			start: time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC), end: time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "week 53", quest: models.Quest{Type: models.Weekly}, period: "2020-W53", loc: time.UTC,
+			start: time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), end: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "times per week", quest: models.Quest{Type: models.TimesPerWeek}, period: "2025-W01", loc: tzNewYork,
+			start: time.Date(2024, 12, 30, 5, 0, 0, 0, time.UTC), end: time.Date(2025, 1, 6, 5, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "leap february", quest: models.Quest{Type: models.Monthly}, period: "2024-02", loc: time.UTC,
+			start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "month day", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd}, period: "2024-01-31", loc: time.UTC,
+			start: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), end: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "invalid day", quest: models.Quest{Type: models.Daily}, period: "2023-02-29", loc: time.UTC},
+		{name: "month for month days", quest: models.Quest{Type: models.Monthly, Schedule: monthEnd}, period: "2024-01", loc: time.UTC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := periodBounds(&tt.quest, tt.period, tt.loc)
+			if ok != tt.wantOK {
+				t.Fatalf("periodBounds(%s) ok = %t, want %t", tt.period, ok, tt.wantOK)
+			}
+			if ok && (!start.Equal(tt.start) || !end.Equal(tt.end)) {
+				t.Errorf("periodBounds(%s) = [%v, %v), want [%v, %v)", tt.period, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestStreakPeriod(t *testing.T) {
+	tests := []struct {
+		period   string
+		expected string
+	}{
+		{period: "2024-W01#1", expected: "2024-W01"},
+		{period: "2020-W53#7", expected: "2020-W53"},
+		{period: "2024-01-01", expected: "2024-01-01"},
+		{period: "", expected: ""},
+	}
+	for _, tt := range tests {
+		if got := streakPeriod(tt.period); got != tt.expected {
+			t.Errorf("streakPeriod(%s) = %s, want %s", tt.period, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package discipline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+	"github.com/mod-develop/backend/pkg/tools"
+)
+
+var (
+	maxProofFiles                = 5
+	maxProofFileSize       int64 = 10 << 20
+	maxProofCommentLength        = 2000
+	lengthProofBlobKeyRand uint  = 16
+
+	// тип файла определяется по содержимому, а не по имени или заголовку запроса
+	proofContentTypes = map[string]bool{
+		"image/jpeg":      true,
+		"image/png":       true,
+		"image/gif":       true,
+		"image/webp":      true,
+		"application/pdf": true,
+		"video/mp4":       true,
+	}
+)
+
+func attachmentToView(a *models.ProofAttachment) types.TProofAttachment {
+	return types.TProofAttachment{
+		ID:          a.ID,
+		Name:        a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		IsImage:     strings.HasPrefix(a.ContentType, "image/"),
+	}
+}
+
+func attachmentsToView(attachments []models.ProofAttachment) []types.TProofAttachment {
+	result := []types.TProofAttachment{}
+	for _, a := range attachments {
+		result = append(result, attachmentToView(&a))
+	}
+	return result
+}
+
+// validateProof проверяет ограничения на комментарий и количество файлов до их загрузки.
+func validateProof(proof *types.TProof) error {
+	if utf8.RuneCountInString(proof.Comment) > maxProofCommentLength {
+		return apperr.ErrProofCommentLength.
+			WithField("comment", fmt.Sprintf("Комментарий должен быть не длиннее %d символов", maxProofCommentLength))
+	}
+	if len(proof.Files) > maxProofFiles {
+		return apperr.ErrProofTooManyFiles
+	}
+	for _, f := range proof.Files {
+		if f.Size > maxProofFileSize {
+			return fmt.Errorf("file `%s`: %w", f.Name, apperr.ErrProofFileTooLarge)
+		}
+	}
+	return nil
+}
+
+// storeProofFiles сохраняет файлы подтверждения в хранилище файлов.
+// При ошибке уже сохраненные файлы удаляются.
+func (s *Discipline) storeProofFiles(ctx context.Context, questID, playerID uint, files []types.TProofFile) ([]models.ProofAttachment, error) {
+	attachments := []models.ProofAttachment{}
+	if len(files) == 0 {
+		return attachments, nil
+	}
+	if s.blob == nil {
+		return nil, errors.New("blob store is not configured")
+	}
+
+	for _, f := range files {
+		attachment, err := s.storeProofFile(ctx, questID, playerID, f)
+		if err != nil {
+			s.deleteProofFiles(ctx, attachments)
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	return attachments, nil
+}
+
+func (s *Discipline) storeProofFile(ctx context.Context, questID, playerID uint, file types.TProofFile) (*models.ProofAttachment, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed read proof file: %w", err)
+	}
+	head = head[:n]
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !proofContentTypes[contentType] {
+		return nil, fmt.Errorf("file `%s` of type `%s`: %w", file.Name, contentType, apperr.ErrProofFileType)
+	}
+
+	key := fmt.Sprintf("proofs/%d/%d/%s%s", questID, playerID,
+		tools.RandomString(lengthProofBlobKeyRand), strings.ToLower(filepath.Ext(file.Name)))
+	counter := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), file.Content), maxProofFileSize+1)}
+	err = s.blob.Put(ctx, key, counter)
+	if err != nil {
+		return nil, fmt.Errorf("failed put proof file: %w", err)
+	}
+	if counter.n > maxProofFileSize {
+		s.deleteProofFiles(ctx, []models.ProofAttachment{{BlobKey: key}})
+		return nil, fmt.Errorf("file `%s`: %w", file.Name, apperr.ErrProofFileTooLarge)
+	}
+
+	return &models.ProofAttachment{
+		FileName:    filepath.Base(file.Name),
+		ContentType: contentType,
+		Size:        counter.n,
+		BlobKey:     key,
+	}, nil
+}
+
+// deleteProofFiles удаляет файлы вложений из хранилища, ошибки только логируются.
+func (s *Discipline) deleteProofFiles(ctx context.Context, attachments []models.ProofAttachment) {
+	if s.blob == nil {
+		return
+	}
+	for _, a := range attachments {
+		if err := s.blob.Delete(ctx, a.BlobKey); err != nil {
+			s.log.Error("failed delete proof file", zap.Error(err), zap.String("key", a.BlobKey))
+		}
+	}
+}
+
+// OpenProofAttachment открывает файл подтверждения. Файл доступен игроку,
+// который его загрузил, и мастеру квеста.
+func (s *Discipline) OpenProofAttachment(ctx context.Context, attachmentID, userID uint) (*types.TProofAttachment, io.ReadCloser, error) {
+	attachment, err := s.store.GetProofAttachment(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.Join(err, apperr.ErrDataNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed get proof attachment: %w", err)
+	}
+	if err := s.checkSeeStatus(ctx, &attachment.QuestPlayerStatus, userID); err != nil {
+		return nil, nil, err
+	}
+	if s.blob == nil {
+		return nil, nil, errors.New("blob store is not configured")
+	}
+
+	content, err := s.blob.Open(ctx, attachment.BlobKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed open proof file: %w", err)
+	}
+	view := attachmentToView(attachment)
+	return &view, content, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,196 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func rewardToView(r *models.Reward) types.TReward {
+	reward := types.TReward{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Cost:        r.Cost,
+		IsActive:    r.IsActive,
+		IsUnlimited: r.Stock == nil,
+	}
+	if r.Stock != nil {
+		reward.Stock = *r.Stock
+	}
+	return reward
+}
+
+func purchaseToView(p *models.RewardPurchase) types.TRewardPurchase {
+	return types.TRewardPurchase{
+		ID:         p.ID,
+		Title:      p.Reward.Title,
+		PlayerName: p.Player.Login,
+		Cost:       p.Cost,
+		Status:     p.Status,
+		Date:       p.CreatedAt.UTC().Format(defaultViewDateTimeFormat),
+	}
+}
+
+func (s *Discipline) NewReward(ctx context.Context, reward *types.TReward, masterID uint) (*models.Reward, error) {
+	if err := checkMaster(masterID); err != nil {
+		return nil, err
+	}
+	model, err := rewardFromView(reward, masterID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.store.NewReward(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed create reward: %w", err)
+	}
+	return r, nil
+}
+
+func (s *Discipline) GetReward(ctx context.Context, rewardID, masterID uint) (*types.TReward, error) {
+	r, err := s.masterReward(ctx, rewardID, masterID)
+	if err != nil {
+		return nil, err
+	}
+
+	reward := rewardToView(r)
+	return &reward, nil
+}
+
+func (s *Discipline) GetRewards(ctx context.Context, masterID uint) (*[]types.TReward, error) {
+	result := []types.TReward{}
+	rewards, err := s.store.GetRewards(ctx, masterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &result, nil
+		}
+		return nil, fmt.Errorf("failed get rewards: %w", err)
+	}
+	for _, r := range *rewards {
+		result = append(result, rewardToView(&r))
+	}
+	return &result, nil
+}
+
+func (s *Discipline) EditReward(ctx context.Context, reward *types.TReward, masterID uint) (*types.TReward, error) {
+	if _, err := s.masterReward(ctx, reward.ID, masterID); err != nil {
+		return nil, err
+	}
+
+	model, err := rewardFromView(reward, masterID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.store.UpdReward(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed update reward: %w", err)
+	}
+
+	result := rewardToView(r)
+	return &result, nil
+}
+
+func (s *Discipline) GetPlayerRewards(ctx context.Context, playerID uint) (*[]types.TPlayerReward, error) {
+	result := []types.TPlayerReward{}
+	rewards, err := s.store.GetPlayerRewards(ctx, playerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &result, nil
+		}
+		return nil, fmt.Errorf("failed get player rewards: %w", err)
+	}
+
+	wallets, err := s.store.GetWallets(ctx, playerID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed get wallets: %w", err)
+	}
+	scores := map[uint]int{}
+	if wallets != nil {
+		for _, w := range *wallets {
+			scores[w.UserMasterID] += w.Balance
+		}
+	}
+
+	for _, r := range *rewards {
+		reward := types.TPlayerReward{
+			ID:          r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Cost:        r.Cost,
+			IsUnlimited: r.Stock == nil,
+			Score:       scores[r.UserMasterID],
+		}
+		if r.Stock != nil {
+			reward.Stock = *r.Stock
+		}
+		reward.CanBuy = reward.Score >= int(r.Cost) && (reward.IsUnlimited || reward.Stock > 0)
+		result = append(result, reward)
+	}
+
+	return &result, nil
+}
+
+func (s *Discipline) BuyReward(ctx context.Context, rewardID, playerID uint) error {
+	_, err := s.store.BuyReward(ctx, rewardID, playerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Join(err, apperr.ErrDataNotFound)
+		}
+		return fmt.Errorf("failed buy reward: %w", err)
+	}
+	return nil
+}
+
+func (s *Discipline) GetPlayerPurchases(ctx context.Context, playerID uint) (*[]types.TRewardPurchase, error) {
+	result := []types.TRewardPurchase{}
+	purchases, err := s.store.GetPlayerPurchases(ctx, playerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &result, nil
+		}
+		return nil, fmt.Errorf("failed get player purchases: %w", err)
+	}
+	for _, p := range *purchases {
+		result = append(result, purchaseToView(&p))
+	}
+	return &result, nil
+}
+
+func (s *Discipline) GetRewardPurchases(ctx context.Context, masterID uint) (*[]types.TRewardPurchase, error) {
+	result := []types.TRewardPurchase{}
+	purchases, err := s.store.GetRewardPurchases(ctx, masterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &result, nil
+		}
+		return nil, fmt.Errorf("failed get reward purchases: %w", err)
+	}
+	for _, p := range *purchases {
+		result = append(result, purchaseToView(&p))
+	}
+	return &result, nil
+}
+
+func (s *Discipline) ManageRewardPurchase(ctx context.Context, purchaseID, masterID uint, fulfil bool) error {
+	purchase, err := s.masterPurchase(ctx, purchaseID, masterID)
+	if err != nil {
+		return err
+	}
+
+	if fulfil {
+		err = s.store.FulfilRewardPurchase(ctx, purchase)
+	} else {
+		err = s.store.RefundRewardPurchase(ctx, purchase)
+	}
+	if err != nil {
+		return fmt.Errorf("failed close reward purchase: %w", err)
+	}
+
+	return nil
+}
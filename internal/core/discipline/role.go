@@ -0,0 +1,114 @@
+package discipline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// usersPerPage - пользователей на странице админки.
+var usersPerPage = 50
+
+// GetRoles возвращает роли с их действиями.
+func (s *Discipline) GetRoles(ctx context.Context) (*[]types.TRole, error) {
+	roles, err := s.store.GetRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed get roles: %w", err)
+	}
+	result := make([]types.TRole, 0, len(*roles))
+	for _, role := range *roles {
+		r := types.TRole{ID: role.ID, Name: role.Name, Actions: []string{}}
+		for _, action := range role.Actions {
+			r.Actions = append(r.Actions, action.Name)
+		}
+		result = append(result, r)
+	}
+	return &result, nil
+}
+
+// GetUsersWithRoles возвращает страницу page пользователей с их ролями. Непустой query
+// оставляет пользователей, в логине которых он встречается без учета регистра.
+func (s *Discipline) GetUsersWithRoles(ctx context.Context, query string, page int) (*types.TUsersList, error) {
+	users, total, err := s.store.FindUsers(ctx, strings.TrimSpace(query), pageOffset(page, usersPerPage), usersPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed get users: %w", err)
+	}
+	result := &types.TUsersList{
+		Users:      make([]types.TUserRoles, 0, len(*users)),
+		Pagination: newPagination(page, usersPerPage, total),
+	}
+	for _, user := range *users {
+		result.Users = append(result.Users, userRolesView(&user))
+	}
+	return result, nil
+}
+
+func userRolesView(user *models.User) types.TUserRoles {
+	u := types.TUserRoles{
+		ID:        user.ID,
+		Login:     user.Login,
+		IsMaster:  len(user.OwnedMasters()) > 0,
+		Locked:    user.LockedAt != nil,
+		CreatedAt: user.CreatedAt,
+		Roles:     map[string]bool{},
+	}
+	for _, role := range user.Roles {
+		u.Roles[role.Name] = true
+	}
+	return u
+}
+
+// SetUserRoles заменяет роли пользователя userID на roles, изменение выполняет actorID.
+// Снять с себя управление пользователями нельзя, иначе в системе может не остаться админа.
+// Мастеру квестов без группы она создается, как при ManageCreateSelfMaster.
+func (s *Discipline) SetUserRoles(ctx context.Context, actorID, userID uint, roles []string) error {
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return notFound(err, "failed get user")
+	}
+	catalog, err := s.store.GetRoles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed get roles: %w", err)
+	}
+	byName := map[string]models.Role{}
+	for _, role := range *catalog {
+		byName[role.Name] = role
+	}
+
+	roleIDs := []uint{}
+	adminUsers, questMaster := false, false
+	for _, name := range roles {
+		role, ok := byName[name]
+		if !ok {
+			return apperr.ErrValidation.WithField("roles", fmt.Sprintf("Неизвестная роль %s", name))
+		}
+		roleIDs = append(roleIDs, role.ID)
+		for _, action := range role.Actions {
+			if action.Name == models.ActionAdminUsers {
+				adminUsers = true
+			}
+		}
+		if role.Name == models.RoleQuestMaster {
+			questMaster = true
+		}
+	}
+	if actorID == userID && !adminUsers {
+		return apperr.ErrValidation.WithField("roles", "Нельзя снять с себя управление пользователями")
+	}
+
+	// со-мастер чужой группы получает право роли в ней, своей группы не создаем
+	if questMaster && len(user.Memberships) == 0 {
+		if _, err := s.newMaster(ctx, userID, defaultMasterName); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.SetUserRoles(ctx, userID, roleIDs); err != nil {
+		return notFound(err, "failed set user roles")
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+	"github.com/mod-develop/backend/pkg/tools"
+)
+
+var (
+	sessionIDBytes       = 16
+	refreshTokenBytes    = 32
+	tokenCleanupInterval = time.Hour
+	// sessionTouchInterval - как часто сохранять время последней активности сессии,
+	// чтобы не писать в базу на каждый запрос.
+	sessionTouchInterval = time.Minute
+	// refreshReuseGrace - сколько после обновления старый токен может прийти без признаков кражи:
+	// параллельные запросы из нескольких вкладок браузера обновляют сессию одним токеном.
+	refreshReuseGrace = time.Minute
+)
+
+func (s *Discipline) workerTokenCleanup(ctx context.Context) {
+	ticker := time.NewTicker(tokenCleanupInterval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.store.DeleteExpiredTokens(ctx, time.Now().UTC()); err != nil {
+				s.log.Error("failed delete expired tokens", zap.Error(err))
+			}
+		}
+	}
+}
+
+// StartSession начинает сессию пользователя на устройстве со сроком ttl.
+// Возвращает идентификатор сессии и токен обновления.
+func (s *Discipline) StartSession(ctx context.Context, userID uint, userAgent, ip string, ttl time.Duration) (string, string, error) {
+	sessionID, err := tools.RandomToken(sessionIDBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed create session id: %w", err)
+	}
+	token, refresh, err := newRefreshToken(userID, sessionID, ttl)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now().UTC()
+	session := &models.Session{
+		ID:         sessionID,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := s.store.NewSession(ctx, session, refresh); err != nil {
+		return "", "", fmt.Errorf("failed save session: %w", err)
+	}
+	return sessionID, token, nil
+}
+
+// RefreshSession обменивает токен обновления на новый и продлевает сессию.
+// Возвращает пользователя, идентификатор сессии и новый токен.
+// Повторное использование уже замененного токена считается кражей,
+// тогда отзываются все сессии пользователя.
+func (s *Discipline) RefreshSession(ctx context.Context, token, ip string, ttl time.Duration) (*models.User, string, string, error) {
+	current, err := s.store.GetRefreshToken(ctx, tools.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", "", apperr.ErrUnauthorized.Wrap(err)
+		}
+		return nil, "", "", fmt.Errorf("failed get refresh token: %w", err)
+	}
+	now := time.Now().UTC()
+	if current.RevokedAt != nil {
+		if now.Sub(*current.RevokedAt) > refreshReuseGrace {
+			s.log.Warn("revoked refresh token reused, revoking all user tokens", zap.Uint("user_id", current.UserID))
+			if err := s.store.RevokeUserTokens(ctx, current.UserID); err != nil {
+				return nil, "", "", fmt.Errorf("failed revoke user tokens: %w", err)
+			}
+		}
+		return nil, "", "", apperr.ErrUnauthorized
+	}
+	if !current.ExpiresAt.After(now) {
+		return nil, "", "", apperr.ErrUnauthorized
+	}
+	session, err := s.activeSession(ctx, current.SessionID, current.UserID, now)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	next, refresh, err := newRefreshToken(current.UserID, session.ID, ttl)
+	if err != nil {
+		return nil, "", "", err
+	}
+	rotated, err := s.store.RotateRefreshToken(ctx, current, refresh)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed rotate refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, "", "", apperr.ErrUnauthorized
+	}
+	session.LastSeenAt = now
+	session.IP = ip
+	session.ExpiresAt = now.Add(ttl)
+	if err := s.store.TouchSession(ctx, session); err != nil {
+		return nil, "", "", fmt.Errorf("failed touch session: %w", err)
+	}
+
+	user, err := s.store.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed get user: %w", err)
+	}
+	if user.LockedAt != nil {
+		return nil, "", "", apperr.ErrAccountLocked
+	}
+	return user, session.ID, next, nil
+}
+
+// CheckAccessToken проверяет, что сессия sessionID пользователя userID действует,
+// и возвращает пользователя. Для завершенной сессии возвращает apperr.ErrUnauthorized.
+func (s *Discipline) CheckAccessToken(ctx context.Context, userID uint, sessionID string, version uint, ip string) (*models.User, error) {
+	now := time.Now().UTC()
+	session, err := s.activeSession(ctx, sessionID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval || session.IP != ip {
+		session.LastSeenAt = now
+		session.IP = ip
+		if err := s.store.TouchSession(ctx, session); err != nil {
+			s.log.Error("failed touch session", zap.Error(err))
+		}
+	}
+
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrUnauthorized.Wrap(err)
+		}
+		return nil, fmt.Errorf("failed get user: %w", err)
+	}
+	if user.TokenVersion != version {
+		return nil, apperr.ErrUnauthorized
+	}
+	if user.LockedAt != nil {
+		return nil, apperr.ErrAccountLocked
+	}
+	return user, nil
+}
+
+// Logout завершает сессию sessionID. Без идентификатора сессия ищется по токену обновления.
+// Пустые значения пропускаются.
+func (s *Discipline) Logout(ctx context.Context, sessionID, refreshToken string) error {
+	if sessionID == "" && refreshToken != "" {
+		token, err := s.store.GetRefreshToken(ctx, tools.HashToken(refreshToken))
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed get refresh token: %w", err)
+		}
+		if err == nil {
+			sessionID = token.SessionID
+		}
+	}
+	if sessionID == "" {
+		return nil
+	}
+	if err := s.store.RevokeSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed revoke session: %w", err)
+	}
+	return nil
+}
+
+// LogoutAll завершает сессии пользователя на всех устройствах.
+func (s *Discipline) LogoutAll(ctx context.Context, userID uint) error {
+	if err := s.store.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+// GetSessions возвращает действующие сессии пользователя, currentID - сессия текущего запроса.
+func (s *Discipline) GetSessions(ctx context.Context, userID uint, currentID string) (*[]types.TSession, error) {
+	sessions, err := s.store.GetUserSessions(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed get user sessions: %w", err)
+	}
+	result := []types.TSession{}
+	for _, session := range *sessions {
+		result = append(result, types.TSession{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentID,
+		})
+	}
+	return &result, nil
+}
+
+// RevokeSession завершает сессию sessionID пользователя userID.
+func (s *Discipline) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Join(err, apperr.ErrDataNotFound)
+		}
+		return fmt.Errorf("failed get session: %w", err)
+	}
+	if session.UserID != userID {
+		return apperr.ErrDataNotFound
+	}
+	if err := s.store.RevokeSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed revoke session: %w", err)
+	}
+	return nil
+}
+
+// activeSession возвращает действующую сессию пользователя userID.
+func (s *Discipline) activeSession(ctx context.Context, sessionID string, userID uint, now time.Time) (*models.Session, error) {
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrUnauthorized.Wrap(err)
+		}
+		return nil, fmt.Errorf("failed get session: %w", err)
+	}
+	if session.UserID != userID || !session.Active(now) {
+		return nil, apperr.ErrUnauthorized
+	}
+	return session, nil
+}
+
+// newRefreshToken создает токен и запись о нем с хешем токена.
+func newRefreshToken(userID uint, sessionID string, ttl time.Duration) (string, *models.RefreshToken, error) {
+	token, err := tools.RandomToken(refreshTokenBytes)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed create refresh token: %w", err)
+	}
+	return token, &models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: tools.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}, nil
+}
@@ -0,0 +1,160 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+// streakOnPay возвращает пересчет серии игрока при оплате выполнения status, nil у разовых квестов.
+// Серия считается по всем оплаченным периодам в порядке периодов, поэтому период, подтвержденный
+// позже следующего, тоже попадает в серию. Бонус платится за отметки, которых серия с этим
+// периодом достигла только сейчас.
+func streakOnPay(status *models.QuestPlayerStatus) StreakFunc {
+	quest := status.Quest
+	if quest.Type == models.OneTime {
+		return nil
+	}
+	period := streakPeriod(status.PeriodKey)
+
+	return func(streak *models.QuestStreak, paidPeriods []string) []models.QuestStreakBonus {
+		paid := paidPerPeriod(&quest, paidPeriods)
+		runs := streakRuns(&quest, paid)
+		if len(runs) == 0 {
+			return nil
+		}
+		periods := slices.Sorted(maps.Keys(runs))
+		streak.LastPeriod = periods[len(periods)-1]
+		streak.Current = runs[streak.LastPeriod]
+		for _, n := range runs {
+			streak.Best = max(streak.Best, n)
+		}
+
+		// неделя засчитывается один раз, последним из нужных выполнений
+		if paid[period] != requiredPerPeriod(&quest) {
+			return nil
+		}
+		end := period
+		for _, p := range periods[slices.Index(periods, period)+1:] {
+			if previousPeriod(&quest, p) != end {
+				break
+			}
+			end = p
+		}
+		// до оплаты периода на его месте был разрыв между двумя сериями
+		reached := max(runs[previousPeriod(&quest, period)], runs[end]-runs[period])
+		bonuses := []models.QuestStreakBonus{}
+		for _, b := range quest.StreakBonuses {
+			if b.Days > reached && b.Days <= runs[end] {
+				bonuses = append(bonuses, b)
+			}
+		}
+		return bonuses
+	}
+}
+
+// requiredPerPeriod - сколько выполнений нужно, чтобы период засчитался в серию.
+func requiredPerPeriod(quest *models.Quest) int {
+	if quest.Type == models.TimesPerWeek {
+		return int(max(quest.Schedule.Times, 1))
+	}
+	return 1
+}
+
+// paidPerPeriod - число оплаченных выполнений в каждом периоде серии. Ключи, которые
+// не подходят к текущему расписанию квеста, остались от прежнего расписания и не считаются.
+func paidPerPeriod(quest *models.Quest, paidPeriods []string) map[string]int {
+	paid := map[string]int{}
+	for _, key := range paidPeriods {
+		period := streakPeriod(key)
+		if _, _, ok := periodBounds(quest, period, time.UTC); ok {
+			paid[period]++
+		}
+	}
+	return paid
+}
+
+// streakRuns - длина серии, которая заканчивается в каждом засчитанном периоде.
+// Ключи периодов одного расписания упорядочены как строки.
+func streakRuns(quest *models.Quest, paid map[string]int) map[string]uint {
+	runs := map[string]uint{}
+	for _, period := range slices.Sorted(maps.Keys(paid)) {
+		if paid[period] >= requiredPerPeriod(quest) {
+			runs[period] = runs[previousPeriod(quest, period)] + 1
+		}
+	}
+	return runs
+}
+
+// currentStreak - длина серии на момент now. Серия прервана, если последний
+// засчитанный период не текущий и не предыдущий.
+func currentStreak(quest *models.Quest, streak *models.QuestStreak, now time.Time, loc *time.Location) uint {
+	period := streakPeriodAt(quest, now, loc)
+	if streak.LastPeriod == period || streak.LastPeriod == previousPeriod(quest, period) {
+		return streak.Current
+	}
+	return 0
+}
+
+// applyPlayerStreak переносит серию игрока и бонусы квеста в представление игрока.
+func (s *Discipline) applyPlayerStreak(ctx context.Context, quest *types.TPlayerQuest, q *models.Quest, playerID uint, now time.Time, loc *time.Location) error {
+	if q.Type == models.OneTime {
+		return nil
+	}
+	streak, err := s.store.GetQuestStreak(ctx, q.ID, playerID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed get quest streak: %w", err)
+	}
+	if streak != nil {
+		quest.StreakCurrent = currentStreak(q, streak, now, loc)
+		quest.StreakBest = streak.Best
+	}
+
+	bonuses, err := s.store.GetStreakBonuses(ctx, q.ID)
+	if err != nil {
+		return fmt.Errorf("failed get streak bonuses: %w", err)
+	}
+	quest.StreakBonuses = streakBonusesToView(*bonuses)
+
+	return nil
+}
+
+func streakBonusesToView(bonuses []models.QuestStreakBonus) []types.TStreakBonus {
+	result := []types.TStreakBonus{}
+	for _, b := range bonuses {
+		result = append(result, types.TStreakBonus{
+			Days:  b.Days,
+			Bonus: b.Bonus,
+		})
+	}
+	slices.SortFunc(result, func(a, b types.TStreakBonus) int { return int(a.Days) - int(b.Days) })
+	return result
+}
+
+// streakBonusesFromView собирает отметки серии из формы. Пустые строки пропускаются,
+// для одинаковой длины серии остается последний бонус. У разовых квестов серий нет.
+func streakBonusesFromView(bonuses []types.TStreakBonus, questType models.QuestType) []models.QuestStreakBonus {
+	result := []models.QuestStreakBonus{}
+	if questType == models.OneTime {
+		return result
+	}
+	for _, b := range bonuses {
+		if b.Days == 0 || b.Bonus == 0 {
+			continue
+		}
+		result = slices.DeleteFunc(result, func(r models.QuestStreakBonus) bool { return r.Days == b.Days })
+		result = append(result, models.QuestStreakBonus{
+			Days:  b.Days,
+			Bonus: b.Bonus,
+		})
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package discipline
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mod-develop/backend/internal/models"
+)
+
+func TestStreakOnPay(t *testing.T) {
+	daily := models.Quest{Type: models.Daily, StreakBonuses: []models.QuestStreakBonus{{Days: 3, Bonus: 5}}}
+	weekly := models.Quest{Type: models.Weekly}
+	threeTimes := models.Quest{Type: models.TimesPerWeek, Schedule: models.QuestSchedule{Times: 3},
+		StreakBonuses: []models.QuestStreakBonus{{Days: 2, Bonus: 7}}}
+	monthEndDays := models.Quest{Type: models.Monthly, Schedule: monthEnd}
+	week := func(key string, times int) []string {
+		keys := []string{}
+		for i := 1; i <= times; i++ {
+			keys = append(keys, key+"#"+strconv.Itoa(i))
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name      string
+		quest     models.Quest
+		period    string
+		paid      []string // ранее оплаченные периоды
+		streak    models.QuestStreak
+		want      models.QuestStreak
+		wantBonus []uint
+	}{
+		{name: "first pay", quest: daily, period: "2024-01-01",
+			want: models.QuestStreak{Current: 1, Best: 1, LastPeriod: "2024-01-01"}},
+		{name: "next day across year", quest: daily, period: "2024-01-01", paid: []string{"2023-12-31"},
+			streak: models.QuestStreak{Current: 1, Best: 4, LastPeriod: "2023-12-31"},
+			want:   models.QuestStreak{Current: 2, Best: 4, LastPeriod: "2024-01-01"}},
+		{name: "bonus mark reached", quest: daily, period: "2024-03-01", paid: []string{"2024-02-28", "2024-02-29"},
+			want: models.QuestStreak{Current: 3, Best: 3, LastPeriod: "2024-03-01"}, wantBonus: []uint{3}},
+		{name: "break after skipped day", quest: daily, period: "2024-01-03", paid: []string{"2024-01-01"},
+			streak: models.QuestStreak{Current: 5, Best: 5, LastPeriod: "2024-01-01"},
+			want:   models.QuestStreak{Current: 1, Best: 5, LastPeriod: "2024-01-03"}},
+		{name: "earlier period confirmed late", quest: daily, period: "2023-12-31", paid: []string{"2024-01-01", "2023-12-30"},
+			want: models.QuestStreak{Current: 3, Best: 3, LastPeriod: "2024-01-01"}, wantBonus: []uint{3}},
+		{name: "late period joins reached mark", quest: daily, period: "2024-01-04",
+			paid: []string{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-05"},
+			want: models.QuestStreak{Current: 5, Best: 5, LastPeriod: "2024-01-05"}},
+		{name: "week after week 53", quest: weekly, period: "2021-W01", paid: []string{"2020-W53"},
+			want: models.QuestStreak{Current: 2, Best: 2, LastPeriod: "2021-W01"}},
+		{name: "break after skipped week", quest: weekly, period: "2021-W02", paid: []string{"2020-W52", "2020-W53"},
+			want: models.QuestStreak{Current: 1, Best: 2, LastPeriod: "2021-W02"}},
+		{name: "month end skips february", quest: monthEndDays, period: "2023-03-29", paid: []string{"2023-01-31"},
+			want: models.QuestStreak{Current: 2, Best: 2, LastPeriod: "2023-03-29"}},
+		{name: "week not finished", quest: threeTimes, period: "2024-W02#2", paid: append(week("2024-W01", 3), "2024-W02#1"),
+			want: models.QuestStreak{Current: 1, Best: 1, LastPeriod: "2024-W01"}},
+		{name: "week finished by last time", quest: threeTimes, period: "2024-W02#3", paid: append(week("2024-W01", 3), week("2024-W02", 2)...),
+			want: models.QuestStreak{Current: 2, Best: 2, LastPeriod: "2024-W02"}, wantBonus: []uint{2}},
+		{name: "extra time in finished week", quest: threeTimes, period: "2024-W02#4", paid: append(week("2024-W01", 3), week("2024-W02", 3)...),
+			want: models.QuestStreak{Current: 2, Best: 2, LastPeriod: "2024-W02"}},
+		// "2024-W02" больше любого ключа дня 2024 года, но после смены расписания серия начинается заново
+		{name: "weekly changed to daily", quest: daily, period: "2024-01-20", paid: []string{"2024-W01", "2024-W02"},
+			streak: models.QuestStreak{Current: 2, Best: 4, LastPeriod: "2024-W02"},
+			want:   models.QuestStreak{Current: 1, Best: 4, LastPeriod: "2024-01-20"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onStreak := streakOnPay(&models.QuestPlayerStatus{PeriodKey: tt.period, Quest: tt.quest})
+			streak := tt.streak
+			bonuses := onStreak(&streak, append(slices.Clone(tt.paid), tt.period))
+			if streak.Current != tt.want.Current || streak.Best != tt.want.Best || streak.LastPeriod != tt.want.LastPeriod {
+				t.Errorf("streakOnPay() streak = %+v, want %+v", streak, tt.want)
+			}
+			days := []uint{}
+			for _, b := range bonuses {
+				days = append(days, b.Days)
+			}
+			if len(days)+len(tt.wantBonus) > 0 && !slices.Equal(days, tt.wantBonus) {
+				t.Errorf("streakOnPay() bonuses for %v days, want %v", days, tt.wantBonus)
+			}
+		})
+	}
+
+	if onStreak := streakOnPay(&models.QuestPlayerStatus{Quest: models.Quest{Type: models.OneTime}}); onStreak != nil {
+		t.Errorf("streakOnPay() for one time quest is not nil")
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	daily := &models.Quest{Type: models.Daily}
+	streak := &models.QuestStreak{Current: 3, Best: 3, LastPeriod: "2023-12-31"}
+	tests := []struct {
+		name     string
+		now      time.Time
+		loc      *time.Location
+		expected uint
+	}{
+		{name: "same day", now: time.Date(2023, 12, 31, 20, 0, 0, 0, time.UTC), loc: time.UTC, expected: 3},
+		{name: "next day", now: time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), loc: time.UTC, expected: 3},
+		{name: "day skipped", now: time.Date(2024, 1, 2, 0, 30, 0, 0, time.UTC), loc: time.UTC, expected: 0},
+		{name: "not skipped yet in player zone", now: time.Date(2024, 1, 2, 0, 30, 0, 0, time.UTC), loc: tzNewYork, expected: 3},
+	}
+	for _, tt := range tests {
+		if got := currentStreak(daily, streak, tt.now, tt.loc); got != tt.expected {
+			t.Errorf("%s: currentStreak() = %d, want %d", tt.name, got, tt.expected)
+		}
+	}
+}
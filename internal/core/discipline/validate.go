@@ -0,0 +1,259 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	minLoginLength    = 3
+	maxLoginLength    = 32
+	minPasswordLength = 8
+	// bcrypt учитывает только первые 72 байта пароля
+	maxPasswordLength = 72
+
+	maxQuestTitleLength       = 100
+	maxQuestDescriptionLength = 2000
+	maxQuestPrice             = uint(100000)
+
+	maxRewardTitleLength       = 100
+	maxRewardDescriptionLength = 2000
+	maxRewardCost              = uint(100000)
+
+	loginPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// validation собирает ошибки полей, чтобы вернуть пользователю их все сразу.
+type validation struct {
+	err *apperr.Error
+}
+
+func (v *validation) add(field, message string) {
+	if v.err == nil {
+		v.err = apperr.ErrValidation
+	}
+	v.err = v.err.WithField(field, message)
+}
+
+// merge добавляет ошибки полей из err. Ошибки без полей возвращаются как есть.
+func (v *validation) merge(err error) error {
+	appErr, ok := apperr.As(err)
+	if !ok || len(appErr.Details) == 0 {
+		return err
+	}
+	for field, message := range appErr.Details {
+		v.add(field, message)
+	}
+	return nil
+}
+
+func (v *validation) error() error {
+	if v.err == nil {
+		return nil
+	}
+	return v.err
+}
+
+// validateRegistration проверяет логин и пароль нового пользователя.
+func (s *Discipline) validateRegistration(ctx context.Context, login, password string) error {
+	v := &validation{}
+	switch {
+	case utf8.RuneCountInString(login) < minLoginLength || utf8.RuneCountInString(login) > maxLoginLength:
+		v.add("login", fmt.Sprintf("Логин должен быть длиной от %d до %d символов", minLoginLength, maxLoginLength))
+	case !loginPattern.MatchString(login):
+		v.add("login", "Логин может содержать только латинские буквы, цифры и символы _ . -")
+	}
+
+	validatePassword(v, password)
+	if err := v.error(); err != nil {
+		return err
+	}
+
+	// логины сравниваются без учета регистра, как и в уникальном индексе
+	_, err := s.store.GetUserByLogin(ctx, login)
+	if err == nil {
+		return errLoginTaken(nil)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed check login: %w", err)
+	}
+	return nil
+}
+
+// validatePassword проверяет длину и состав пароля, ошибка относится к полю password.
+func validatePassword(v *validation, password string) {
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		hasLetter = hasLetter || unicode.IsLetter(r)
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+	switch {
+	case utf8.RuneCountInString(password) < minPasswordLength:
+		v.add("password", fmt.Sprintf("Пароль должен быть не короче %d символов", minPasswordLength))
+	case len(password) > maxPasswordLength:
+		v.add("password", "Слишком длинный пароль")
+	case !hasLetter || !hasDigit:
+		v.add("password", "Пароль должен содержать буквы и цифры")
+	}
+}
+
+func errLoginTaken(err error) error {
+	return apperr.ErrLoginTaken.WithField("login", "Выберите другой логин").Wrap(err)
+}
+
+// questFromView проверяет квест из формы или API и собирает модель для сохранения.
+// Игроки квеста должны принадлежать мастеру пользователя userID.
+func (s *Discipline) questFromView(ctx context.Context, quest *types.TQuest, masterID, userID uint) (*models.Quest, error) {
+	v := &validation{}
+	q := &models.Quest{
+		Model:        gorm.Model{ID: quest.ID},
+		Title:        strings.TrimSpace(quest.Title),
+		Description:  strings.TrimSpace(quest.Description),
+		Type:         models.OneTime,
+		UserMasterID: masterID,
+		UserID:       userID,
+		Price:        quest.Price,
+		Penalty:      quest.Penalty,
+		IsActive:     quest.IsActive,
+	}
+
+	switch {
+	case q.Title == "":
+		v.add("title", "Укажите название квеста")
+	case utf8.RuneCountInString(q.Title) > maxQuestTitleLength:
+		v.add("title", fmt.Sprintf("Название должно быть не длиннее %d символов", maxQuestTitleLength))
+	}
+	if utf8.RuneCountInString(q.Description) > maxQuestDescriptionLength {
+		v.add("description", fmt.Sprintf("Описание должно быть не длиннее %d символов", maxQuestDescriptionLength))
+	}
+	if q.Price > maxQuestPrice {
+		v.add("price", fmt.Sprintf("Награда должна быть не больше %d", maxQuestPrice))
+	}
+	if q.Penalty > maxQuestPrice {
+		v.add("penalty", fmt.Sprintf("Штраф должен быть не больше %d", maxQuestPrice))
+	}
+
+	var err error
+	if q.StartTime, err = parseFormDate(quest.DateStart, quest.TimeZoneOffset); err != nil {
+		v.add("dateStart", "Неверная дата начала")
+	}
+	if q.EndTime, err = parseFormDate(quest.DateEnd, quest.TimeZoneOffset); err != nil {
+		v.add("dateEnd", "Неверная дата окончания")
+	}
+	if q.StartTime != nil && q.EndTime != nil && !q.StartTime.Before(*q.EndTime) {
+		v.add("dateEnd", "Дата окончания должна быть позже даты начала")
+	}
+
+	for _, t := range quest.Types {
+		if t.Selected {
+			q.Type = t.Value
+			break
+		}
+	}
+	q.Schedule, err = scheduleFromView(quest, q.Type)
+	if err = v.merge(err); err != nil {
+		return nil, err
+	}
+	q.StreakBonuses = streakBonusesFromView(quest.StreakBonuses, q.Type)
+
+	selected := []uint{}
+	for _, p := range quest.Players {
+		if p.Selected {
+			selected = append(selected, p.ID)
+		}
+	}
+	if len(selected) > 0 {
+		players, err := s.masterPlayerIDs(ctx, masterID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range selected {
+			if _, ok := players[id]; !ok {
+				v.add("players", "Можно выбрать только своих игроков")
+				break
+			}
+			q.Players = append(q.Players, models.User{ID: id})
+		}
+	}
+
+	if err := v.error(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// rewardFromView проверяет награду из формы или API и собирает модель для сохранения.
+func rewardFromView(reward *types.TReward, masterID uint) (*models.Reward, error) {
+	v := &validation{}
+	r := &models.Reward{
+		Model:        gorm.Model{ID: reward.ID},
+		UserMasterID: masterID,
+		Title:        strings.TrimSpace(reward.Title),
+		Description:  strings.TrimSpace(reward.Description),
+		Cost:         reward.Cost,
+		IsActive:     reward.IsActive,
+	}
+	if !reward.IsUnlimited {
+		stock := reward.Stock
+		r.Stock = &stock
+	}
+
+	switch {
+	case r.Title == "":
+		v.add("title", "Укажите название награды")
+	case utf8.RuneCountInString(r.Title) > maxRewardTitleLength:
+		v.add("title", fmt.Sprintf("Название должно быть не длиннее %d символов", maxRewardTitleLength))
+	}
+	if utf8.RuneCountInString(r.Description) > maxRewardDescriptionLength {
+		v.add("description", fmt.Sprintf("Описание должно быть не длиннее %d символов", maxRewardDescriptionLength))
+	}
+	switch {
+	case r.Cost == 0:
+		v.add("cost", "Стоимость должна быть больше нуля")
+	case r.Cost > maxRewardCost:
+		v.add("cost", fmt.Sprintf("Стоимость должна быть не больше %d", maxRewardCost))
+	}
+
+	if err := v.error(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// masterPlayerIDs возвращает игроков группы masterID.
+func (s *Discipline) masterPlayerIDs(ctx context.Context, masterID uint) (map[uint]struct{}, error) {
+	ids := map[uint]struct{}{}
+	players, err := s.GetPlayers(ctx, masterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range *players {
+		ids[p.ID] = struct{}{}
+	}
+	return ids, nil
+}
+
+// parseFormDate разбирает дату из формы, пустая строка означает отсутствие даты.
+func parseFormDate(value string, timeZoneOffset int) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(defaultFormDateTimeFormat, value)
+	if err != nil {
+		return nil, err
+	}
+	date = date.Add(time.Duration(timeZoneOffset) * time.Minute)
+	return &date, nil
+}
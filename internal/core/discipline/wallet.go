@@ -0,0 +1,184 @@
+package discipline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/mod-develop/backend/internal/adapters/apperr"
+	"github.com/mod-develop/backend/internal/adapters/types"
+	"github.com/mod-develop/backend/internal/models"
+)
+
+var (
+	transactionsPerPage = 20
+
+	transactionTitles = map[models.WalletTransactionType]string{
+		models.TransactionAccrual:    "Начисление за квест",
+		models.TransactionAdjustment: "Корректировка",
+		models.TransactionPurchase:   "Покупка награды",
+		models.TransactionRefund:     "Возврат покупки",
+		models.TransactionReversal:   "Отмена операции",
+		models.TransactionStreak:     "Бонус за серию",
+		models.TransactionPenalty:    "Штраф",
+	}
+
+	// покупки и возвраты закрываются через очередь покупок, а не отменой операции
+	reversibleTransactions = map[models.WalletTransactionType]bool{
+		models.TransactionAccrual:    true,
+		models.TransactionAdjustment: true,
+		models.TransactionStreak:     true,
+		models.TransactionPenalty:    true,
+	}
+)
+
+func walletToView(w *models.PlayerWallet) types.TPlayerWaller {
+	return types.TPlayerWaller{
+		ID:         w.ID,
+		Score:      w.Balance,
+		PlayerID:   w.PlayerID,
+		PlayerName: w.Player.Login,
+		MasterID:   w.UserMasterID,
+	}
+}
+
+func transactionToView(t *models.WalletTransaction) types.TWalletTransaction {
+	return types.TWalletTransaction{
+		ID:         t.ID,
+		Type:       t.Type,
+		TypeTitle:  transactionTitles[t.Type],
+		Amount:     t.Amount,
+		Balance:    t.Balance,
+		Comment:    t.Comment,
+		Date:       t.CreatedAt.UTC().Format(defaultViewDateTimeFormat),
+		IsReversed: t.IsReversed,
+		CanReverse: !t.IsReversed && reversibleTransactions[t.Type],
+	}
+}
+
+func newPagination(page, perPage int, total int64) types.TPagination {
+	pages := int((total + int64(perPage) - 1) / int64(perPage))
+	if pages == 0 {
+		pages = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	return types.TPagination{
+		Page:  page,
+		Pages: pages,
+		Total: total,
+	}
+}
+
+func pageOffset(page, perPage int) int {
+	if page < 1 {
+		page = 1
+	}
+	return (page - 1) * perPage
+}
+
+func (s *Discipline) GetMasterWallets(ctx context.Context, masterID uint) (*[]types.TPlayerWaller, error) {
+	result := []types.TPlayerWaller{}
+	wallets, err := s.store.GetMasterWallets(ctx, masterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &result, nil
+		}
+		return nil, fmt.Errorf("failed get master wallets: %w", err)
+	}
+	for _, w := range *wallets {
+		result = append(result, walletToView(&w))
+	}
+	return &result, nil
+}
+
+// GetMasterWalletHistory - журнал операций кошелька игрока для мастера квестов.
+func (s *Discipline) GetMasterWalletHistory(ctx context.Context, walletID, masterID uint, page int) (*types.TWalletHistory, error) {
+	wallet, err := s.masterWallet(ctx, walletID, masterID)
+	if err != nil {
+		return nil, err
+	}
+
+	txns, total, err := s.store.GetWalletTransactions(ctx, walletID, pageOffset(page, transactionsPerPage), transactionsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed get wallet transactions: %w", err)
+	}
+
+	history := &types.TWalletHistory{
+		Wallet:     walletToView(wallet),
+		Pagination: newPagination(page, transactionsPerPage, total),
+	}
+	for _, t := range *txns {
+		history.Transactions = append(history.Transactions, transactionToView(&t))
+	}
+	return history, nil
+}
+
+// GetPlayerWalletHistory - журнал операций по всем кошелькам игрока.
+func (s *Discipline) GetPlayerWalletHistory(ctx context.Context, playerID uint, page int) (*types.TWalletHistory, error) {
+	txns, total, err := s.store.GetPlayerTransactions(ctx, playerID, pageOffset(page, transactionsPerPage), transactionsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed get player transactions: %w", err)
+	}
+
+	history := &types.TWalletHistory{
+		Wallet:     types.TPlayerWaller{PlayerID: playerID},
+		Pagination: newPagination(page, transactionsPerPage, total),
+	}
+	for _, t := range *txns {
+		tr := transactionToView(&t)
+		tr.CanReverse = false
+		history.Transactions = append(history.Transactions, tr)
+	}
+
+	wallets, err := s.store.GetWallets(ctx, playerID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed get wallets: %w", err)
+	}
+	if wallets != nil {
+		for _, w := range *wallets {
+			history.Wallet.Score += w.Balance
+		}
+	}
+
+	return history, nil
+}
+
+// AdjustWallet - ручная корректировка баланса игрока мастером квестов.
+func (s *Discipline) AdjustWallet(ctx context.Context, masterID, playerID, authorID uint, amount int, comment string) error {
+	if err := s.masterPlayer(ctx, masterID, playerID); err != nil {
+		return err
+	}
+
+	_, err := s.store.AdjustWallet(ctx, &models.WalletTransaction{
+		UserMasterID: masterID,
+		PlayerID:     playerID,
+		Amount:       amount,
+		Comment:      comment,
+		AuthorID:     &authorID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed adjust wallet: %w", err)
+	}
+	return nil
+}
+
+// ReverseWalletTransaction - отмена операции кошелька встречной записью журнала.
+func (s *Discipline) ReverseWalletTransaction(ctx context.Context, transactionID, masterID, authorID uint) error {
+	txn, err := s.masterTransaction(ctx, transactionID, masterID)
+	if err != nil {
+		return err
+	}
+	if !reversibleTransactions[txn.Type] {
+		return apperr.ErrTransactionNotReversible
+	}
+
+	_, err = s.store.ReverseWalletTransaction(ctx, txn, authorID)
+	if err != nil {
+		return fmt.Errorf("failed reverse wallet transaction: %w", err)
+	}
+	return nil
+}
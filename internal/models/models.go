@@ -10,49 +10,101 @@ const (
 	RoleAdmin       = "admin"
 	RoleQuestMaster = "quest_master"
 
-	ActionCreateQuest = "create_quest"
+	ActionCreateQuest       = "create_quest"
+	ActionConfirmSubmission = "confirm_submission"
+	ActionManagePlayers     = "manage_players"
+	ActionManageRewards     = "manage_rewards"
+	ActionManageWallets     = "manage_wallets"
+	ActionAdminUsers        = "admin_users"
 )
 
+// Роли и действия по умолчанию создают миграции 0010_default_roles и 0016_permissions,
+// идентификаторы здесь должны совпадать с ними.
 var (
+	// Permissions - каталог действий, которые можно выдать роли.
+	Permissions = []Action{
+		{Model: gorm.Model{ID: 1}, Name: ActionCreateQuest},
+		{Model: gorm.Model{ID: 2}, Name: ActionConfirmSubmission},
+		{Model: gorm.Model{ID: 3}, Name: ActionManagePlayers},
+		{Model: gorm.Model{ID: 4}, Name: ActionManageRewards},
+		{Model: gorm.Model{ID: 5}, Name: ActionManageWallets},
+		{Model: gorm.Model{ID: 6}, Name: ActionAdminUsers},
+	}
+
+	// DefaultRoles - роли по умолчанию с их действиями.
+	DefaultRoles = []Role{
+		{
+			Model: gorm.Model{ID: 1},
+			Name:  RoleAdmin,
+			Actions: []Action{
+				{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}, {Model: gorm.Model{ID: 3}},
+				{Model: gorm.Model{ID: 4}}, {Model: gorm.Model{ID: 5}}, {Model: gorm.Model{ID: 6}},
+			},
+		},
+		RoleQuestMasterObject,
+	}
+
 	RoleQuestMasterObject = Role{
 		Model: gorm.Model{
 			ID: 2,
 		},
 		Name: RoleQuestMaster,
 		Actions: []Action{
-			{Model: gorm.Model{ID: 1}},
+			{Model: gorm.Model{ID: 1}}, {Model: gorm.Model{ID: 2}}, {Model: gorm.Model{ID: 3}},
+			{Model: gorm.Model{ID: 4}}, {Model: gorm.Model{ID: 5}},
 		},
 	}
 )
 
-var DefaultRoles = []Role{
-	{
-		Model: gorm.Model{
-			ID: 1,
-		},
-		Name:    RoleAdmin,
-		Actions: []Action{},
-	},
-	RoleQuestMasterObject,
+type User struct {
+	ID                 uint   `gorm:"primarykey"`
+	Login              string `gorm:"index"` // уникален без учета регистра, индекс idx_users_login_lower
+	PasswordHash       string
+	Roles              []Role     `gorm:"many2many:user_roles;"`
+	TimeZone           string     `gorm:"not null;default:'UTC'"`
+	TokenVersion       uint       `gorm:"not null;default:0"` // увеличивается при выходе со всех устройств
+	LockedAt           *time.Time // заблокирован админом, вход запрещен
+	MustChangePassword bool       `gorm:"not null;default:false"` // пароль сброшен админом, до смены доступны только смена пароля и выход
+	Memberships        []MasterMember
+	ActiveMasterID     *uint // группа, выбранная для управления; недоступную заменяет первая доступная
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
-var DefaultActions = []Action{
-	{
-		Model: gorm.Model{
-			ID: 1,
-		},
-		Name: ActionCreateQuest,
-	},
+// UserStats - число пользователей для проверки состояния системы.
+type UserStats struct {
+	Users   int64 // все пользователи
+	Locked  int64 // заблокированные
+	Masters int64 // владельцы хотя бы одной группы мастера
 }
 
-type User struct {
-	ID           uint   `gorm:"primarykey"`
-	Login        string `gorm:"index"`
-	PasswordHash string
-	Roles        []Role `gorm:"many2many:user_roles;"`
-	QuestMaster  *UserMaster
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+// Session - вход пользователя с одного устройства. Токены доступа и обновления
+// действуют, пока сессия не отозвана и не истекла.
+type Session struct {
+	ID         string `gorm:"primaryKey"` // случайный идентификатор, передается в токене доступа
+	UserID     uint   `gorm:"index:idx_session_user_id"`
+	UserAgent  string
+	IP         string `gorm:"column:ip"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"index:idx_session_expires_at"`
+	RevokedAt  *time.Time
+}
+
+// Active - сессия не отозвана и не истекла к моменту now.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
+
+// RefreshToken - токен обновления сессии. Хранится только хеш, сам токен знает клиент.
+// При обновлении токен отзывается и заменяется новым.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint   `gorm:"index:idx_refresh_token_user_id"`
+	SessionID string `gorm:"index:idx_refresh_token_session_id"`
+	TokenHash string `gorm:"uniqueIndex:uq_refresh_token_hash"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
 }
 
 type Role struct {
@@ -66,51 +118,259 @@ type Action struct {
 	Name string
 }
 
+// OwnedMasters возвращает группы, которыми пользователь владеет. Memberships должны быть загружены вместе с группами.
+func (u *User) OwnedMasters() []UserMaster {
+	masters := []UserMaster{}
+	for _, m := range u.Memberships {
+		if m.Role == MemberOwner {
+			masters = append(masters, m.UserMaster)
+		}
+	}
+	return masters
+}
+
+// UserMaster - группа мастера квестов. UserID - владелец, вместе с ним группой
+// управляют участники MasterMember. У пользователя может быть несколько групп.
 type UserMaster struct {
 	gorm.Model
-	UserID     uint   `gorm:"unique:uq_user"`
+	UserID     uint   `gorm:"index:idx_user_master_user_id"`
+	Name       string `gorm:"not null;default:''"`
 	UniqueCode string `gorm:"unique:uq_code"`
 	Players    []User `gorm:"many2many:master_players;constraint:OnDelete:CASCADE"`
 }
 
+type MemberRole string
+
+const (
+	MemberOwner    MemberRole = "owner"
+	MemberCoMaster MemberRole = "co_master"
+	MemberReviewer MemberRole = "reviewer"
+)
+
+// MemberActions - действия, которые роль участника дает в группе мастера.
+// Участниками и приглашениями управляет только владелец.
+var MemberActions = map[MemberRole][]string{
+	MemberOwner: {
+		ActionCreateQuest, ActionConfirmSubmission, ActionManagePlayers, ActionManageRewards, ActionManageWallets,
+	},
+	MemberCoMaster: {
+		ActionCreateQuest, ActionConfirmSubmission, ActionManagePlayers, ActionManageRewards, ActionManageWallets,
+	},
+	MemberReviewer: {ActionConfirmSubmission},
+}
+
+// MasterMember - пользователь, который управляет группой мастера. Владелец группы
+// тоже участник с ролью owner.
+type MasterMember struct {
+	ID           uint `gorm:"primarykey"`
+	UserMasterID uint `gorm:"uniqueIndex:uq_master_member"`
+	UserMaster   UserMaster
+	UserID       uint `gorm:"uniqueIndex:uq_master_member;index:idx_master_member_user_id"`
+	User         User
+	Role         MemberRole `sql:"type:enum('owner','co_master','reviewer')"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// MasterInvite - приглашение в группу мастера с ролью Role. Код принимается один раз до ExpiresAt.
+type MasterInvite struct {
+	ID           uint   `gorm:"primarykey"`
+	UserMasterID uint   `gorm:"index:idx_master_invite_master_id"`
+	Code         string `gorm:"uniqueIndex:uq_master_invite_code"`
+	Role         MemberRole
+	AuthorID     uint
+	ExpiresAt    time.Time
+	AcceptedByID *uint
+	AcceptedAt   *time.Time
+	CreatedAt    time.Time
+}
+
 type PlayerWallet struct {
 	gorm.Model
-	UserMasterID uint
+	UserMasterID uint `gorm:"uniqueIndex:idx_wallet_master_player,where:deleted_at IS NULL"`
 	UserMaster   UserMaster
-	PlayerID     uint
+	PlayerID     uint `gorm:"uniqueIndex:idx_wallet_master_player"`
 	Player       User
-	Prise        int
+	Balance      int
 }
 
 type QuestType string
 
 const (
-	OneTime QuestType = "one_time"
-	Daily   QuestType = "daily"
+	OneTime      QuestType = "one_time"
+	Daily        QuestType = "daily"
+	Weekly       QuestType = "weekly"
+	Monthly      QuestType = "monthly"
+	WeekDays     QuestType = "week_days"
+	TimesPerWeek QuestType = "times_per_week"
 )
 
+// QuestSchedule - параметры расписания повторяющегося квеста.
+// WeekDays - дни недели через запятую (0 - воскресенье), MonthDays - числа месяца через запятую,
+// Times - сколько раз за неделю можно выполнить квест.
+type QuestSchedule struct {
+	WeekDays  string
+	MonthDays string
+	Times     uint
+}
+
 type Quest struct {
 	gorm.Model
-	Title       string
-	Description string
-	Type        QuestType `sql:"type:enum('one_time','daily')"`
-	UserID      uint
-	User        User
-	Players     []User `gorm:"many2many:quest_players;constraint:OnDelete:CASCADE"`
-	Price       uint
-	StartTime   *time.Time
-	EndTime     *time.Time
-	IsActive    bool
+	Title         string
+	Description   string
+	Type          QuestType     `sql:"type:enum('one_time','daily','weekly','monthly','week_days','times_per_week')"`
+	Schedule      QuestSchedule `gorm:"embedded;embeddedPrefix:schedule_"`
+	UserMasterID  uint          `gorm:"index:idx_quest_master_id"`
+	UserMaster    UserMaster
+	UserID        uint // автор квеста
+	User          User
+	Players       []User `gorm:"many2many:quest_players;constraint:OnDelete:CASCADE"`
+	Price         uint
+	Penalty       uint
+	StartTime     *time.Time
+	EndTime       *time.Time
+	IsActive      bool
+	StreakBonuses []QuestStreakBonus `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// QuestStreakBonus - бонус, который получает игрок, выполнив квест Days периодов подряд.
+type QuestStreakBonus struct {
+	gorm.Model
+	QuestID uint `gorm:"index:idx_streak_bonus_quest_id"`
+	Days    uint
+	Bonus   uint
+}
+
+// QuestStreak - серия выполненных подряд периодов повторяющегося квеста.
+// LastPeriod - ключ последнего засчитанного периода.
+type QuestStreak struct {
+	gorm.Model
+	QuestID    uint `gorm:"uniqueIndex:uq_streak_quest_player"`
+	PlayerID   uint `gorm:"uniqueIndex:uq_streak_quest_player"`
+	Current    uint
+	Best       uint
+	LastPeriod string
 }
 
 type QuestPlayerStatus struct {
 	gorm.Model
-	PlayerID           uint `gorm:"index:idx_player_id"`
+	PlayerID           uint `gorm:"index:idx_player_id;uniqueIndex:uq_status_quest_player_period"`
 	Player             User
-	QuestID            uint `gorm:"index:idx_quest_id"`
+	QuestID            uint `gorm:"index:idx_quest_id;uniqueIndex:uq_status_quest_player_period,where:deleted_at IS NULL"`
 	Quest              Quest
+	PeriodKey          string `gorm:"not null;default:'';uniqueIndex:uq_status_quest_player_period"`
 	RequestExecuteDate *time.Time
 	RejectExecuteDate  *time.Time
 	ConfirmationDate   *time.Time
 	AccrualDate        *time.Time
+	ProofComment       string
+	RejectReason       string
+	Attachments        []ProofAttachment `gorm:"constraint:OnDelete:CASCADE"`
+	Comments           []QuestComment    `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// ProofAttachment - файл, приложенный игроком как подтверждение выполнения квеста.
+// Содержимое лежит в хранилище файлов по ключу BlobKey.
+type ProofAttachment struct {
+	gorm.Model
+	QuestPlayerStatusID uint `gorm:"index:idx_attachment_status_id"`
+	QuestPlayerStatus   QuestPlayerStatus
+	FileName            string
+	ContentType         string
+	Size                int64
+	BlobKey             string
+}
+
+// QuestComment - сообщение мастера или игрока в обсуждении отправленного квеста.
+type QuestComment struct {
+	gorm.Model
+	QuestPlayerStatusID uint `gorm:"index:idx_comment_status_id"`
+	QuestPlayerStatus   QuestPlayerStatus
+	AuthorID            uint
+	Author              User
+	Text                string
+}
+
+type QuestPenaltyReason string
+
+const (
+	PenaltyMissed   QuestPenaltyReason = "missed"
+	PenaltyRejected QuestPenaltyReason = "rejected"
+)
+
+// QuestPenalty - штраф игрока по квесту. За пропущенный период и за отклонение отправки штраф
+// списывается один раз, это гарантируют частичные уникальные индексы.
+type QuestPenalty struct {
+	gorm.Model
+	QuestID             uint `gorm:"uniqueIndex:uq_penalty_missed,where:reason = 'missed'"`
+	Quest               Quest
+	PlayerID            uint `gorm:"uniqueIndex:uq_penalty_missed"`
+	UserMasterID        uint
+	PeriodKey           string             `gorm:"uniqueIndex:uq_penalty_missed"`
+	Reason              QuestPenaltyReason `sql:"type:enum('missed','rejected')"`
+	Amount              uint
+	QuestPlayerStatusID *uint `gorm:"uniqueIndex:uq_penalty_rejected,where:reason = 'rejected'"`
+}
+
+type Reward struct {
+	gorm.Model
+	UserMasterID uint `gorm:"index:idx_reward_master_id"`
+	UserMaster   UserMaster
+	Title        string
+	Description  string
+	Cost         uint
+	Stock        *uint
+	IsActive     bool
+}
+
+type RewardPurchaseStatus string
+
+const (
+	PurchasePending   RewardPurchaseStatus = "pending"
+	PurchaseFulfilled RewardPurchaseStatus = "fulfilled"
+	PurchaseRefunded  RewardPurchaseStatus = "refunded"
+)
+
+type RewardPurchase struct {
+	gorm.Model
+	RewardID     uint `gorm:"index:idx_purchase_reward_id"`
+	Reward       Reward
+	PlayerID     uint `gorm:"index:idx_purchase_player_id"`
+	Player       User
+	UserMasterID uint `gorm:"index:idx_purchase_master_id"`
+	Cost         uint
+	Status       RewardPurchaseStatus `sql:"type:enum('pending','fulfilled','refunded')"`
+	ClosedDate   *time.Time
+}
+
+type WalletTransactionType string
+
+const (
+	TransactionAccrual    WalletTransactionType = "accrual"
+	TransactionAdjustment WalletTransactionType = "adjustment"
+	TransactionPurchase   WalletTransactionType = "purchase"
+	TransactionRefund     WalletTransactionType = "refund"
+	TransactionReversal   WalletTransactionType = "reversal"
+	TransactionStreak     WalletTransactionType = "streak_bonus"
+	TransactionPenalty    WalletTransactionType = "penalty"
+)
+
+// WalletTransaction - запись журнала операций кошелька. Записи только добавляются,
+// баланс кошелька равен сумме Amount всех его записей.
+type WalletTransaction struct {
+	gorm.Model
+	WalletID            uint `gorm:"index:idx_transaction_wallet_id"`
+	Wallet              PlayerWallet
+	UserMasterID        uint
+	PlayerID            uint                  `gorm:"index:idx_transaction_player_id"`
+	Type                WalletTransactionType `sql:"type:enum('accrual','adjustment','purchase','refund','reversal','streak_bonus','penalty')"`
+	Amount              int
+	Balance             int
+	Comment             string
+	AuthorID            *uint
+	QuestPlayerStatusID *uint
+	RewardPurchaseID    *uint
+	QuestPenaltyID      *uint
+	ReversalOfID        *uint `gorm:"index:idx_transaction_reversal_of_id"`
+	IsReversed          bool  `gorm:"->;-:migration"`
 }
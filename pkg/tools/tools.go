@@ -1,6 +1,11 @@
 package tools
 
 import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 
 	"golang.org/x/crypto/bcrypt"
@@ -22,6 +27,21 @@ func RandomString(n uint) string {
 	return string(b)
 }
 
+// RandomToken возвращает криптографически случайную строку из n байт в base64url.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("failed read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken возвращает SHA-256 токена в hex, чтобы не хранить сам токен.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func HashPassword(password string) (string, error) {
 	cost := 14
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
@@ -1,3 +1 @@
-swag init -o ./docs -g ./internal/adapters/api/rest/rest.go
-swag fmt
-go run ./cmd/server/server.go
\ No newline at end of file
+DEV_MODE=true go run ./cmd/server
\ No newline at end of file
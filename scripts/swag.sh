@@ -1,2 +0,0 @@
-swag init -o ./docs -g ./internal/adapter/api/rest/rest.go
-swag fmt
\ No newline at end of file